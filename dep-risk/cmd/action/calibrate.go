@@ -0,0 +1,85 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/dep-risk/dep-risk/internal/calibrate"
+	"github.com/dep-risk/dep-risk/internal/scorer"
+)
+
+// runCalibrate implements `dep-risk calibrate`, which fits scoring weights to
+// labeled triage outcomes
+func runCalibrate(args []string) error {
+	flags := flag.NewFlagSet("calibrate", flag.ExitOnError)
+	var (
+		dataPath   = flags.String("data", "", "Labeled findings: a CSV file or a JSON export of the API vulnerabilities table")
+		threshold  = flags.Float64("threshold", -1, "Fail threshold to evaluate at (default: the configured thresholds.fail_score)")
+		outputPath = flags.String("output", "", "Write the fitted config snippet to this file")
+	)
+	flags.Parse(args)
+	if *dataPath == "" {
+		return fmt.Errorf("-data is required")
+	}
+
+	cfg, _, err := loadConfiguration()
+	if err != nil {
+		return fmt.Errorf("failed to load configuration: %w", err)
+	}
+	if *threshold < 0 {
+		*threshold = cfg.FailThreshold
+	}
+	current := scorer.ScoringWeights{
+		CVSS:        cfg.CVSSWeight,
+		Popularity:  cfg.PopularityWeight,
+		Dependency:  cfg.DependencyWeight,
+		Context:     cfg.ContextWeight,
+		Maintenance: cfg.MaintenanceWeight,
+		Hygiene:     cfg.HygieneWeight,
+	}
+
+	fmt.Printf("📥 Loading labeled findings from %s...\n", *dataPath)
+	dataset, err := calibrate.Load(*dataPath, *threshold)
+	if err != nil {
+		return fmt.Errorf("failed to load dataset: %w", err)
+	}
+	if dataset.Skipped > 0 {
+		fmt.Printf("⚠️  Skipped %d records without a score explanation\n", dataset.Skipped)
+	}
+	if len(dataset.Missing) > 0 {
+		fmt.Printf("⚠️  No values for %v; these components get weight 0\n", dataset.Missing)
+	}
+
+	fmt.Println("📐 Fitting scoring weights...")
+	result, err := calibrate.Calibrate(dataset, current, *threshold)
+	if err != nil {
+		return err
+	}
+	printCalibration(result)
+
+	snippet := result.Snippet()
+	fmt.Printf("\n📝 Config snippet:\n\n%s", snippet)
+	if *outputPath != "" {
+		if err := os.WriteFile(*outputPath, []byte(snippet), 0644); err != nil {
+			return fmt.Errorf("failed to write snippet: %w", err)
+		}
+		fmt.Printf("\n💾 Snippet written to %s\n", *outputPath)
+	}
+	return nil
+}
+
+// printCalibration prints the current and fitted weights side by side
+func printCalibration(result *calibrate.Result) {
+	fmt.Printf("\n📊 Calibration on %d findings (%d positive), fail threshold %.1f\n",
+		result.Examples, result.Positives, result.Threshold)
+	fmt.Printf("   %-12s %8s %8s\n", "", "current", "fitted")
+	fmt.Printf("   %-12s %8.3f %8.3f\n", "cvss", result.Current.CVSS, result.Fitted.CVSS)
+	fmt.Printf("   %-12s %8.3f %8.3f\n", "popularity", result.Current.Popularity, result.Fitted.Popularity)
+	fmt.Printf("   %-12s %8.3f %8.3f\n", "dependency", result.Current.Dependency, result.Fitted.Dependency)
+	fmt.Printf("   %-12s %8.3f %8.3f\n", "context", result.Current.Context, result.Fitted.Context)
+	fmt.Printf("   %-12s %8.3f %8.3f\n", "maintenance", result.Current.Maintenance, result.Fitted.Maintenance)
+	fmt.Printf("   %-12s %8.2f %8.2f\n", "precision", result.Before.Precision, result.After.Precision)
+	fmt.Printf("   %-12s %8.2f %8.2f\n", "recall", result.Before.Recall, result.After.Recall)
+	fmt.Printf("   %-12s %8.2f %8.2f\n", "rmse", result.Before.RMSE, result.After.RMSE)
+}
@@ -0,0 +1,123 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/dep-risk/dep-risk/internal/config"
+)
+
+// runConfig implements the `dep-risk config` subcommands
+func runConfig(args []string) error {
+	if len(args) == 0 {
+		return fmt.Errorf("usage: dep-risk config migrate|print|validate [-config path], or dep-risk config schema")
+	}
+
+	switch args[0] {
+	case "migrate":
+		return runConfigMigrate(args[1:])
+	case "print":
+		return runConfigPrint(args[1:])
+	case "validate":
+		return runConfigValidate(args[1:])
+	case "schema":
+		return runConfigSchema()
+	default:
+		return fmt.Errorf("unknown config command %q (available: migrate, print, validate, schema)", args[0])
+	}
+}
+
+// runConfigMigrate rewrites a flat config file in the current schema, in place
+func runConfigMigrate(args []string) error {
+	flags := flag.NewFlagSet("config migrate", flag.ExitOnError)
+	configPath := flags.String("config", config.DefaultConfig().GetConfigPath(), "Config file to migrate")
+	flags.Parse(args)
+
+	info, err := os.Stat(*configPath)
+	if err != nil {
+		return err
+	}
+	data, err := os.ReadFile(*configPath)
+	if err != nil {
+		return err
+	}
+
+	migrated, changed, err := config.Migrate(data)
+	if err != nil {
+		return fmt.Errorf("%s: %w", *configPath, err)
+	}
+	if !changed {
+		fmt.Printf("✅ %s already declares a config version\n", *configPath)
+		return nil
+	}
+
+	if err := os.WriteFile(*configPath, migrated, info.Mode().Perm()); err != nil {
+		return fmt.Errorf("failed to write %s: %w", *configPath, err)
+	}
+	fmt.Printf("📝 Migrated %s to config version %d\n", *configPath, config.CurrentVersion)
+	return nil
+}
+
+// runConfigPrint prints the effective configuration, merged over the base
+// configs it extends and with the environment applied
+func runConfigPrint(args []string) error {
+	flags := flag.NewFlagSet("config print", flag.ExitOnError)
+	configPath := flags.String("config", config.DefaultConfig().GetConfigPath(), "Config file to print")
+	flags.Parse(args)
+
+	cfg, err := config.LoadConfig(*configPath)
+	if err != nil {
+		return err
+	}
+	effective, err := cfg.Effective()
+	if err != nil {
+		return err
+	}
+	fmt.Print(string(effective))
+	return nil
+}
+
+// runConfigValidate reports every problem in a config file and the action
+// inputs, with the line of each problem in the file
+func runConfigValidate(args []string) error {
+	flags := flag.NewFlagSet("config validate", flag.ExitOnError)
+	configPath := flags.String("config", config.DefaultConfig().GetConfigPath(), "Config file to validate")
+	flags.Parse(args)
+
+	problems, err := config.Validate(*configPath)
+	if err != nil {
+		return fmt.Errorf("%s: %w", *configPath, err)
+	}
+	if len(problems) == 0 {
+		// Policy files are only checked once the config they are listed in is valid
+		cfg, err := config.LoadConfig(*configPath)
+		if err != nil {
+			return fmt.Errorf("%s: %w", *configPath, err)
+		}
+		if _, err := loadPolicies(cfg, cfg.GetWorkingDirectory()); err != nil {
+			return err
+		}
+		fmt.Printf("✅ %s is valid\n", *configPath)
+		return nil
+	}
+
+	for _, problem := range problems {
+		if problem.Line > 0 {
+			fmt.Printf("%s:%d: %s\n", *configPath, problem.Line, problem.Message)
+		} else {
+			fmt.Printf("%s: %s\n", *configPath, problem.Message)
+		}
+	}
+	return fmt.Errorf("%d problems found in %s", len(problems), *configPath)
+}
+
+// runConfigSchema prints the JSON Schema of the config file
+func runConfigSchema() error {
+	schema, err := config.JSONSchema()
+	if err != nil {
+		return err
+	}
+	fmt.Print(string(schema))
+	return nil
+}
@@ -4,17 +4,27 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
+	"flag"
 	"fmt"
 	"log"
 	"net/http"
 	"os"
 	"path/filepath"
+	"strings"
 	"time"
 
 	"github.com/dep-risk/dep-risk/internal/config"
+	"github.com/dep-risk/dep-risk/internal/eol"
 	"github.com/dep-risk/dep-risk/internal/github"
+	"github.com/dep-risk/dep-risk/internal/hygiene"
+	"github.com/dep-risk/dep-risk/internal/kev"
+	"github.com/dep-risk/dep-risk/internal/maintenance"
+	"github.com/dep-risk/dep-risk/internal/policy"
+	"github.com/dep-risk/dep-risk/internal/popularity"
 	"github.com/dep-risk/dep-risk/internal/scanner"
 	"github.com/dep-risk/dep-risk/internal/scorer"
+	"github.com/dep-risk/dep-risk/internal/workflow"
 )
 
 // ActionResult represents the output of the GitHub Action
@@ -23,59 +33,163 @@ type ActionResult struct {
 	VulnerabilitiesFound int   `json:"vulnerabilities_found"`
 	HighRiskCount      int     `json:"high_risk_count"`
 	ScanStatus         string  `json:"scan_status"`
+	ScanPartial        bool    `json:"scan_partial"`
+	DiagnosticsCount   int     `json:"diagnostics_count"`
+	GatesFailed        int     `json:"gates_failed"`
+	GateResults        map[string]string `json:"gate_results,omitempty"`
 	SarifFile          string  `json:"sarif_file,omitempty"`
 	ReportURL          string  `json:"report_url,omitempty"`
 }
 
 func main() {
+	if len(os.Args) > 1 {
+		switch os.Args[1] {
+		case "calibrate":
+			if err := runCalibrate(os.Args[2:]); err != nil {
+				log.Fatalf("Calibration failed: %v", err)
+			}
+			return
+		case "rescore":
+			if err := runRescore(os.Args[2:]); err != nil {
+				log.Fatalf("Rescoring failed: %v", err)
+			}
+			return
+		case "config":
+			if err := runConfig(os.Args[2:]); err != nil {
+				log.Fatalf("Config command failed: %v", err)
+			}
+			return
+		}
+	}
+
+	var (
+		replayOSV  = flag.String("replay-osv", "", "Score a recorded osv-scanner JSON report instead of scanning")
+		replaySBOM = flag.String("replay-sbom", "", "Syft SPDX JSON SBOM to use with -replay-osv")
+	)
+	flag.Parse()
+
 	// Load configuration
-	cfg, err := loadConfiguration()
+	cfg, configPath, err := loadConfiguration()
 	if err != nil {
+		annotateConfigProblems(err, configPath)
 		log.Fatalf("Failed to load configuration: %v", err)
 	}
+	if cfg.LegacyFormat {
+		fmt.Printf("⚠️  %s uses the deprecated flat config format; run `dep-risk config migrate` to upgrade it to version %d\n", cfg.GetConfigPath(), config.CurrentVersion)
+	}
+	if cfg.SlackNotification != nil || cfg.EmailNotification != nil {
+		fmt.Println("⚠️  notifications.slack and notifications.email are not delivered by the action; use the check run, PR comment or report outputs instead")
+	}
+	if unjustified := cfg.UnjustifiedIgnores(); len(unjustified) > 0 {
+		fmt.Printf("⚠️  %d ignore entries have no reason or expiry (%s); move them to ignore.rules so they can be audited\n", len(unjustified), strings.Join(unjustified, ", "))
+	}
+	printEffectiveConfig(cfg)
+	run := config.RunContextFromEnv()
+	applied := cfg.ApplyOverrides(run)
+	if len(applied) > 0 {
+		fmt.Printf("🎯 Applied overrides %s (branch %q, event %q): fail threshold %.1f, warn threshold %.1f\n",
+			strings.Join(applied, ", "), run.Branch, run.Event, cfg.FailThreshold, cfg.WarnThreshold)
+	}
+	if *replayOSV != "" {
+		cfg.ReplayOSV = *replayOSV
+	}
+	if *replaySBOM != "" {
+		cfg.ReplaySBOM = *replaySBOM
+	}
+	if cfg.ReplaySBOM != "" && !cfg.IsReplay() {
+		log.Fatalf("-replay-sbom requires -replay-osv")
+	}
 
 	// Initialize scanner
 	workingDir := cfg.GetWorkingDirectory()
+
+	// Policies are compiled up front so a broken policy fails before the scan
+	policies, err := loadPolicies(cfg, workingDir)
+	if err != nil {
+		log.Fatalf("Failed to load policies: %v", err)
+	}
 	scannerInstance := scanner.NewScanner(workingDir)
 
 	// Initialize scorer with custom weights
-	scoringWeights := scorer.ScoringWeights{
-		CVSS:       cfg.CVSSWeight,
-		Popularity: cfg.PopularityWeight,
-		Dependency: cfg.DependencyWeight,
-		Context:    cfg.ContextWeight,
+	scorerInstance, err := newScorer(cfg, workingDir)
+	if err != nil {
+		log.Fatalf("Failed to initialize scorer: %v", err)
 	}
-	scorerInstance := scorer.NewScorerWithWeights(scoringWeights)
 
 	// Perform vulnerability scan
-	fmt.Println("🔍 Starting vulnerability scan...")
-	scanResult, err := scannerInstance.ScanProject()
+	scanResult, err := runScan(scannerInstance, cfg)
 	if err != nil {
 		log.Fatalf("Scan failed: %v", err)
 	}
 
+	// Detectors below inspect the local tree, which replayed results do not describe
+	if !cfg.IsReplay() {
+		runHygieneChecks(scanResult, cfg, workingDir)
+		runEOLChecks(scanResult, cfg, workingDir)
+		runWorkflowChecks(scanResult, cfg, workingDir)
+	}
+
+	// KEV status comes from the advisory IDs, so replayed results are looked up too
+	kevErr := runKEVLookup(scanResult, cfg, workingDir)
+
 	fmt.Printf("📊 Found %d vulnerabilities\n", scanResult.TotalCount)
+	if scanResult.IsPartial() {
+		fmt.Printf("⚠️  Scan may be incomplete: %d scanner diagnostics reported\n", len(scanResult.Diagnostics))
+	}
 
 	// Calculate risk scores
 	fmt.Println("⚖️  Calculating risk scores...")
-	projectScore := scorerInstance.CalculateProjectScore(scanResult)
+	projectScore, ignored := scoreProject(scorerInstance, scanResult, cfg)
+	if ignored > 0 {
+		fmt.Printf("🚫 Ignored %d vulnerabilities based on configuration\n", ignored)
+	}
+	for _, expired := range projectScore.ExpiredIgnores {
+		fmt.Printf("⏰ Ignore rule for %s in %s expired after %s and is reported again (owner: %s)\n",
+			expired.VulnerabilityID, expired.Package, expired.Expires, ownerOrUnknown(expired.Owner))
+	}
+	projectScore.Policy = evaluatePolicy(scorerInstance, projectScore, cfg, run, applied)
+	if projectScore.Policy != nil {
+		for _, scope := range projectScore.Policy.Scopes {
+			fmt.Printf("🎯 %s: %d findings, risk score %.1f against fail threshold %.1f\n",
+				scopeName(scope), scope.Findings, scope.Score, scope.FailThreshold)
+		}
+	}
 
-	// Filter ignored vulnerabilities
-	filteredScores := filterIgnoredVulnerabilities(projectScore.VulnerabilityScores, cfg)
-	if len(filteredScores) != len(projectScore.VulnerabilityScores) {
-		fmt.Printf("🚫 Ignored %d vulnerabilities based on configuration\n", 
-			len(projectScore.VulnerabilityScores)-len(filteredScores))
-		
-		// Recalculate project score with filtered vulnerabilities
-		filteredScanResult := &scanner.ScanResult{
-			Vulnerabilities: extractVulnerabilities(filteredScores),
-			TotalCount:      len(filteredScores),
+	gates, err := scorer.CompileGates(cfg.Gates)
+	if err != nil {
+		log.Fatalf("Invalid gates: %v", err)
+	}
+	projectScore.Gates = scorerInstance.EvaluateGates(gates, projectScore.VulnerabilityScores)
+	if kevErr != nil {
+		scorer.FailGatesReading(gates, projectScore.Gates, "kev", "KEV catalog unavailable")
+	}
+	for _, gate := range projectScore.Gates {
+		if gate.Error != "" {
+			fmt.Printf("🚦 Gate %s failed (%s): %s\n", gate.Name, gate.Action, gate.Error)
+		} else if gate.Passed {
+			fmt.Printf("🚦 Gate %s passed: %d of at most %d findings match\n", gate.Name, gate.Count, gate.MaxCount)
+		} else {
+			fmt.Printf("🚦 Gate %s failed (%s): %d findings match, at most %d allowed\n", gate.Name, gate.Action, gate.Count, gate.MaxCount)
+		}
+	}
+
+	if len(policies) > 0 {
+		decisions, err := policy.EvaluateAll(policies, projectScore, policy.Run{Branch: run.Branch, Event: run.Event}, projectScore.ScannedAt)
+		if err != nil {
+			log.Fatalf("Failed to evaluate policies: %v", err)
+		}
+		projectScore.Decisions = decisions
+		fmt.Printf("📜 Evaluated %d policies: %d deny, %d warn\n", len(policies),
+			scorer.CountDecisions(decisions, scorer.DecisionDeny), scorer.CountDecisions(decisions, scorer.DecisionWarn))
+		for _, decision := range decisions {
+			if decision.Action == scorer.DecisionWarn {
+				fmt.Printf("⚠️  Policy rule %s warns: %s\n", decision.Rule, decision.Message)
+			}
 		}
-		projectScore = scorerInstance.CalculateProjectScore(filteredScanResult)
 	}
 
 	// Determine scan status
-	scanStatus := determineScanStatus(projectScore.OverallScore, cfg)
+	scanStatus := determineScanStatus(projectScore, cfg)
 	
 	// Create action result
 	result := ActionResult{
@@ -83,6 +197,10 @@ func main() {
 		VulnerabilitiesFound: projectScore.Summary.TotalVulnerabilities,
 		HighRiskCount:        projectScore.Summary.HighRiskCount,
 		ScanStatus:           scanStatus,
+		ScanPartial:          projectScore.IsPartialScan(),
+		DiagnosticsCount:     len(projectScore.Diagnostics),
+		GatesFailed:          scorer.GatesFailed(projectScore.Gates, scorer.GateFail) + scorer.GatesFailed(projectScore.Gates, scorer.GateWarn),
+		GateResults:          gateResults(projectScore.Gates),
 	}
 
 	// Generate outputs
@@ -90,14 +208,19 @@ func main() {
 		log.Printf("Warning: Failed to generate some outputs: %v", err)
 	}
 
-	// GitHub integration (if running in GitHub Actions)
-	if err := handleGitHubIntegration(projectScore, cfg); err != nil {
-		log.Printf("Warning: GitHub integration failed: %v", err)
-	}
+	// Replayed results belong to another tree, so never publish them
+	if cfg.IsReplay() {
+		fmt.Println("⏪ Replay mode: skipping GitHub and API integration")
+	} else {
+		// GitHub integration (if running in GitHub Actions)
+		if err := handleGitHubIntegration(projectScore, cfg); err != nil {
+			log.Printf("Warning: GitHub integration failed: %v", err)
+		}
 
-	// API integration (send data to backend)
-	if err := handleAPIIntegration(projectScore, cfg); err != nil {
-		log.Printf("Warning: API integration failed: %v", err)
+		// API integration (send data to backend)
+		if err := handleAPIIntegration(projectScore, cfg); err != nil {
+			log.Printf("Warning: API integration failed: %v", err)
+		}
 	}
 
 	// Print summary
@@ -108,8 +231,30 @@ func main() {
 
 	// Exit with appropriate code
 	exitCode := getExitCode(scanStatus, projectScore.OverallScore, cfg)
-	if exitCode != 0 {
-		fmt.Printf("❌ Scan failed: Risk score %.1f exceeds threshold %.1f\n", 
+	for _, gate := range projectScore.Gates {
+		if !gate.Passed && gate.Action == scorer.GateFail {
+			fmt.Printf("❌ Scan failed: Gate %s matched %d findings, at most %d allowed\n", gate.Name, gate.Count, gate.MaxCount)
+		}
+	}
+	for _, decision := range projectScore.Decisions {
+		if decision.Action == scorer.DecisionDeny {
+			fmt.Printf("❌ Scan failed: Policy rule %s (%s) denied: %s\n", decision.Rule, decision.Policy, decision.Message)
+		}
+	}
+	if exitCode != 0 && projectScore.Policy != nil && len(projectScore.Policy.Scopes) > 0 {
+		for _, scope := range projectScore.Policy.Scopes {
+			if scope.Score >= scope.FailThreshold {
+				fmt.Printf("❌ Scan failed: Risk score %.1f of %s exceeds threshold %.1f\n",
+					scope.Score, scopeName(scope), scope.FailThreshold)
+			}
+		}
+	} else if exitCode != 0 {
+		if projectScore.OverallScore >= cfg.FailThreshold {
+			fmt.Printf("❌ Scan failed: Risk score %.1f exceeds threshold %.1f\n", 
+				projectScore.OverallScore, cfg.FailThreshold)
+		}
+	} else if scanStatus == "partial" {
+		fmt.Printf("⚠️  Scan passed with incomplete coverage: Risk score %.1f is below threshold %.1f\n", 
 			projectScore.OverallScore, cfg.FailThreshold)
 	} else {
 		fmt.Printf("✅ Scan passed: Risk score %.1f is below threshold %.1f\n", 
@@ -119,23 +264,331 @@ func main() {
 	os.Exit(exitCode)
 }
 
-// loadConfiguration loads the application configuration
-func loadConfiguration() (*config.Config, error) {
-	cfg := config.DefaultConfig()
-	configPath := cfg.GetConfigPath()
+// loadConfiguration loads the application configuration and returns the path
+// of the config file it read, relative to the workspace
+func loadConfiguration() (*config.Config, string, error) {
+	defaults := config.DefaultConfig()
+	configPath := defaults.GetConfigPath()
 	
-	return config.LoadConfig(configPath)
+	cfg, err := config.LoadConfig(configPath)
+	if rel, relErr := filepath.Rel(defaults.GetWorkingDirectory(), configPath); relErr == nil {
+		configPath = rel
+	}
+	return cfg, configPath, err
+}
+
+// annotateConfigProblems reports the problems of an invalid configuration as
+// error annotations on the lines of the config file
+func annotateConfigProblems(err error, file string) {
+	var problems config.Problems
+	if !errors.As(err, &problems) {
+		return
+	}
+
+	for _, problem := range problems {
+		if problem.Line > 0 {
+			fmt.Printf("::error file=%s,line=%d::%s\n", file, problem.Line, problem.Message)
+		} else {
+			fmt.Printf("::error::%s\n", problem.Message)
+		}
+	}
+}
+
+// printEffectiveConfig logs the merged configuration in a collapsed group of
+// the Actions log
+func printEffectiveConfig(cfg *config.Config) {
+	if cfg.Extends != "" {
+		fmt.Printf("🧩 Configuration extends %s\n", cfg.Extends)
+	}
+	if len(cfg.Locked) > 0 {
+		fmt.Printf("🔒 Locked by base config: %s\n", strings.Join(cfg.Locked, ", "))
+	}
+
+	effective, err := cfg.Effective()
+	if err != nil {
+		fmt.Printf("⚠️  Failed to render effective configuration: %v\n", err)
+		return
+	}
+	fmt.Println("::group::🧩 Effective configuration")
+	fmt.Print(string(effective))
+	fmt.Println("::endgroup::")
+}
+
+// newScorer creates a scorer with the scoring settings of the configuration
+func newScorer(cfg *config.Config, workingDir string) (*scorer.Scorer, error) {
+	scoringWeights := scorer.ScoringWeights{
+		CVSS:       cfg.CVSSWeight,
+		Popularity: cfg.PopularityWeight,
+		Dependency: cfg.DependencyWeight,
+		Context:    cfg.ContextWeight,
+		Maintenance: cfg.MaintenanceWeight,
+		Hygiene:    cfg.HygieneWeight,
+	}
+	scorerInstance := scorer.NewScorerWithWeights(scoringWeights)
+	scorerInstance.ModelVersion = cfg.Scoring.Model
+	scorerInstance.Dependency = cfg.DependencyScoring
+	scorerInstance.Context = cfg.Context
+	scorerInstance.Modifiers = cfg.Scoring.Modifiers
+	scorerInstance.Timing = cfg.Scoring.Timing
+	scorerInstance.Aggregation = cfg.Scoring.Aggregation
+	scorerInstance.Workers = cfg.ParallelJobs
+	scorerInstance.ScanTime = time.Now().UTC()
+
+	rules, err := scorer.CompileRules(cfg.Scoring.Rules)
+	if err != nil {
+		return nil, fmt.Errorf("invalid scoring rules: %w", err)
+	}
+	scorerInstance.Rules = rules
+
+	popularityProvider, err := newPopularityProvider(cfg, workingDir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to initialize popularity provider: %w", err)
+	}
+	scorerInstance.Popularity = popularityProvider
+
+	if cfg.ScorecardResults != "" || cfg.ReleaseMetadata != "" {
+		maintenanceIndex, err := maintenance.Load(resolvePath(workingDir, cfg.ScorecardResults), resolvePath(workingDir, cfg.ReleaseMetadata))
+		if err != nil {
+			return nil, fmt.Errorf("failed to load maintenance data: %w", err)
+		}
+		scorerInstance.Maintenance = maintenanceIndex
+	}
+
+	return scorerInstance, nil
+}
+
+// scoreProject scores the scan results without the ignored vulnerabilities
+// and returns how many were ignored
+func scoreProject(scorerInstance *scorer.Scorer, scanResult *scanner.ScanResult, cfg *config.Config) (*scorer.ProjectRiskScore, int) {
+	projectScore := scorerInstance.CalculateProjectScore(scanResult)
+
+	filteredScores, ignoredFindings, expired := filterIgnoredVulnerabilities(projectScore.VulnerabilityScores, cfg, projectScore.ScannedAt)
+	ignored := len(ignoredFindings)
+	if ignored > 0 {
+		// Recalculate project score with filtered vulnerabilities
+		filteredScanResult := &scanner.ScanResult{
+			Vulnerabilities: extractVulnerabilities(filteredScores),
+			TotalCount:      len(filteredScores),
+			Diagnostics:     scanResult.Diagnostics,
+			DependencyCount: scanResult.DependencyCount,
+		}
+		projectScore = scorerInstance.CalculateProjectScore(filteredScanResult)
+	}
+	projectScore.ExpiredIgnores = expired
+	projectScore.IgnoredFindings = ignoredFindings
+	projectScore.UnjustifiedIgnores = cfg.UnjustifiedIgnores()
+
+	return projectScore, ignored
 }
 
-// filterIgnoredVulnerabilities removes vulnerabilities that should be ignored
-func filterIgnoredVulnerabilities(scores []scorer.RiskScore, cfg *config.Config) []scorer.RiskScore {
+// runScan scans the project, or replays recorded scanner output in replay mode
+func runScan(scannerInstance *scanner.Scanner, cfg *config.Config) (*scanner.ScanResult, error) {
+	if cfg.IsReplay() {
+		fmt.Printf("⏪ Replaying recorded OSV output from %s...\n", cfg.ReplayOSV)
+		return scannerInstance.ReplayProject(cfg.ReplayOSV, cfg.ReplaySBOM)
+	}
+
+	fmt.Println("🔍 Starting vulnerability scan...")
+	return scannerInstance.ScanProject()
+}
+
+// resolvePath resolves a configured path relative to the working directory
+func resolvePath(workingDir, path string) string {
+	if path == "" || filepath.IsAbs(path) {
+		return path
+	}
+	return filepath.Join(workingDir, path)
+}
+
+// newPopularityProvider creates the configured package popularity provider
+func newPopularityProvider(cfg *config.Config, workingDir string) (scorer.PopularityProvider, error) {
+	options := popularity.Options{
+		Provider:     cfg.PopularityProvider,
+		SnapshotPath: cfg.PopularitySnapshot,
+		BaseURL:      cfg.PopularityURL,
+	}
+	if options.SnapshotPath != "" && !filepath.IsAbs(options.SnapshotPath) {
+		options.SnapshotPath = filepath.Join(workingDir, options.SnapshotPath)
+	}
+	if cfg.CacheEnabled {
+		if cacheDir, err := os.UserCacheDir(); err == nil {
+			options.CacheDir = filepath.Join(cacheDir, "dep-risk", "popularity")
+			options.CacheTTL = time.Duration(cfg.CacheTTL) * time.Hour
+		}
+	}
+
+	return popularity.NewProvider(options)
+}
+
+// runHygieneChecks adds retracted and deprecated dependency findings to the scan result
+func runHygieneChecks(scanResult *scanner.ScanResult, cfg *config.Config, workingDir string) {
+	if !cfg.HygieneEnabled {
+		return
+	}
+
+	fmt.Println("🧹 Checking for retracted and deprecated dependencies...")
+	checker := hygiene.NewChecker(workingDir, hygiene.Options{
+		GoProxy:             cfg.GoProxy,
+		NPMRegistrySnapshot: cfg.NPMRegistrySnapshot,
+	})
+	findings, diagnostics := checker.Check()
+	scanResult.AddFindings(findings)
+	scanResult.Diagnostics = append(scanResult.Diagnostics, diagnostics...)
+
+	if len(findings) > 0 {
+		fmt.Printf("🧹 Found %d hygiene findings\n", len(findings))
+	}
+}
+
+// runEOLChecks adds end-of-life runtime and framework findings to the scan result
+func runEOLChecks(scanResult *scanner.ScanResult, cfg *config.Config, workingDir string) {
+	if !cfg.EOLEnabled {
+		return
+	}
+
+	datasetPath := cfg.EOLDataset
+	if datasetPath != "" && !filepath.IsAbs(datasetPath) {
+		datasetPath = filepath.Join(workingDir, datasetPath)
+	}
+	dataset, err := eol.LoadDataset(datasetPath)
+	if err != nil {
+		log.Printf("Warning: Skipping end-of-life checks: %v", err)
+		return
+	}
+
+	fmt.Println("⏳ Checking for end-of-life runtimes and frameworks...")
+	checker := eol.NewChecker(workingDir, dataset, eol.Options{
+		WarningDays:  cfg.EOLWarningDays,
+		ExcludePaths: cfg.ExcludePaths,
+	})
+	findings, diagnostics := checker.Check()
+	scanResult.AddFindings(findings)
+	scanResult.Diagnostics = append(scanResult.Diagnostics, diagnostics...)
+
+	if len(findings) > 0 {
+		fmt.Printf("⏳ Found %d end-of-life findings\n", len(findings))
+	}
+}
+
+// loadPolicies compiles the configured policy files
+func loadPolicies(cfg *config.Config, workingDir string) ([]*policy.Policy, error) {
+	policies := make([]*policy.Policy, 0, len(cfg.PolicyFiles))
+	for _, path := range cfg.PolicyFiles {
+		loaded, err := policy.Load(resolvePath(workingDir, path))
+		if err != nil {
+			return nil, err
+		}
+		loaded.Path = path
+		policies = append(policies, loaded)
+	}
+	return policies, nil
+}
+
+// runKEVLookup marks the findings listed in the Known Exploited
+// Vulnerabilities catalog
+func runKEVLookup(scanResult *scanner.ScanResult, cfg *config.Config, workingDir string) error {
+	if !cfg.KEVEnabled {
+		return nil
+	}
+
+	var catalog *kev.Catalog
+	var err error
+	if cfg.KEVCatalog != "" {
+		catalog, err = kev.Load(resolvePath(workingDir, cfg.KEVCatalog))
+	} else {
+		url := cfg.KEVURL
+		if url == "" {
+			url = kev.DefaultURL
+		}
+		catalog, err = kev.Fetch(url)
+	}
+	if err != nil {
+		// Unmarked findings would silently pass kev gates, so the scan is
+		// incomplete and those gates fail
+		scanResult.Diagnostics = append(scanResult.Diagnostics, scanner.Diagnostic{
+			Tool:    "kev",
+			Kind:    scanner.DiagnosticLookupFailure,
+			Message: err.Error(),
+		})
+		log.Printf("Warning: KEV lookup failed: %v", err)
+		return err
+	}
+
+	if count := catalog.Annotate(scanResult.Vulnerabilities); count > 0 {
+		fmt.Printf("🔥 %d findings are known exploited vulnerabilities (KEV catalog %s)\n", count, catalog.CatalogVersion)
+	}
+	return nil
+}
+
+// runWorkflowChecks adds GitHub Actions advisory and pinning policy findings to the scan result
+func runWorkflowChecks(scanResult *scanner.ScanResult, cfg *config.Config, workingDir string) {
+	if !cfg.WorkflowScanEnabled {
+		return
+	}
+
+	// Prefer an advisory snapshot; otherwise query GitHub when a token is available
+	var source workflow.AdvisorySource
+	if cfg.WorkflowAdvisories != "" {
+		path := cfg.WorkflowAdvisories
+		if !filepath.IsAbs(path) {
+			path = filepath.Join(workingDir, path)
+		}
+		source = &workflow.FileSource{Path: path}
+	} else if token := os.Getenv("GITHUB_TOKEN"); token != "" {
+		source = workflow.NewAPISource(os.Getenv("GITHUB_API_URL"), token)
+	}
+
+	fmt.Println("🔗 Checking GitHub Actions workflow dependencies...")
+	checker := workflow.NewChecker(workingDir, source, workflow.Options{
+		RequireSHA:    cfg.WorkflowRequireSHA,
+		AllowedOwners: cfg.WorkflowAllowedOwners,
+		ExcludePaths:  cfg.ExcludePaths,
+	})
+	findings, diagnostics := checker.Check(context.Background())
+	scanResult.AddFindings(findings)
+	scanResult.Diagnostics = append(scanResult.Diagnostics, diagnostics...)
+
+	if len(findings) > 0 {
+		fmt.Printf("🔗 Found %d workflow findings\n", len(findings))
+	}
+}
+
+// filterIgnoredVulnerabilities removes vulnerabilities that should be ignored,
+// returning them separately, and lists the findings kept because their ignore
+// rule has expired
+func filterIgnoredVulnerabilities(scores []scorer.RiskScore, cfg *config.Config, now time.Time) ([]scorer.RiskScore, []scanner.Vulnerability, []scorer.ExpiredIgnore) {
 	var filtered []scorer.RiskScore
+	var ignoredFindings []scanner.Vulnerability
+	var expired []scorer.ExpiredIgnore
 	for _, score := range scores {
-		if !cfg.ShouldIgnore(score.Vulnerability.ID) {
-			filtered = append(filtered, score)
+		ignored, rule := cfg.CheckIgnore(score.Vulnerability, now)
+		if ignored {
+			ignoredFindings = append(ignoredFindings, score.Vulnerability)
+			continue
 		}
+		filtered = append(filtered, score)
+		if rule != nil {
+			vuln := score.Vulnerability
+			expired = append(expired, scorer.ExpiredIgnore{
+				VulnerabilityID: vuln.ID,
+				Package:         vuln.Package,
+				Version:         vuln.Version,
+				Rule:            rule.String(),
+				Reason:          rule.Reason,
+				Owner:           rule.Owner,
+				Expires:         rule.Expires,
+			})
+		}
+	}
+	return filtered, ignoredFindings, expired
+}
+
+// ownerOrUnknown returns the owner of an ignore rule for display
+func ownerOrUnknown(owner string) string {
+	if owner == "" {
+		return "unassigned"
 	}
-	return filtered
+	return owner
 }
 
 // extractVulnerabilities extracts vulnerability objects from risk scores
@@ -147,16 +600,123 @@ func extractVulnerabilities(scores []scorer.RiskScore) []scanner.Vulnerability {
 	return vulns
 }
 
+// scanOutcome reports whether a scan fails or warns: on the project score, or
+// on each scope when overrides judge paths separately, and on gates and policy
+// decisions
+func scanOutcome(projectScore *scorer.ProjectRiskScore, cfg *config.Config) (failed, warned bool) {
+	failed = projectScore.OverallScore >= cfg.FailThreshold
+	warned = projectScore.OverallScore >= cfg.WarnThreshold
+	if policy := projectScore.Policy; policy != nil && len(policy.Scopes) > 0 {
+		// Each scope is judged against its own thresholds
+		failed, warned = false, false
+		for _, scope := range policy.Scopes {
+			failed = failed || scope.Score >= scope.FailThreshold
+			warned = warned || scope.Score >= scope.WarnThreshold
+		}
+	}
+
+	// Gates apply on top of the thresholds
+	failed = failed || scorer.GatesFailed(projectScore.Gates, scorer.GateFail) > 0
+	warned = warned || scorer.GatesFailed(projectScore.Gates, scorer.GateWarn) > 0
+
+	// So do the decisions of policy files
+	failed = failed || scorer.CountDecisions(projectScore.Decisions, scorer.DecisionDeny) > 0
+	warned = warned || scorer.CountDecisions(projectScore.Decisions, scorer.DecisionWarn) > 0
+	return failed, warned
+}
+
 // determineScanStatus determines the overall scan status
-func determineScanStatus(overallScore float64, cfg *config.Config) string {
-	if overallScore >= cfg.FailThreshold {
+func determineScanStatus(projectScore *scorer.ProjectRiskScore, cfg *config.Config) string {
+	failed, warned := scanOutcome(projectScore, cfg)
+	if failed {
 		return "failure"
-	} else if overallScore >= cfg.WarnThreshold {
+	} else if warned {
 		return "warning"
+	} else if projectScore.IsPartialScan() {
+		// A clean result from an incomplete scan must not look like a pass
+		return "partial"
 	}
 	return "success"
 }
 
+// evaluatePolicy records the override blocks applied to the run. Findings
+// covered by blocks with paths are split off into scopes, each judged
+// against the thresholds of its block; the other findings form the default
+// scope.
+func evaluatePolicy(scorerInstance *scorer.Scorer, projectScore *scorer.ProjectRiskScore, cfg *config.Config, run config.RunContext, applied []string) *scorer.PolicyResult {
+	if len(cfg.Overrides) == 0 {
+		return nil
+	}
+	policy := &scorer.PolicyResult{
+		Branch:        run.Branch,
+		Event:         run.Event,
+		Overrides:     applied,
+		FailThreshold: cfg.FailThreshold,
+		WarnThreshold: cfg.WarnThreshold,
+	}
+
+	var unscoped []scorer.RiskScore
+	scoped := make(map[*config.Override][]scorer.RiskScore)
+	for _, score := range projectScore.VulnerabilityScores {
+		if override := cfg.ScopeOf(run, score.Vulnerability); override != nil {
+			scoped[override] = append(scoped[override], score)
+		} else {
+			unscoped = append(unscoped, score)
+		}
+	}
+	if len(scoped) == 0 {
+		return policy
+	}
+
+	policy.Scopes = append(policy.Scopes, scorer.PolicyScope{
+		Findings:      len(unscoped),
+		Score:         scorerInstance.AggregateScores(unscoped, projectScore.DependencyCount),
+		FailThreshold: cfg.FailThreshold,
+		WarnThreshold: cfg.WarnThreshold,
+	})
+	for i := range cfg.Overrides {
+		override := &cfg.Overrides[i]
+		scores, ok := scoped[override]
+		if !ok {
+			continue
+		}
+		fail, warn := override.Apply(cfg.FailThreshold, cfg.WarnThreshold)
+		policy.Scopes = append(policy.Scopes, scorer.PolicyScope{
+			Override:      override.Name,
+			Paths:         override.When.Paths,
+			Findings:      len(scores),
+			Score:         scorerInstance.AggregateScores(scores, projectScore.DependencyCount),
+			FailThreshold: fail,
+			WarnThreshold: warn,
+		})
+	}
+	return policy
+}
+
+// gateResults maps each gate to pass or fail for the gate_results output
+func gateResults(gates []scorer.GateResult) map[string]string {
+	if len(gates) == 0 {
+		return nil
+	}
+	results := make(map[string]string, len(gates))
+	for _, gate := range gates {
+		if gate.Passed {
+			results[gate.Name] = "pass"
+		} else {
+			results[gate.Name] = "fail"
+		}
+	}
+	return results
+}
+
+// scopeName names a policy scope in logs
+func scopeName(scope scorer.PolicyScope) string {
+	if scope.Override == "" {
+		return "default scope"
+	}
+	return fmt.Sprintf("override %s (%s)", scope.Override, strings.Join(scope.Paths, ", "))
+}
+
 // generateOutputs generates various output files
 func generateOutputs(projectScore *scorer.ProjectRiskScore, cfg *config.Config, workingDir string) error {
 	// Generate JSON report
@@ -245,6 +805,22 @@ func generateSARIFResults(projectScore *scorer.ProjectRiskScore) []map[string]in
 			},
 		}
 
+		// Findings from the local tree carry their exact location
+		if vuln.File != "" {
+			result["locations"] = []map[string]interface{}{
+				{
+					"physicalLocation": map[string]interface{}{
+						"artifactLocation": map[string]interface{}{
+							"uri": vuln.File,
+						},
+						"region": map[string]interface{}{
+							"startLine": vuln.Line,
+						},
+					},
+				},
+			}
+		}
+
 		results = append(results, result)
 	}
 
@@ -255,6 +831,8 @@ func generateSARIFResults(projectScore *scorer.ProjectRiskScore) []map[string]in
 func printSummary(projectScore *scorer.ProjectRiskScore, cfg *config.Config) {
 	fmt.Println("\n📋 Scan Summary:")
 	fmt.Printf("   Overall Risk Score: %.1f/10\n", projectScore.OverallScore)
+	fmt.Printf("   Aggregation: %s\n", projectScore.Aggregation)
+	fmt.Printf("   Scoring Model: %s (config %.12s)\n", projectScore.ModelVersion, projectScore.ConfigHash)
 	fmt.Printf("   Total Vulnerabilities: %d\n", projectScore.Summary.TotalVulnerabilities)
 	fmt.Printf("   High Risk: %d\n", projectScore.Summary.HighRiskCount)
 	fmt.Printf("   Medium Risk: %d\n", projectScore.Summary.MediumRiskCount)
@@ -275,13 +853,35 @@ func printSummary(projectScore *scorer.ProjectRiskScore, cfg *config.Config) {
 		}
 	}
 
+	if len(projectScore.Diagnostics) > 0 {
+		fmt.Println("\n🩺 Scanner Diagnostics:")
+		for _, diagnostic := range projectScore.Diagnostics {
+			fmt.Printf("   • [%s] %s: %s\n", diagnostic.Kind, diagnostic.Tool, diagnostic.Message)
+		}
+	}
+
 	fmt.Printf("\n⚙️  Configuration:\n")
 	fmt.Printf("   Fail Threshold: %.1f\n", cfg.FailThreshold)
 	fmt.Printf("   Warn Threshold: %.1f\n", cfg.WarnThreshold)
+	fmt.Printf("   Scoring Model: %s\n", cfg.Scoring.Model)
+	fmt.Printf("   Aggregation Strategy: %s\n", cfg.Scoring.Aggregation.Strategy)
 	fmt.Printf("   CVSS Weight: %.1f%%\n", cfg.CVSSWeight*100)
 	fmt.Printf("   Popularity Weight: %.1f%%\n", cfg.PopularityWeight*100)
+	fmt.Printf("   Popularity Provider: %s\n", cfg.PopularityProvider)
 	fmt.Printf("   Dependency Weight: %.1f%%\n", cfg.DependencyWeight*100)
 	fmt.Printf("   Context Weight: %.1f%%\n", cfg.ContextWeight*100)
+	if cfg.Scoring.Modifiers.Industry != "" {
+		fmt.Printf("   Industry: %s\n", cfg.Scoring.Modifiers.Industry)
+	}
+	if cfg.Context != nil {
+		fmt.Printf("   Execution Context: %s\n", strings.Join(cfg.Context.Execution, ", "))
+	}
+	if cfg.MaintenanceWeight > 0 {
+		fmt.Printf("   Maintenance Weight: %.1f%%\n", cfg.MaintenanceWeight*100)
+	}
+	if cfg.HygieneEnabled {
+		fmt.Printf("   Hygiene Weight: %.1f%%\n", cfg.HygieneWeight*100)
+	}
 }
 
 // setGitHubOutputs sets GitHub Actions output variables
@@ -298,6 +898,15 @@ func setGitHubOutputs(result ActionResult) {
 		fmt.Fprintf(file, "vulnerabilities_found=%d\n", result.VulnerabilitiesFound)
 		fmt.Fprintf(file, "high_risk_count=%d\n", result.HighRiskCount)
 		fmt.Fprintf(file, "scan_status=%s\n", result.ScanStatus)
+		fmt.Fprintf(file, "scan_partial=%t\n", result.ScanPartial)
+		fmt.Fprintf(file, "diagnostics_count=%d\n", result.DiagnosticsCount)
+		fmt.Fprintf(file, "gates_failed=%d\n", result.GatesFailed)
+		if result.GateResults != nil {
+			gateResults, err := json.Marshal(result.GateResults)
+			if err == nil {
+				fmt.Fprintf(file, "gate_results=%s\n", gateResults)
+			}
+		}
 		if result.SarifFile != "" {
 			fmt.Fprintf(file, "sarif_file=%s\n", result.SarifFile)
 		}
@@ -363,10 +972,10 @@ func shouldCreateComment(projectScore *scorer.ProjectRiskScore, cfg *config.Conf
 		return true
 	case "never":
 		return false
-	case "on-failure":
-		return projectScore.OverallScore >= cfg.FailThreshold
 	default:
-		return projectScore.OverallScore >= cfg.FailThreshold
+		// on-failure comments whenever the scan status is failure
+		failed, _ := scanOutcome(projectScore, cfg)
+		return failed
 	}
 }
 
@@ -395,6 +1004,8 @@ type ScanData struct {
 	MediumRiskCount int                   `json:"medium_risk_count"`
 	LowRiskCount    int                   `json:"low_risk_count"`
 	AverageScore    float64               `json:"average_score"`
+	ModelVersion    string                `json:"model_version"`
+	ConfigHash      string                `json:"config_hash"`
 	Vulnerabilities []VulnerabilityData   `json:"vulnerabilities"`
 	ScanTime        time.Time             `json:"scan_time"`
 }
@@ -408,6 +1019,8 @@ type VulnerabilityData struct {
 	Severity    string  `json:"severity"`
 	IsDirect    bool    `json:"is_direct"`
 	Summary     string  `json:"summary"`
+	Explanation *scorer.Explanation `json:"explanation,omitempty"`
+	Finding     *scanner.Vulnerability `json:"finding,omitempty"`
 }
 
 // handleAPIIntegration sends scan results to the backend API
@@ -443,6 +1056,8 @@ func handleAPIIntegration(projectScore *scorer.ProjectRiskScore, cfg *config.Con
 			Severity:  vuln.Severity,
 			IsDirect:  vuln.IsDirect,
 			Summary:   vuln.Summary,
+			Explanation: &score.Explanation,
+			Finding:     &score.Vulnerability,
 		})
 	}
 
@@ -463,8 +1078,10 @@ func handleAPIIntegration(projectScore *scorer.ProjectRiskScore, cfg *config.Con
 			MediumRiskCount: projectScore.Summary.MediumRiskCount,
 			LowRiskCount:    projectScore.Summary.LowRiskCount,
 			AverageScore:    projectScore.Summary.AverageScore,
+			ModelVersion:    projectScore.ModelVersion,
+			ConfigHash:      projectScore.ConfigHash,
 			Vulnerabilities: vulns,
-			ScanTime:        time.Now(),
+			ScanTime:        projectScore.ScannedAt,
 		},
 		"vulnerabilities": vulns,
 	}
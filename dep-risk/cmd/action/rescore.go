@@ -0,0 +1,136 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/dep-risk/dep-risk/internal/config"
+	"github.com/dep-risk/dep-risk/internal/rescore"
+	"github.com/dep-risk/dep-risk/internal/scanner"
+	"github.com/dep-risk/dep-risk/internal/scorer"
+)
+
+// runRescore implements `dep-risk rescore`, which scores the findings of an
+// existing report under alternative configs and compares the results
+func runRescore(args []string) error {
+	flags := flag.NewFlagSet("rescore", flag.ExitOnError)
+	var (
+		reportPath = flags.String("report", "dep-risk-report.json", "Report written by a previous dep-risk run")
+		jsonOutput = flags.Bool("json", false, "Print the comparison as JSON")
+	)
+	flags.Usage = func() {
+		fmt.Fprintln(flags.Output(), "Usage: dep-risk rescore [-report dep-risk-report.json] [-json] config.yml...")
+		flags.PrintDefaults()
+	}
+	flags.Parse(args)
+	if flags.NArg() == 0 {
+		flags.Usage()
+		return fmt.Errorf("at least one alternative config is required")
+	}
+
+	data, err := os.ReadFile(*reportPath)
+	if err != nil {
+		return fmt.Errorf("failed to read report: %w", err)
+	}
+	var report scorer.ProjectRiskScore
+	if err := json.Unmarshal(data, &report); err != nil {
+		return fmt.Errorf("failed to parse report: %w", err)
+	}
+
+	// The report was produced with the current configuration
+	cfg, _, err := loadConfiguration()
+	if err != nil {
+		return fmt.Errorf("failed to load configuration: %w", err)
+	}
+	workingDir := cfg.GetWorkingDirectory()
+	baseline := rescore.NewScenario(filepath.Base(*reportPath), &report, thresholds(cfg), len(report.IgnoredFindings))
+
+	// Alternatives apply their own ignore rules to every finding, including
+	// those the baseline ignored
+	vulnerabilities := append(extractVulnerabilities(report.VulnerabilityScores), report.IgnoredFindings...)
+	scanResult := &scanner.ScanResult{
+		Vulnerabilities: vulnerabilities,
+		TotalCount:      len(vulnerabilities),
+		Diagnostics:     report.Diagnostics,
+		DependencyCount: report.DependencyCount,
+	}
+
+	var alternatives []rescore.Scenario
+	for _, path := range flags.Args() {
+		alternative, err := config.LoadConfig(path)
+		if err != nil {
+			return fmt.Errorf("%s: %w", path, err)
+		}
+		scorerInstance, err := newScorer(alternative, workingDir)
+		if err != nil {
+			return fmt.Errorf("%s: %w", path, err)
+		}
+		// Timing factors and ignore expiry are judged as of the original scan
+		if !report.ScannedAt.IsZero() {
+			scorerInstance.ScanTime = report.ScannedAt
+		}
+		projectScore, ignored := scoreProject(scorerInstance, scanResult, alternative)
+		alternatives = append(alternatives, rescore.NewScenario(path, projectScore, thresholds(alternative), ignored))
+	}
+
+	comparison := rescore.Compare(baseline, alternatives)
+	if *jsonOutput {
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(comparison)
+	}
+	printComparison(comparison, len(vulnerabilities))
+	return nil
+}
+
+// thresholds returns the fail and warn thresholds of a configuration
+func thresholds(cfg *config.Config) rescore.Thresholds {
+	return rescore.Thresholds{Fail: cfg.FailThreshold, Warn: cfg.WarnThreshold}
+}
+
+// printComparison prints the scenarios side by side, then the findings
+// crossing a threshold and changing rank under each alternative
+func printComparison(comparison *rescore.Comparison, findings int) {
+	scenarios := []rescore.Scenario{comparison.Baseline}
+	for _, alternative := range comparison.Alternatives {
+		scenarios = append(scenarios, alternative.Scenario)
+	}
+
+	row := func(label string, value func(rescore.Scenario) string) {
+		fmt.Printf("   %-22s", label)
+		for _, scenario := range scenarios {
+			fmt.Printf(" %-20s", value(scenario))
+		}
+		fmt.Println()
+	}
+
+	fmt.Printf("📊 Rescoring %d findings\n\n", findings)
+	row("", func(s rescore.Scenario) string { return s.Name })
+	row("Project score", func(s rescore.Scenario) string { return fmt.Sprintf("%.1f", s.ProjectScore) })
+	row("Status", func(s rescore.Scenario) string { return s.Status })
+	row("Thresholds (fail/warn)", func(s rescore.Scenario) string {
+		return fmt.Sprintf("%.1f/%.1f", s.Thresholds.Fail, s.Thresholds.Warn)
+	})
+	row("High/Medium/Low", func(s rescore.Scenario) string {
+		return fmt.Sprintf("%d/%d/%d", s.HighRisk, s.MediumRisk, s.LowRisk)
+	})
+	row("Ignored", func(s rescore.Scenario) string { return fmt.Sprintf("%d", s.Ignored) })
+	row("Aggregation", func(s rescore.Scenario) string { return strings.SplitN(s.Aggregation, " ", 2)[0] })
+	row("Model", func(s rescore.Scenario) string { return s.ModelVersion })
+
+	for _, alternative := range comparison.Alternatives {
+		fmt.Printf("\n🔀 %s: %d threshold crossings\n", alternative.Name, len(alternative.Crossings))
+		for _, crossing := range alternative.Crossings {
+			fmt.Printf("   %s: %s (%.1f) → %s (%.1f)\n", crossing.Key, crossing.FromLevel, crossing.From, crossing.ToLevel, crossing.To)
+		}
+
+		fmt.Printf("↕️  %s: %d rank changes\n", alternative.Name, len(alternative.RankChanges))
+		for _, change := range alternative.RankChanges {
+			fmt.Printf("   #%d → #%d %s\n", change.FromRank, change.ToRank, change.Key)
+		}
+	}
+}
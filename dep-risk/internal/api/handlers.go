@@ -99,6 +99,8 @@ func (s *Server) createScan(c *gin.Context) {
 		MediumRiskCount:      req.ScanResult.MediumRiskCount,
 		LowRiskCount:         req.ScanResult.LowRiskCount,
 		ScanDuration:         req.ScanResult.ScanDuration,
+		ModelVersion:         req.ScanResult.ModelVersion,
+		ConfigHash:           req.ScanResult.ConfigHash,
 	}
 
 	if err := tx.Create(&scan).Error; err != nil {
@@ -122,6 +124,8 @@ func (s *Server) createScan(c *gin.Context) {
 			Severity:       vulnReq.Severity,
 			IsDirect:       vulnReq.IsDirect,
 			Description:    vulnReq.Description,
+			Explanation:    vulnReq.Explanation,
+			Finding:        vulnReq.Finding,
 		}
 
 		if err := tx.Create(&vuln).Error; err != nil {
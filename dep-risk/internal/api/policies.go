@@ -0,0 +1,168 @@
+package api
+
+import (
+	"crypto/subtle"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+
+	"github.com/dep-risk/dep-risk/internal/config"
+	"github.com/dep-risk/dep-risk/internal/database"
+	"github.com/dep-risk/dep-risk/internal/models"
+)
+
+// maxPolicySize bounds uploaded policy files
+const maxPolicySize = 1 << 20
+
+// policyAdminKey returns the admin credential of an organization from
+// POLICY_ADMIN_KEYS, a comma-separated list of org=key entries where the org
+// * matches every organization
+func policyAdminKey(org string) string {
+	fallback := ""
+	for _, entry := range strings.Split(os.Getenv("POLICY_ADMIN_KEYS"), ",") {
+		name, key, ok := strings.Cut(strings.TrimSpace(entry), "=")
+		if !ok || key == "" {
+			continue
+		}
+		if strings.EqualFold(name, org) {
+			return key
+		}
+		if name == "*" {
+			fallback = key
+		}
+	}
+	return fallback
+}
+
+// policyAdminMiddleware requires the admin credential of the organization in
+// the X-Admin-Key header. Policies decide what repositories may loosen, so the
+// shared API key is not enough, in any environment, and organizations without
+// an admin key cannot change their policies through the API.
+func policyAdminMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		want := policyAdminKey(c.Param("org"))
+		if want == "" {
+			c.JSON(http.StatusForbidden, models.APIResponse{
+				Success: false,
+				Error:   "Policy updates are not enabled for this organization",
+			})
+			c.Abort()
+			return
+		}
+
+		got := c.GetHeader("X-Admin-Key")
+		if subtle.ConstantTimeCompare([]byte(got), []byte(want)) != 1 {
+			c.JSON(http.StatusUnauthorized, models.APIResponse{
+				Success: false,
+				Error:   "Invalid organization admin key",
+			})
+			c.Abort()
+			return
+		}
+
+		c.Next()
+	}
+}
+
+// listPolicies handles GET /api/v1/orgs/:org/policies
+func (s *Server) listPolicies(c *gin.Context) {
+	db := database.GetDB()
+	var policies []models.Policy
+	err := db.Joins("JOIN organizations ON organizations.id = policies.org_id").
+		Where("organizations.git_hub_org = ?", c.Param("org")).
+		Select("policies.id, policies.org_id, policies.name, policies.created_at, policies.updated_at").
+		Order("policies.name").
+		Find(&policies).Error
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.APIResponse{
+			Success: false,
+			Error:   "Database error",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, models.APIResponse{
+		Success: true,
+		Data:    policies,
+	})
+}
+
+// getPolicy handles GET /api/v1/orgs/:org/policies/:name
+// The policy is returned as YAML so that `extends: api:<org>/<name>` can
+// read it like a local file.
+func (s *Server) getPolicy(c *gin.Context) {
+	db := database.GetDB()
+	var policy models.Policy
+	err := db.Joins("JOIN organizations ON organizations.id = policies.org_id").
+		Where("organizations.git_hub_org = ? AND policies.name = ?", c.Param("org"), c.Param("name")).
+		First(&policy).Error
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			c.JSON(http.StatusNotFound, models.APIResponse{
+				Success: false,
+				Error:   "Policy not found",
+			})
+		} else {
+			c.JSON(http.StatusInternalServerError, models.APIResponse{
+				Success: false,
+				Error:   "Database error",
+			})
+		}
+		return
+	}
+
+	c.Data(http.StatusOK, "application/yaml", []byte(policy.Content))
+}
+
+// putPolicy handles PUT /api/v1/orgs/:org/policies/:name with a YAML body.
+// It runs behind policyAdminMiddleware.
+func (s *Server) putPolicy(c *gin.Context) {
+	content, err := io.ReadAll(io.LimitReader(c.Request.Body, maxPolicySize+1))
+	if err != nil || len(content) > maxPolicySize {
+		c.JSON(http.StatusBadRequest, models.APIResponse{
+			Success: false,
+			Error:   "Policy must be a YAML body of at most 1 MiB",
+		})
+		return
+	}
+	if err := config.ValidatePolicy(content); err != nil {
+		c.JSON(http.StatusBadRequest, models.APIResponse{
+			Success: false,
+			Error:   "Invalid policy: " + err.Error(),
+		})
+		return
+	}
+
+	db := database.GetDB()
+	orgName := c.Param("org")
+	var policy models.Policy
+	err = db.Transaction(func(tx *gorm.DB) error {
+		org := models.Organization{GitHubOrg: orgName, Name: orgName}
+		if err := tx.Where("git_hub_org = ?", orgName).FirstOrCreate(&org).Error; err != nil {
+			return err
+		}
+		policy = models.Policy{OrgID: org.ID, Name: c.Param("name")}
+		if err := tx.Where("org_id = ? AND name = ?", org.ID, policy.Name).FirstOrCreate(&policy).Error; err != nil {
+			return err
+		}
+		policy.Content = string(content)
+		return tx.Save(&policy).Error
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.APIResponse{
+			Success: false,
+			Error:   "Failed to save policy",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, models.APIResponse{
+		Success: true,
+		Message: "Policy saved",
+		Data:    policy,
+	})
+}
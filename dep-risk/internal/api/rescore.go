@@ -0,0 +1,124 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+
+	"github.com/dep-risk/dep-risk/internal/database"
+	"github.com/dep-risk/dep-risk/internal/models"
+	"github.com/dep-risk/dep-risk/internal/scanner"
+	"github.com/dep-risk/dep-risk/internal/scorer"
+)
+
+// getScoringModels handles GET /api/v1/models
+func (s *Server) getScoringModels(c *gin.Context) {
+	c.JSON(http.StatusOK, models.APIResponse{
+		Success: true,
+		Data: gin.H{
+			"latest": scorer.LatestModelVersion,
+			"models": scorer.Models(),
+		},
+	})
+}
+
+// rescoreScan handles GET /api/v1/scans/:id/rescore?model=<version>
+// Stored findings are scored with the defaults of the requested model
+// (the latest by default) and compared with the stored scores.
+func (s *Server) rescoreScan(c *gin.Context) {
+	scanID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.APIResponse{
+			Success: false,
+			Error:   "Invalid scan ID",
+		})
+		return
+	}
+
+	scoringModel := c.DefaultQuery("model", scorer.LatestModelVersion)
+	scorerInstance, err := scorer.NewScorerForModel(scoringModel)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.APIResponse{
+			Success: false,
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	db := database.GetDB()
+	var scan models.Scan
+	if err := db.Preload("Vulnerabilities").First(&scan, scanID).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			c.JSON(http.StatusNotFound, models.APIResponse{
+				Success: false,
+				Error:   "Scan not found",
+			})
+		} else {
+			c.JSON(http.StatusInternalServerError, models.APIResponse{
+				Success: false,
+				Error:   "Database error",
+			})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, models.APIResponse{
+		Success: true,
+		Data:    rescore(scan, scorerInstance),
+	})
+}
+
+// rescore scores the stored findings of a scan again and compares the results
+func rescore(scan models.Scan, scorerInstance *scorer.Scorer) models.RescoreResult {
+	findings := make([]scanner.Vulnerability, len(scan.Vulnerabilities))
+	for i, record := range scan.Vulnerabilities {
+		findings[i] = findingFromRecord(record)
+	}
+	projectScore := scorerInstance.CalculateProjectScore(&scanner.ScanResult{Vulnerabilities: findings})
+
+	result := models.RescoreResult{
+		ScanID:             scan.ID,
+		StoredModelVersion: scan.ModelVersion,
+		StoredConfigHash:   scan.ConfigHash,
+		StoredRiskScore:    scan.OverallRiskScore,
+		ModelVersion:       projectScore.ModelVersion,
+		ConfigHash:         projectScore.ConfigHash,
+		RiskScore:          projectScore.OverallScore,
+		Delta:              projectScore.OverallScore - scan.OverallRiskScore,
+	}
+	for i, record := range scan.Vulnerabilities {
+		score := projectScore.VulnerabilityScores[i]
+		result.Vulnerabilities = append(result.Vulnerabilities, models.RescoredVulnerability{
+			ID:              record.ID,
+			CVEID:           record.CVEID,
+			PackageName:     record.PackageName,
+			PackageVersion:  record.PackageVersion,
+			StoredRiskScore: record.RiskScore,
+			RiskScore:       score.Overall,
+			Delta:           score.Overall - record.RiskScore,
+		})
+	}
+	return result
+}
+
+// findingFromRecord restores a finding from a stored vulnerability, using the
+// full finding when the action recorded one
+func findingFromRecord(record models.Vulnerability) scanner.Vulnerability {
+	var finding scanner.Vulnerability
+	if len(record.Finding) > 0 && json.Unmarshal(record.Finding, &finding) == nil {
+		return finding
+	}
+
+	return scanner.Vulnerability{
+		ID:          record.CVEID,
+		Package:     record.PackageName,
+		Version:     record.PackageVersion,
+		CVSS:        record.CVSSScore,
+		Severity:    record.Severity,
+		IsDirect:    record.IsDirect,
+		Description: record.Description,
+	}
+}
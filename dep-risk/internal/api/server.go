@@ -63,6 +63,8 @@ func (s *Server) setupRoutes() {
 		// Scan endpoints
 		v1.POST("/scans", s.createScan)
 		v1.GET("/scans/:id", s.getScan)
+		v1.GET("/scans/:id/rescore", s.rescoreScan)
+		v1.GET("/models", s.getScoringModels)
 
 		// Organization endpoints
 		orgs := v1.Group("/orgs/:org")
@@ -70,6 +72,9 @@ func (s *Server) setupRoutes() {
 			orgs.GET("/dashboard", s.getOrganizationDashboard)
 			orgs.GET("/repos", s.getOrganizationRepos)
 			orgs.GET("/stats", s.getOrganizationStats)
+			orgs.GET("/policies", s.listPolicies)
+			orgs.GET("/policies/:name", s.getPolicy)
+			orgs.PUT("/policies/:name", policyAdminMiddleware(), s.putPolicy)
 		}
 
 		// Repository endpoints
@@ -148,7 +153,7 @@ func corsMiddleware() gin.HandlerFunc {
 	return func(c *gin.Context) {
 		c.Header("Access-Control-Allow-Origin", "*")
 		c.Header("Access-Control-Allow-Credentials", "true")
-		c.Header("Access-Control-Allow-Headers", "Content-Type, Content-Length, Accept-Encoding, X-CSRF-Token, Authorization, accept, origin, Cache-Control, X-Requested-With")
+		c.Header("Access-Control-Allow-Headers", "Content-Type, Content-Length, Accept-Encoding, X-CSRF-Token, Authorization, X-Admin-Key, accept, origin, Cache-Control, X-Requested-With")
 		c.Header("Access-Control-Allow-Methods", "POST, OPTIONS, GET, PUT, DELETE")
 
 		if c.Request.Method == "OPTIONS" {
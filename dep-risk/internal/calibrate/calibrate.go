@@ -0,0 +1,280 @@
+// Package calibrate fits scoring weights to labeled triage outcomes.
+//
+// Each example holds the component values of a finding (as recorded in its
+// score explanation) and a ground-truth impact on the 0-10 score scale. The
+// weights are fitted by least squares constrained to be non-negative and to
+// sum to 1, so the fitted weights are valid scoring weights.
+package calibrate
+
+import (
+	"fmt"
+	"math"
+	"strings"
+
+	"github.com/dep-risk/dep-risk/internal/scorer"
+)
+
+// Components are the weighted score components, in feature order
+var Components = []string{"cvss", "popularity", "dependency", "context", "maintenance"}
+
+// Example is a labeled finding
+type Example struct {
+	ID       string
+	Package  string
+	Features [5]float64 // component values, ordered as Components
+	Impact   float64    // ground-truth impact on the 0-10 scale
+	Positive bool       // whether the finding should have failed the check
+}
+
+// Dataset is a set of labeled findings
+type Dataset struct {
+	Examples []Example
+	// Missing lists components the dataset has no values for; they are not
+	// fitted and get weight 0
+	Missing []string
+	// Skipped counts records without component values
+	Skipped int
+}
+
+// Metrics describe how well weights separate positive from negative findings
+// at a fail threshold
+type Metrics struct {
+	TruePositives  int     `json:"true_positives"`
+	FalsePositives int     `json:"false_positives"`
+	FalseNegatives int     `json:"false_negatives"`
+	TrueNegatives  int     `json:"true_negatives"`
+	Precision      float64 `json:"precision"`
+	Recall         float64 `json:"recall"`
+	RMSE           float64 `json:"rmse"`
+}
+
+// Result compares the current weights with the fitted weights
+type Result struct {
+	Examples  int                   `json:"examples"`
+	Positives int                   `json:"positives"`
+	Skipped   int                   `json:"skipped"`
+	Threshold float64               `json:"threshold"`
+	Current   scorer.ScoringWeights `json:"current"`
+	Fitted    scorer.ScoringWeights `json:"fitted"`
+	Before    Metrics               `json:"before"`
+	After     Metrics               `json:"after"`
+}
+
+// MinExamples is the smallest dataset weights are fitted to
+const MinExamples = 10
+
+const (
+	maxIterations = 20000
+	tolerance     = 1e-10
+)
+
+// Calibrate fits weights to a dataset and evaluates the current and fitted
+// weights at the fail threshold. The hygiene weight is not fitted.
+func Calibrate(dataset *Dataset, current scorer.ScoringWeights, threshold float64) (*Result, error) {
+	if len(dataset.Examples) < MinExamples {
+		return nil, fmt.Errorf("need at least %d labeled findings, got %d", MinExamples, len(dataset.Examples))
+	}
+
+	fitted, err := Fit(dataset.Examples, dataset.Missing)
+	if err != nil {
+		return nil, err
+	}
+	fitted.Hygiene = current.Hygiene
+
+	result := &Result{
+		Examples:  len(dataset.Examples),
+		Skipped:   dataset.Skipped,
+		Threshold: threshold,
+		Current:   current,
+		Fitted:    fitted,
+		Before:    Evaluate(dataset.Examples, current, threshold),
+		After:     Evaluate(dataset.Examples, fitted, threshold),
+	}
+	for _, example := range dataset.Examples {
+		if example.Positive {
+			result.Positives++
+		}
+	}
+	return result, nil
+}
+
+// Fit finds the weights minimising the squared error between the weighted
+// component sum and the impact, with each weight non-negative and the
+// weights summing to 1. Components listed in fixed get weight 0. The
+// problem is solved by projected gradient descent.
+func Fit(examples []Example, fixed []string) (scorer.ScoringWeights, error) {
+	var active []int
+	for i, name := range Components {
+		if !containsName(fixed, name) {
+			active = append(active, i)
+		}
+	}
+	if len(active) == 0 {
+		return scorer.ScoringWeights{}, fmt.Errorf("no components to fit")
+	}
+	if len(examples) == 0 {
+		return scorer.ScoringWeights{}, fmt.Errorf("no labeled findings")
+	}
+
+	// The step size comes from a bound on the Lipschitz constant of the gradient
+	var lipschitz float64
+	for _, example := range examples {
+		for _, i := range active {
+			lipschitz += example.Features[i] * example.Features[i]
+		}
+	}
+	lipschitz *= 2 / float64(len(examples))
+	if lipschitz == 0 {
+		return scorer.ScoringWeights{}, fmt.Errorf("all component values are zero")
+	}
+	step := 1 / lipschitz
+
+	// Start from equal weights
+	weights := make([]float64, len(active))
+	for i := range weights {
+		weights[i] = 1 / float64(len(active))
+	}
+
+	gradient := make([]float64, len(active))
+	for iteration := 0; iteration < maxIterations; iteration++ {
+		for i := range gradient {
+			gradient[i] = 0
+		}
+		for _, example := range examples {
+			residual := -example.Impact
+			for i, component := range active {
+				residual += weights[i] * example.Features[component]
+			}
+			for i, component := range active {
+				gradient[i] += 2 * residual * example.Features[component] / float64(len(examples))
+			}
+		}
+
+		next := make([]float64, len(weights))
+		for i := range weights {
+			next[i] = weights[i] - step*gradient[i]
+		}
+		next = projectSimplex(next)
+
+		var change float64
+		for i := range weights {
+			change = math.Max(change, math.Abs(next[i]-weights[i]))
+		}
+		weights = next
+		if change < tolerance {
+			break
+		}
+	}
+
+	var values [5]float64
+	for i, component := range active {
+		values[component] = weights[i]
+	}
+	return weightsFromValues(values), nil
+}
+
+// Evaluate scores the examples with weights and counts the findings at or
+// above the threshold against their labels
+func Evaluate(examples []Example, weights scorer.ScoringWeights, threshold float64) Metrics {
+	var metrics Metrics
+	var squaredError float64
+	values := valuesFromWeights(weights)
+	for _, example := range examples {
+		var score float64
+		for i, value := range example.Features {
+			score += value * values[i]
+		}
+		score = math.Max(0, math.Min(10, score))
+		squaredError += (score - example.Impact) * (score - example.Impact)
+
+		failed := score >= threshold
+		switch {
+		case failed && example.Positive:
+			metrics.TruePositives++
+		case failed && !example.Positive:
+			metrics.FalsePositives++
+		case !failed && example.Positive:
+			metrics.FalseNegatives++
+		default:
+			metrics.TrueNegatives++
+		}
+	}
+
+	if flagged := metrics.TruePositives + metrics.FalsePositives; flagged > 0 {
+		metrics.Precision = float64(metrics.TruePositives) / float64(flagged)
+	}
+	if positives := metrics.TruePositives + metrics.FalseNegatives; positives > 0 {
+		metrics.Recall = float64(metrics.TruePositives) / float64(positives)
+	}
+	if len(examples) > 0 {
+		metrics.RMSE = math.Sqrt(squaredError / float64(len(examples)))
+	}
+	return metrics
+}
+
+// Snippet formats fitted weights as a dep-risk config snippet
+func (r *Result) Snippet() string {
+	var snippet strings.Builder
+	fmt.Fprintf(&snippet, "# Fitted by dep-risk calibrate on %d labeled findings (%d positive)\n", r.Examples, r.Positives)
+	fmt.Fprintf(&snippet, "# Precision %.2f -> %.2f, recall %.2f -> %.2f at thresholds.fail_score %.1f\n",
+		r.Before.Precision, r.After.Precision, r.Before.Recall, r.After.Recall, r.Threshold)
+	snippet.WriteString("scoring:\n  weights:\n")
+	fmt.Fprintf(&snippet, "    cvss: %.3f\n", r.Fitted.CVSS)
+	fmt.Fprintf(&snippet, "    popularity: %.3f\n", r.Fitted.Popularity)
+	fmt.Fprintf(&snippet, "    dependency: %.3f\n", r.Fitted.Dependency)
+	fmt.Fprintf(&snippet, "    context: %.3f\n", r.Fitted.Context)
+	fmt.Fprintf(&snippet, "    maintenance: %.3f\n", r.Fitted.Maintenance)
+	return snippet.String()
+}
+
+// projectSimplex returns the Euclidean projection of v onto the probability
+// simplex {w : w >= 0, sum(w) = 1}
+func projectSimplex(v []float64) []float64 {
+	sorted := append([]float64(nil), v...)
+	// Sort descending; the vectors are tiny
+	for i := 1; i < len(sorted); i++ {
+		for j := i; j > 0 && sorted[j] > sorted[j-1]; j-- {
+			sorted[j], sorted[j-1] = sorted[j-1], sorted[j]
+		}
+	}
+
+	var sum, theta float64
+	for i, value := range sorted {
+		sum += value
+		if candidate := (sum - 1) / float64(i+1); value-candidate > 0 {
+			theta = candidate
+		}
+	}
+
+	projected := make([]float64, len(v))
+	for i, value := range v {
+		projected[i] = math.Max(0, value-theta)
+	}
+	return projected
+}
+
+// valuesFromWeights orders the component weights as Components
+func valuesFromWeights(weights scorer.ScoringWeights) [5]float64 {
+	return [5]float64{weights.CVSS, weights.Popularity, weights.Dependency, weights.Context, weights.Maintenance}
+}
+
+// weightsFromValues is the inverse of valuesFromWeights
+func weightsFromValues(values [5]float64) scorer.ScoringWeights {
+	return scorer.ScoringWeights{
+		CVSS:        values[0],
+		Popularity:  values[1],
+		Dependency:  values[2],
+		Context:     values[3],
+		Maintenance: values[4],
+	}
+}
+
+// containsName reports whether names contains name
+func containsName(names []string, name string) bool {
+	for _, candidate := range names {
+		if candidate == name {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,159 @@
+package calibrate
+
+import (
+	"encoding/json"
+	"math"
+	"strings"
+	"testing"
+
+	"github.com/dep-risk/dep-risk/internal/scanner"
+	"github.com/dep-risk/dep-risk/internal/scorer"
+)
+
+func TestFitRecoversWeights(t *testing.T) {
+	truth := [5]float64{0.7, 0.1, 0.2, 0, 0}
+	var examples []Example
+	for i := 0; i < 40; i++ {
+		features := [5]float64{
+			float64(i%10) + 0.5,
+			float64((i*3)%10) + 0.2,
+			float64((i*7)%10) + 0.1,
+			float64((i*9)%10) + 0.3,
+			5,
+		}
+		var impact float64
+		for j, value := range features {
+			impact += value * truth[j]
+		}
+		examples = append(examples, Example{Features: features, Impact: impact, Positive: impact >= 7})
+	}
+
+	fitted, err := Fit(examples, nil)
+	if err != nil {
+		t.Fatalf("Fit failed: %v", err)
+	}
+	values := valuesFromWeights(fitted)
+	var sum float64
+	for i, value := range values {
+		sum += value
+		if value < 0 {
+			t.Errorf("Expected non-negative weights, got %v", values)
+		}
+		if math.Abs(value-truth[i]) > 0.01 {
+			t.Errorf("Weight %s: expected %.2f, got %.4f", Components[i], truth[i], value)
+		}
+	}
+	if math.Abs(sum-1) > 1e-9 {
+		t.Errorf("Expected weights to sum to 1, got %f", sum)
+	}
+
+	fixed, err := Fit(examples, []string{"cvss"})
+	if err != nil {
+		t.Fatalf("Fit failed: %v", err)
+	}
+	if fixed.CVSS != 0 {
+		t.Errorf("Expected a fixed component to get weight 0, got %f", fixed.CVSS)
+	}
+}
+
+func TestEvaluate(t *testing.T) {
+	examples := []Example{
+		{Features: [5]float64{9, 0, 0, 0, 0}, Positive: true, Impact: 10},
+		{Features: [5]float64{8, 0, 0, 0, 0}, Positive: false, Impact: 0},
+		{Features: [5]float64{2, 0, 0, 0, 0}, Positive: true, Impact: 10},
+		{Features: [5]float64{1, 0, 0, 0, 0}, Positive: false, Impact: 0},
+	}
+	metrics := Evaluate(examples, scorer.ScoringWeights{CVSS: 1}, 7)
+	if metrics.TruePositives != 1 || metrics.FalsePositives != 1 || metrics.FalseNegatives != 1 || metrics.TrueNegatives != 1 {
+		t.Errorf("Unexpected confusion matrix: %+v", metrics)
+	}
+	if metrics.Precision != 0.5 || metrics.Recall != 0.5 {
+		t.Errorf("Expected precision and recall 0.5, got %.2f/%.2f", metrics.Precision, metrics.Recall)
+	}
+}
+
+func TestLoadCSV(t *testing.T) {
+	data := `id,package,cvss,popularity,dependency,context,outcome,impact
+CVE-1,lodash,9.8,8,6,5,true_positive,
+CVE-2,debug,5.0,4,2,5,,3.5
+CVE-3,ms,7.5,4,2,5,,8
+`
+	dataset, err := LoadCSV(strings.NewReader(data), 7)
+	if err != nil {
+		t.Fatalf("LoadCSV failed: %v", err)
+	}
+	if len(dataset.Examples) != 3 {
+		t.Fatalf("Expected 3 examples, got %d", len(dataset.Examples))
+	}
+	if len(dataset.Missing) != 1 || dataset.Missing[0] != "maintenance" {
+		t.Errorf("Expected maintenance to be missing, got %v", dataset.Missing)
+	}
+	first, second, third := dataset.Examples[0], dataset.Examples[1], dataset.Examples[2]
+	if !first.Positive || first.Impact != 10 || first.Features[0] != 9.8 {
+		t.Errorf("Unexpected outcome-labeled example: %+v", first)
+	}
+	if second.Positive || second.Impact != 3.5 || !third.Positive {
+		t.Errorf("Expected impact labels classified at the threshold, got %+v and %+v", second, third)
+	}
+
+	if _, err := LoadCSV(strings.NewReader("cvss,outcome\n5,maybe\n"), 7); err == nil || !strings.Contains(err.Error(), "line 2") {
+		t.Errorf("Expected an unknown outcome to fail with its line, got %v", err)
+	}
+}
+
+func TestLoadExport(t *testing.T) {
+	score := scorer.NewScorer().CalculateVulnerabilityScore(scanner.Vulnerability{
+		ID: "CVE-2023-1", Package: "lodash", CVSS: 9.8, Severity: "CRITICAL", IsDirect: true,
+	})
+	explanation := score.Explanation
+	data := `[
+  {"cve_id": "CVE-2023-1", "package_name": "lodash", "explanation": ` + mustJSON(t, explanation) + `, "outcome": "exploitable"},
+  {"cve_id": "CVE-2023-2", "package_name": "old", "impact": 2}
+]`
+	dataset, err := LoadExport(strings.NewReader(data), 7)
+	if err != nil {
+		t.Fatalf("LoadExport failed: %v", err)
+	}
+	if len(dataset.Examples) != 1 || dataset.Skipped != 1 {
+		t.Fatalf("Expected 1 example and 1 skipped record, got %d and %d", len(dataset.Examples), dataset.Skipped)
+	}
+	if example := dataset.Examples[0]; example.Features[0] != 9.8 || !example.Positive {
+		t.Errorf("Unexpected example: %+v", example)
+	}
+}
+
+func TestCalibrate(t *testing.T) {
+	dataset := &Dataset{}
+	for i := 0; i < 5; i++ {
+		dataset.Examples = append(dataset.Examples, Example{Features: [5]float64{5, 5, 5, 5, 5}, Impact: 5})
+	}
+	if _, err := Calibrate(dataset, scorer.DefaultWeights(), 7); err == nil {
+		t.Error("Expected a small dataset to be rejected")
+	}
+
+	for i := 0; i < 10; i++ {
+		cvss := float64(i)
+		dataset.Examples = append(dataset.Examples, Example{Features: [5]float64{cvss, 5, 5, 5, 5}, Impact: cvss, Positive: cvss >= 7})
+	}
+	result, err := Calibrate(dataset, scorer.DefaultWeights(), 7)
+	if err != nil {
+		t.Fatalf("Calibrate failed: %v", err)
+	}
+	if result.Fitted.Hygiene != scorer.DefaultWeights().Hygiene {
+		t.Errorf("Expected the hygiene weight to be kept, got %f", result.Fitted.Hygiene)
+	}
+	if result.After.RMSE > result.Before.RMSE {
+		t.Errorf("Expected fitted weights to reduce the error: %.3f -> %.3f", result.Before.RMSE, result.After.RMSE)
+	}
+	if snippet := result.Snippet(); !strings.Contains(snippet, "    cvss: ") || !strings.Contains(snippet, "15 labeled findings") {
+		t.Errorf("Unexpected snippet:\n%s", snippet)
+	}
+}
+
+func mustJSON(t *testing.T, value interface{}) string {
+	data, err := json.Marshal(value)
+	if err != nil {
+		t.Fatalf("Failed to marshal: %v", err)
+	}
+	return string(data)
+}
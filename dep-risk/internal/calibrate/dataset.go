@@ -0,0 +1,208 @@
+package calibrate
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/dep-risk/dep-risk/internal/scorer"
+)
+
+// Triage outcomes accepted as labels, and whether the finding was a real risk
+var Outcomes = map[string]bool{
+	"true_positive":  true,
+	"exploitable":    true,
+	"confirmed":      true,
+	"fixed":          true,
+	"false_positive": false,
+	"not_affected":   false,
+	"accepted_risk":  false,
+	"wont_fix":       false,
+}
+
+// Load reads a labeled dataset: a CSV file, or a JSON export of the API
+// vulnerabilities table. Findings labeled only with an outcome get impact
+// 10 or 0; findings labeled only with an impact are positive when the
+// impact reaches the threshold.
+func Load(path string, threshold float64) (*Dataset, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	if strings.EqualFold(filepath.Ext(path), ".csv") {
+		return LoadCSV(file, threshold)
+	}
+	return LoadExport(file, threshold)
+}
+
+// LoadCSV reads a CSV dataset with a header row. Recognised columns are id,
+// package, the component names, impact and outcome; cvss and one of impact
+// or outcome are required.
+func LoadCSV(r io.Reader, threshold float64) (*Dataset, error) {
+	reader := csv.NewReader(r)
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read CSV header: %w", err)
+	}
+	columns := make(map[string]int)
+	for i, name := range header {
+		columns[strings.ToLower(strings.TrimSpace(name))] = i
+	}
+	if _, ok := columns["cvss"]; !ok {
+		return nil, fmt.Errorf("CSV has no cvss column")
+	}
+	_, hasImpact := columns["impact"]
+	_, hasOutcome := columns["outcome"]
+	if !hasImpact && !hasOutcome {
+		return nil, fmt.Errorf("CSV needs an impact or outcome column")
+	}
+
+	dataset := &Dataset{}
+	for _, name := range Components {
+		if _, ok := columns[name]; !ok {
+			dataset.Missing = append(dataset.Missing, name)
+		}
+	}
+
+	for line := 2; ; line++ {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read CSV: %w", err)
+		}
+
+		field := func(name string) string {
+			if i, ok := columns[name]; ok && i < len(record) {
+				return strings.TrimSpace(record[i])
+			}
+			return ""
+		}
+
+		example := Example{ID: field("id"), Package: field("package")}
+		for i, name := range Components {
+			if containsName(dataset.Missing, name) {
+				continue
+			}
+			value, err := strconv.ParseFloat(field(name), 64)
+			if err != nil {
+				return nil, fmt.Errorf("line %d: invalid %s value %q", line, name, field(name))
+			}
+			example.Features[i] = value
+		}
+
+		var impact *float64
+		if text := field("impact"); text != "" {
+			value, err := strconv.ParseFloat(text, 64)
+			if err != nil {
+				return nil, fmt.Errorf("line %d: invalid impact %q", line, text)
+			}
+			impact = &value
+		}
+		if err := label(&example, impact, field("outcome"), threshold); err != nil {
+			return nil, fmt.Errorf("line %d: %w", line, err)
+		}
+		dataset.Examples = append(dataset.Examples, example)
+	}
+	return dataset, nil
+}
+
+// exportRecord is a row of the API vulnerabilities table with its label
+type exportRecord struct {
+	CVEID       string              `json:"cve_id"`
+	PackageName string              `json:"package_name"`
+	Explanation *scorer.Explanation `json:"explanation"`
+	Impact      *float64            `json:"impact"`
+	Outcome     string              `json:"outcome"`
+}
+
+// LoadExport reads a JSON array of API vulnerability records, each with an
+// impact or outcome label. Component values come from the stored score
+// explanation; records without one are skipped.
+func LoadExport(r io.Reader, threshold float64) (*Dataset, error) {
+	var records []exportRecord
+	if err := json.NewDecoder(r).Decode(&records); err != nil {
+		return nil, fmt.Errorf("failed to parse export: %w", err)
+	}
+
+	dataset := &Dataset{}
+	for i, record := range records {
+		features, ok := explanationFeatures(record.Explanation)
+		if !ok {
+			dataset.Skipped++
+			continue
+		}
+
+		example := Example{ID: record.CVEID, Package: record.PackageName, Features: features}
+		if err := label(&example, record.Impact, record.Outcome, threshold); err != nil {
+			return nil, fmt.Errorf("record %d (%s): %w", i+1, record.CVEID, err)
+		}
+		dataset.Examples = append(dataset.Examples, example)
+	}
+	return dataset, nil
+}
+
+// explanationFeatures extracts the component values from a score explanation
+func explanationFeatures(explanation *scorer.Explanation) ([5]float64, bool) {
+	var features [5]float64
+	if explanation == nil {
+		return features, false
+	}
+
+	for _, child := range explanation.Children {
+		if child.Factor != "weighted_sum" {
+			continue
+		}
+		found := 0
+		for _, component := range child.Children {
+			for i, name := range Components {
+				if component.Factor == name {
+					features[i] = component.Value
+					found++
+				}
+			}
+		}
+		return features, found == len(Components)
+	}
+	return features, false
+}
+
+// label sets the impact and class of an example from its labels
+func label(example *Example, impact *float64, outcome string, threshold float64) error {
+	if outcome != "" {
+		positive, ok := Outcomes[strings.ToLower(outcome)]
+		if !ok {
+			return fmt.Errorf("unknown outcome %q", outcome)
+		}
+		example.Positive = positive
+		if impact == nil {
+			example.Impact = 0
+			if positive {
+				example.Impact = 10
+			}
+		}
+	}
+
+	if impact != nil {
+		if *impact < 0 || *impact > 10 {
+			return fmt.Errorf("impact must be between 0 and 10, got %.1f", *impact)
+		}
+		example.Impact = *impact
+		if outcome == "" {
+			example.Positive = *impact >= threshold
+		}
+	} else if outcome == "" {
+		return fmt.Errorf("missing impact or outcome")
+	}
+	return nil
+}
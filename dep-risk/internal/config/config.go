@@ -1,16 +1,41 @@
 package config
 
 import (
+	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
+	"reflect"
+	"sort"
 	"strconv"
 	"strings"
 
 	"gopkg.in/yaml.v3"
+
+	"github.com/dep-risk/dep-risk/internal/scanner"
+	"github.com/dep-risk/dep-risk/internal/scorer"
 )
 
-// Config represents the application configuration
+// ScoringConfig holds the scoring settings configured under `scoring`
+type ScoringConfig struct {
+	// Scoring model whose defaults apply to unset scoring settings
+	Model string `yaml:"model"`
+
+	// Industry and ecosystem multipliers applied to every finding
+	Modifiers scorer.ModifierParams `yaml:"modifiers"`
+
+	// Fix availability, advisory age and SLA multipliers
+	Timing scorer.TimingParams `yaml:"timing"`
+
+	// Custom rules applied in order after the modifiers
+	Rules []scorer.RuleSpec `yaml:"rules"`
+
+	// How finding scores are combined into the project score
+	Aggregation scorer.AggregationParams `yaml:"aggregation"`
+}
+
+// Config represents the application configuration. Configuration files use
+// the nested FileV1 schema; the yaml tags name the keys of the flat format.
 type Config struct {
 	FailThreshold    float64  `yaml:"fail_threshold"`
 	WarnThreshold    float64  `yaml:"warn_threshold"`
@@ -22,6 +47,7 @@ type Config struct {
 	PopularityWeight float64  `yaml:"popularity_weight"`
 	DependencyWeight float64  `yaml:"dependency_weight"`
 	ContextWeight    float64  `yaml:"context_weight"`
+	MaintenanceWeight float64 `yaml:"maintenance_weight"`
 	CommentMode      string   `yaml:"comment_mode"`
 	SarifUpload      bool     `yaml:"sarif_upload"`
 	DashboardUpload  bool     `yaml:"dashboard_upload"`
@@ -29,6 +55,84 @@ type Config struct {
 	ParallelJobs     int      `yaml:"parallel_jobs"`
 	CacheEnabled     bool     `yaml:"cache_enabled"`
 	CacheTTL         int      `yaml:"cache_ttl"`
+
+	// Popularity provider: builtin, snapshot or depsdev
+	PopularityProvider string `yaml:"popularity_provider"`
+	PopularitySnapshot string `yaml:"popularity_snapshot"`
+	PopularityURL      string `yaml:"popularity_url"`
+
+	// Dependency component formula
+	DependencyScoring scorer.DependencyParams `yaml:"dependency_scoring"`
+
+	// Nested scoring settings
+	Scoring ScoringConfig `yaml:"scoring"`
+
+	// Declared execution context for the context component; when absent the
+	// component is estimated from package names
+	Context *scorer.ContextInfo `yaml:"context"`
+
+	// Maintenance health sources, read from local files
+	ScorecardResults string `yaml:"scorecard_results"`
+	ReleaseMetadata  string `yaml:"release_metadata"`
+
+	// Hygiene checks for retracted Go versions and deprecated npm packages
+	HygieneEnabled      bool    `yaml:"hygiene_enabled"`
+	HygieneWeight       float64 `yaml:"hygiene_weight"`
+	GoProxy             string  `yaml:"goproxy"`
+	NPMRegistrySnapshot string  `yaml:"npm_registry_snapshot"`
+
+	// End-of-life checks for runtimes and frameworks
+	EOLEnabled     bool   `yaml:"eol_enabled"`
+	EOLDataset     string `yaml:"eol_dataset"`
+	EOLWarningDays int    `yaml:"eol_warning_days"`
+
+	// GitHub Actions workflow dependency checks and pinning policy
+	WorkflowScanEnabled   bool     `yaml:"workflow_scan_enabled"`
+	WorkflowAdvisories    string   `yaml:"workflow_advisories"`
+	WorkflowRequireSHA    bool     `yaml:"workflow_require_sha"`
+	WorkflowAllowedOwners []string `yaml:"workflow_allowed_owners"`
+
+	// Ignore entries by package and by file, and structured ignore rules,
+	// configured under `ignore`
+	IgnorePackages []string     `yaml:"-"`
+	IgnorePaths    []string     `yaml:"-"`
+	IgnoreRules    []IgnoreRule `yaml:"-"`
+
+	// Conditional thresholds per branch, event and path
+	Overrides []Override `yaml:"-"`
+
+	// Known Exploited Vulnerabilities lookup, from a local catalog or the
+	// catalog URL
+	KEVEnabled bool   `yaml:"-"`
+	KEVCatalog string `yaml:"-"`
+	KEVURL     string `yaml:"-"`
+
+	// Named gates evaluated over the findings
+	Gates []scorer.GateSpec `yaml:"-"`
+
+	// CEL policy files evaluated against the scored report, relative to the
+	// repository root
+	PolicyFiles []string `yaml:"-"`
+
+	// Notifications of the design document, accepted but not delivered
+	SlackNotification *SlackV1 `yaml:"-"`
+	EmailNotification *EmailV1 `yaml:"-"`
+
+	// LegacyFormat is set when the file used the flat format
+	LegacyFormat bool `yaml:"-"`
+
+	// Extends names the base configs the file extends, nearest first, and
+	// Locked the keys they lock
+	Extends string   `yaml:"-"`
+	Locked  []string `yaml:"-"`
+	base    *FileV1
+
+	// lines are the lines of the keys set in the config file
+	lines map[string]int
+
+	// Replay mode inputs are run options rather than repository settings
+	ReplayOSV  string `yaml:"-"`
+	ReplaySBOM string `yaml:"-"`
 }
 
 // DefaultConfig returns the default configuration
@@ -51,6 +155,14 @@ func DefaultConfig() *Config {
 		ParallelJobs:     4,
 		CacheEnabled:     true,
 		CacheTTL:         24,
+		DependencyScoring: scorer.DefaultDependencyParams(),
+		Scoring:          ScoringConfig{Model: scorer.LatestModelVersion, Timing: scorer.DefaultTimingParams(), Aggregation: scorer.DefaultAggregationParams()},
+		PopularityProvider: "builtin",
+		HygieneEnabled:   false,
+		HygieneWeight:    0.6,
+		EOLEnabled:       false,
+		EOLWarningDays:   90,
+		WorkflowScanEnabled: false,
 	}
 }
 
@@ -66,7 +178,9 @@ func LoadConfig(configPath string) (*Config, error) {
 	}
 
 	// Override with environment variables (GitHub Actions inputs)
-	config.loadFromEnv()
+	if err := config.loadFromEnv(); err != nil {
+		return nil, fmt.Errorf("invalid action inputs: %w", err)
+	}
 
 	// Validate configuration
 	if err := config.validate(); err != nil {
@@ -82,140 +196,368 @@ func (c *Config) loadFromFile(path string) error {
 	if err != nil {
 		return err
 	}
-
-	return yaml.Unmarshal(data, c)
+	return c.load(data, filepath.Dir(path))
 }
 
-// loadFromEnv loads configuration from environment variables
-func (c *Config) loadFromEnv() {
-	if val := os.Getenv("INPUT_FAIL_THRESHOLD"); val != "" {
-		if f, err := strconv.ParseFloat(val, 64); err == nil {
-			c.FailThreshold = f
-		}
+// load applies a configuration file merged over the files it extends, which
+// are resolved relative to dir. Files without a version use the flat format
+// and are migrated to the current schema first. Unknown keys and values of
+// the wrong type are reported together as Problems once the rest of the file
+// is applied.
+func (c *Config) load(data []byte, dir string) error {
+	root, legacy, err := parseVersioned(data)
+	if err != nil {
+		return err
 	}
+	c.LegacyFormat = legacy
+	c.lines = make(map[string]int)
+	keyLines(root, "", c.lines)
+	problems := unknownKeys(root, reflect.TypeOf(FileV1{}), "")
 
-	if val := os.Getenv("INPUT_WARN_THRESHOLD"); val != "" {
-		if f, err := strconv.ParseFloat(val, 64); err == nil {
-			c.WarnThreshold = f
-		}
+	result, err := resolveExtends(root, dir, 0, make(map[string]bool))
+	if err != nil {
+		return err
 	}
-
-	if val := os.Getenv("INPUT_SCAN_PATHS"); val != "" {
-		c.ScanPaths = strings.Split(val, ",")
-		for i := range c.ScanPaths {
-			c.ScanPaths[i] = strings.TrimSpace(c.ScanPaths[i])
+	if result.base != nil {
+		// Locked keys are checked against the settings of the base alone
+		base := DefaultConfig()
+		if err := base.apply(result.base.root); err != nil {
+			return fmt.Errorf("%s: %w", result.sources[0], err)
 		}
+		baseFile := base.File()
+		c.base = &baseFile
+		c.Extends = strings.Join(result.sources, " <- ")
+		c.Locked = result.base.locked
 	}
 
-	if val := os.Getenv("INPUT_EXCLUDE_PATHS"); val != "" {
-		c.ExcludePaths = strings.Split(val, ",")
-		for i := range c.ExcludePaths {
-			c.ExcludePaths[i] = strings.TrimSpace(c.ExcludePaths[i])
+	if err := c.apply(result.root); err != nil {
+		typeErr, ok := err.(*yaml.TypeError)
+		if !ok {
+			return err
 		}
+		problems = append(problems, typeProblems(typeErr)...)
 	}
-
-	if val := os.Getenv("INPUT_LANGUAGES"); val != "" {
-		c.Languages = strings.Split(val, ",")
-		for i := range c.Languages {
-			c.Languages[i] = strings.TrimSpace(c.Languages[i])
-		}
+	if len(problems) > 0 {
+		problems.sort()
+		return problems
 	}
+	return nil
+}
 
-	if val := os.Getenv("INPUT_CVSS_WEIGHT"); val != "" {
-		if f, err := strconv.ParseFloat(val, 64); err == nil {
-			c.CVSSWeight = f
+// apply decodes a version 1 file over the current settings. Values of the
+// wrong type are skipped and returned as a *yaml.TypeError.
+func (c *Config) apply(root *yaml.Node) error {
+	// The selected model provides the defaults the rest of the file overrides
+	if model := mappingValue(mappingValue(root, "scoring"), "model"); model != nil && model.Value != "" {
+		if err := c.applyModel(model.Value); err != nil {
+			return err
 		}
 	}
 
-	if val := os.Getenv("INPUT_POPULARITY_WEIGHT"); val != "" {
-		if f, err := strconv.ParseFloat(val, 64); err == nil {
-			c.PopularityWeight = f
-		}
+	file := c.File()
+	err := root.Decode(&file)
+	if _, ok := err.(*yaml.TypeError); err != nil && !ok {
+		return err
 	}
+	c.applyFile(file)
+	return err
+}
 
-	if val := os.Getenv("INPUT_DEPENDENCY_WEIGHT"); val != "" {
-		if f, err := strconv.ParseFloat(val, 64); err == nil {
-			c.DependencyWeight = f
-		}
+// applyModel resets the scoring settings to the defaults of a scoring model
+func (c *Config) applyModel(version string) error {
+	model, err := scorer.LookupModel(version)
+	if err != nil {
+		return err
 	}
 
-	if val := os.Getenv("INPUT_CONTEXT_WEIGHT"); val != "" {
-		if f, err := strconv.ParseFloat(val, 64); err == nil {
-			c.ContextWeight = f
-		}
-	}
+	c.Scoring.Model = model.Version
+	c.CVSSWeight = model.Weights.CVSS
+	c.PopularityWeight = model.Weights.Popularity
+	c.DependencyWeight = model.Weights.Dependency
+	c.ContextWeight = model.Weights.Context
+	c.MaintenanceWeight = model.Weights.Maintenance
+	c.HygieneWeight = model.Weights.Hygiene
+	c.DependencyScoring = model.Dependency
+	return nil
+}
 
-	if val := os.Getenv("INPUT_COMMENT_MODE"); val != "" {
-		c.CommentMode = val
-	}
+// loadFromEnv loads configuration from environment variables. Inputs that
+// do not parse are reported together and leave their setting unchanged.
+func (c *Config) loadFromEnv() error {
+	var env envInputs
+	env.float("INPUT_FAIL_THRESHOLD", &c.FailThreshold)
+	env.float("INPUT_WARN_THRESHOLD", &c.WarnThreshold)
+	env.list("INPUT_SCAN_PATHS", &c.ScanPaths)
+	env.list("INPUT_EXCLUDE_PATHS", &c.ExcludePaths)
+	env.list("INPUT_LANGUAGES", &c.Languages)
+	env.float("INPUT_CVSS_WEIGHT", &c.CVSSWeight)
+	env.float("INPUT_POPULARITY_WEIGHT", &c.PopularityWeight)
+	env.float("INPUT_DEPENDENCY_WEIGHT", &c.DependencyWeight)
+	env.float("INPUT_CONTEXT_WEIGHT", &c.ContextWeight)
+	env.str("INPUT_COMMENT_MODE", &c.CommentMode)
+	env.bool("INPUT_SARIF_UPLOAD", &c.SarifUpload)
+	env.bool("INPUT_DASHBOARD_UPLOAD", &c.DashboardUpload)
+	env.int("INPUT_TIMEOUT", &c.Timeout)
+	env.int("INPUT_PARALLEL_JOBS", &c.ParallelJobs)
+	env.bool("INPUT_CACHE_ENABLED", &c.CacheEnabled)
+	env.int("INPUT_CACHE_TTL", &c.CacheTTL)
+	env.float("INPUT_MAINTENANCE_WEIGHT", &c.MaintenanceWeight)
+	env.str("INPUT_SCORECARD_RESULTS", &c.ScorecardResults)
+	env.str("INPUT_RELEASE_METADATA", &c.ReleaseMetadata)
+	env.str("INPUT_AGGREGATION_STRATEGY", &c.Scoring.Aggregation.Strategy)
+	env.str("INPUT_POPULARITY_PROVIDER", &c.PopularityProvider)
+	env.str("INPUT_POPULARITY_SNAPSHOT", &c.PopularitySnapshot)
+	env.str("INPUT_POPULARITY_URL", &c.PopularityURL)
+	env.bool("INPUT_HYGIENE_ENABLED", &c.HygieneEnabled)
+	env.float("INPUT_HYGIENE_WEIGHT", &c.HygieneWeight)
+	env.str("INPUT_GOPROXY", &c.GoProxy)
+	env.str("INPUT_NPM_REGISTRY_SNAPSHOT", &c.NPMRegistrySnapshot)
+	env.bool("INPUT_EOL_ENABLED", &c.EOLEnabled)
+	env.str("INPUT_EOL_DATASET", &c.EOLDataset)
+	env.int("INPUT_EOL_WARNING_DAYS", &c.EOLWarningDays)
+	env.bool("INPUT_WORKFLOW_SCAN_ENABLED", &c.WorkflowScanEnabled)
+	env.str("INPUT_WORKFLOW_ADVISORIES", &c.WorkflowAdvisories)
+	env.bool("INPUT_WORKFLOW_REQUIRE_SHA", &c.WorkflowRequireSHA)
+	env.list("INPUT_WORKFLOW_ALLOWED_OWNERS", &c.WorkflowAllowedOwners)
+	env.bool("INPUT_KEV_ENABLED", &c.KEVEnabled)
+	env.str("INPUT_KEV_CATALOG", &c.KEVCatalog)
+	env.list("INPUT_POLICY_FILES", &c.PolicyFiles)
+	env.str("INPUT_REPLAY_OSV", &c.ReplayOSV)
+	env.str("INPUT_REPLAY_SBOM", &c.ReplaySBOM)
+	return errors.Join(env.errs...)
+}
 
-	if val := os.Getenv("INPUT_SARIF_UPLOAD"); val != "" {
-		c.SarifUpload = val == "true"
-	}
+// envInputs parses action inputs from the environment, collecting the ones
+// that do not parse
+type envInputs struct {
+	errs []error
+}
 
-	if val := os.Getenv("INPUT_DASHBOARD_UPLOAD"); val != "" {
-		c.DashboardUpload = val == "true"
+func (e *envInputs) str(name string, target *string) {
+	if val := os.Getenv(name); val != "" {
+		*target = val
 	}
+}
 
-	if val := os.Getenv("INPUT_TIMEOUT"); val != "" {
-		if i, err := strconv.Atoi(val); err == nil {
-			c.Timeout = i
-		}
+func (e *envInputs) list(name string, target *[]string) {
+	val := os.Getenv(name)
+	if val == "" {
+		return
+	}
+	*target = strings.Split(val, ",")
+	for i := range *target {
+		(*target)[i] = strings.TrimSpace((*target)[i])
 	}
+}
 
-	if val := os.Getenv("INPUT_PARALLEL_JOBS"); val != "" {
-		if i, err := strconv.Atoi(val); err == nil {
-			c.ParallelJobs = i
+func (e *envInputs) float(name string, target *float64) {
+	if val := os.Getenv(name); val != "" {
+		f, err := strconv.ParseFloat(strings.TrimSpace(val), 64)
+		if err != nil {
+			e.invalid(name, val, "a number")
+			return
 		}
+		*target = f
 	}
+}
 
-	if val := os.Getenv("INPUT_CACHE_ENABLED"); val != "" {
-		c.CacheEnabled = val == "true"
+func (e *envInputs) int(name string, target *int) {
+	if val := os.Getenv(name); val != "" {
+		i, err := strconv.Atoi(strings.TrimSpace(val))
+		if err != nil {
+			e.invalid(name, val, "a whole number")
+			return
+		}
+		*target = i
 	}
+}
 
-	if val := os.Getenv("INPUT_CACHE_TTL"); val != "" {
-		if i, err := strconv.Atoi(val); err == nil {
-			c.CacheTTL = i
+func (e *envInputs) bool(name string, target *bool) {
+	if val := os.Getenv(name); val != "" {
+		switch strings.ToLower(strings.TrimSpace(val)) {
+		case "true":
+			*target = true
+		case "false":
+			*target = false
+		default:
+			e.invalid(name, val, "true or false")
 		}
 	}
 }
 
-// validate checks if the configuration is valid
+// invalid records an input that does not parse, by its action input name
+func (e *envInputs) invalid(name, val, want string) {
+	input := strings.ToLower(strings.TrimPrefix(name, "INPUT_"))
+	e.errs = append(e.errs, fmt.Errorf("input %s must be %s, got %q (%s)", input, want, val, name))
+}
+
+// popularityProviders are the values of popularity.provider
+var popularityProviders = []string{"builtin", "snapshot", "depsdev"}
+
+// commentModes are the values of notifications.comment_mode
+var commentModes = []string{"always", "on-failure", "never"}
+
+// validate checks if the configuration is valid, reporting every problem
+// found as Problems
 func (c *Config) validate() error {
+	var errs []error
 	if c.FailThreshold < 0 || c.FailThreshold > 10 {
-		return fmt.Errorf("fail_threshold must be between 0 and 10")
+		errs = append(errs, fmt.Errorf("thresholds.fail_score must be between 0 and 10"))
 	}
 
 	if c.WarnThreshold < 0 || c.WarnThreshold > 10 {
-		return fmt.Errorf("warn_threshold must be between 0 and 10")
+		errs = append(errs, fmt.Errorf("thresholds.warn_score must be between 0 and 10"))
 	}
 
 	if c.WarnThreshold > c.FailThreshold {
-		return fmt.Errorf("warn_threshold cannot be greater than fail_threshold")
+		errs = append(errs, fmt.Errorf("thresholds.warn_score cannot be greater than thresholds.fail_score"))
 	}
 
 	// Validate weights sum to approximately 1.0
-	totalWeight := c.CVSSWeight + c.PopularityWeight + c.DependencyWeight + c.ContextWeight
+	totalWeight := c.CVSSWeight + c.PopularityWeight + c.DependencyWeight + c.ContextWeight + c.MaintenanceWeight
 	if totalWeight < 0.9 || totalWeight > 1.1 {
-		return fmt.Errorf("scoring weights must sum to approximately 1.0, got %.2f", totalWeight)
+		errs = append(errs, fmt.Errorf("scoring.weights must sum to approximately 1.0, got %.2f", totalWeight))
+	}
+
+	if c.MaintenanceWeight < 0 {
+		errs = append(errs, fmt.Errorf("scoring.weights.maintenance cannot be negative"))
+	}
+
+	errs = append(errs, validateDependencyScoring(c.DependencyScoring))
+
+	if c.Context != nil {
+		errs = append(errs, validateContext(*c.Context))
+	}
+
+	if _, err := scorer.LookupModel(c.Scoring.Model); err != nil {
+		errs = append(errs, fmt.Errorf("scoring.model: %w", err))
+	}
+
+	errs = append(errs, validateModifiers(c.Scoring.Modifiers))
+	errs = append(errs, validateTiming(c.Scoring.Timing))
+
+	if rules, err := scorer.CompileRules(c.Scoring.Rules); err != nil {
+		errs = append(errs, fmt.Errorf("scoring.rules: %w", err))
+	} else if !c.KEVEnabled {
+		for i, rule := range rules {
+			if rule.References("kev") {
+				errs = append(errs, fmt.Errorf("scoring.rules[%d]: rule %s reads kev, which requires kev.enabled", i, rule.Spec.Name))
+			}
+		}
+	}
+
+	errs = append(errs, validateAggregation(c.Scoring.Aggregation))
+
+	if !contains(popularityProviders, c.PopularityProvider) {
+		errs = append(errs, fmt.Errorf("popularity.provider must be one of: %s", strings.Join(popularityProviders, ", ")))
+	}
+
+	if c.PopularityProvider == "snapshot" && c.PopularitySnapshot == "" {
+		errs = append(errs, fmt.Errorf("popularity.provider snapshot requires popularity.snapshot"))
+	}
+
+	if c.HygieneWeight < 0 || c.HygieneWeight > 1 {
+		errs = append(errs, fmt.Errorf("scoring.weights.hygiene must be between 0 and 1"))
+	}
+
+	if c.EOLWarningDays < 0 {
+		errs = append(errs, fmt.Errorf("eol.warning_days cannot be negative"))
 	}
 
-	validCommentModes := []string{"always", "on-failure", "never"}
-	if !contains(validCommentModes, c.CommentMode) {
-		return fmt.Errorf("comment_mode must be one of: %s", strings.Join(validCommentModes, ", "))
+	if !contains(commentModes, c.CommentMode) {
+		errs = append(errs, fmt.Errorf("notifications.comment_mode must be one of: %s", strings.Join(commentModes, ", ")))
 	}
 
 	if c.Timeout <= 0 {
-		return fmt.Errorf("timeout must be positive")
+		errs = append(errs, fmt.Errorf("scan.timeout must be positive"))
 	}
 
 	if c.ParallelJobs <= 0 {
-		return fmt.Errorf("parallel_jobs must be positive")
+		errs = append(errs, fmt.Errorf("scan.parallel_jobs must be positive"))
+	}
+
+	for i, entry := range c.IgnorePackages {
+		if strings.TrimSpace(entry) == "" {
+			errs = append(errs, fmt.Errorf("ignore.packages[%d] cannot be empty", i))
+			continue
+		}
+		if _, constraint := splitPackageEntry(entry); constraint != "" {
+			if _, err := parseConstraint(constraint); err != nil {
+				errs = append(errs, fmt.Errorf("ignore.packages[%d]: %q: %w", i, entry, err))
+			}
+		}
+	}
+
+	for i, pattern := range c.IgnorePaths {
+		if strings.TrimSpace(pattern) == "" {
+			errs = append(errs, fmt.Errorf("ignore.paths[%d] cannot be empty", i))
+		}
+	}
+
+	for i, rule := range c.IgnoreRules {
+		if err := rule.validate(); err != nil {
+			errs = append(errs, fmt.Errorf("ignore.rules[%d]: %w", i, err))
+		}
 	}
 
+	for i, override := range c.Overrides {
+		if err := override.validate(); err != nil {
+			errs = append(errs, fmt.Errorf("overrides[%d]: %w", i, err))
+			continue
+		}
+		fail, warn := override.Apply(c.FailThreshold, c.WarnThreshold)
+		if fail < 0 || fail > 10 || warn < 0 || warn > 10 {
+			errs = append(errs, fmt.Errorf("overrides[%d].thresholds must be between 0 and 10", i))
+		} else if warn > fail {
+			errs = append(errs, fmt.Errorf("overrides[%d].thresholds: warn_score %.1f cannot be greater than fail_score %.1f", i, warn, fail))
+		}
+	}
+
+	errs = append(errs, c.validateGates())
+
+	for i, path := range c.PolicyFiles {
+		if strings.TrimSpace(path) == "" {
+			errs = append(errs, fmt.Errorf("policies.files[%d] cannot be empty", i))
+		}
+	}
+
+	errs = append(errs, c.checkLocks())
+
+	if c.ReplaySBOM != "" && c.ReplayOSV == "" {
+		errs = append(errs, fmt.Errorf("replay_sbom requires replay_osv"))
+	}
+
+	if problems := c.problems(errors.Join(errs...)); len(problems) > 0 {
+		problems.sort()
+		return problems
+	}
 	return nil
 }
 
+// validateGates compiles the gates and checks that the ones reading KEV
+// status have the lookup enabled
+func (c *Config) validateGates() error {
+	gates, err := scorer.CompileGates(c.Gates)
+	if err != nil {
+		return fmt.Errorf("gates: %w", err)
+	}
+	if c.KEVEnabled {
+		return nil
+	}
+	var errs []error
+	for i, gate := range gates {
+		if gate.References("kev") {
+			errs = append(errs, fmt.Errorf("gates[%d]: gate %s reads kev, which requires kev.enabled", i, gate.Spec.Name))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// IsReplay reports whether the scan runs from recorded scanner output
+func (c *Config) IsReplay() bool {
+	return c.ReplayOSV != ""
+}
+
 // GetScoringWeights returns the scoring weights from the configuration
 func (c *Config) GetScoringWeights() (float64, float64, float64, float64) {
 	return c.CVSSWeight, c.PopularityWeight, c.DependencyWeight, c.ContextWeight
@@ -259,4 +601,173 @@ func (c *Config) GetConfigPath() string {
 		return filepath.Join(c.GetWorkingDirectory(), configPath)
 	}
 	return filepath.Join(c.GetWorkingDirectory(), ".github", "dep-risk.yml")
-}
\ No newline at end of file
+}
+
+// validateDependencyScoring checks the dependency component parameters
+func validateDependencyScoring(params scorer.DependencyParams) error {
+	var errs []error
+	values := []struct {
+		name  string
+		value float64
+	}{
+		{"direct_score", params.DirectScore},
+		{"transitive_score", params.TransitiveScore},
+		{"depth_decay", params.DepthDecay},
+		{"min_transitive_score", params.MinTransitiveScore},
+		{"dependents_bonus", params.DependentsBonus},
+		{"cyclic_bonus", params.CyclicBonus},
+	}
+	for _, v := range values {
+		if v.value < 0 || v.value > 10 {
+			errs = append(errs, fmt.Errorf("scoring.dependency.%s must be between 0 and 10", v.name))
+		}
+	}
+
+	if params.DependentsThreshold < 0 {
+		errs = append(errs, fmt.Errorf("scoring.dependency.dependents_threshold cannot be negative"))
+	}
+
+	validTypes := []string{scanner.DependencyProduction, scanner.DependencyDevelopment, scanner.DependencyOptional, scanner.DependencyPeer}
+	for depType, modifier := range params.TypeModifiers {
+		if !contains(validTypes, depType) {
+			errs = append(errs, fmt.Errorf("scoring.dependency.type_modifiers: unknown dependency type %q (valid: %s)", depType, strings.Join(validTypes, ", ")))
+		}
+		if modifier < 0 {
+			errs = append(errs, fmt.Errorf("scoring.dependency.type_modifiers.%s cannot be negative", depType))
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// validateContext checks a declared execution context against the known values
+func validateContext(context scorer.ContextInfo) error {
+	var errs []error
+	validExecution := sortedKeys(scorer.ExecutionModifiers)
+	for _, execution := range context.Execution {
+		if !contains(validExecution, execution) {
+			errs = append(errs, fmt.Errorf("context.execution: unknown execution context %q (valid: %s)", execution, strings.Join(validExecution, ", ")))
+		}
+	}
+
+	if validSensitivity := sortedKeys(scorer.SensitivityModifiers); context.DataSensitivity != "" && !contains(validSensitivity, context.DataSensitivity) {
+		errs = append(errs, fmt.Errorf("context.data_sensitivity must be one of: %s", strings.Join(validSensitivity, ", ")))
+	}
+
+	if validEnvironments := sortedKeys(scorer.EnvironmentModifiers); context.Environment != "" && !contains(validEnvironments, context.Environment) {
+		errs = append(errs, fmt.Errorf("context.environment must be one of: %s", strings.Join(validEnvironments, ", ")))
+	}
+
+	for _, regime := range context.Compliance {
+		if strings.TrimSpace(regime) == "" {
+			errs = append(errs, fmt.Errorf("context.compliance cannot contain empty entries"))
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// validateModifiers checks the industry and ecosystem multipliers
+func validateModifiers(params scorer.ModifierParams) error {
+	var errs []error
+	if params.Industry != "" {
+		if _, ok := scorer.Modifier(params.Industries, scorer.IndustryModifiers, params.Industry); !ok {
+			errs = append(errs, fmt.Errorf("scoring.modifiers.industry: unknown industry %q (valid: %s, or add it under industries)", params.Industry, strings.Join(sortedKeys(scorer.IndustryModifiers), ", ")))
+		}
+	}
+
+	tables := []struct {
+		name      string
+		modifiers map[string]float64
+	}{
+		{"industries", params.Industries},
+		{"ecosystems", params.Ecosystems},
+	}
+	for _, table := range tables {
+		for key, modifier := range table.modifiers {
+			if modifier <= 0 || modifier > 5 {
+				errs = append(errs, fmt.Errorf("scoring.modifiers.%s.%s must be greater than 0 and at most 5", table.name, key))
+			}
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// validateTiming checks the fix availability, age and SLA factors
+func validateTiming(params scorer.TimingParams) error {
+	var errs []error
+	for _, v := range []struct {
+		name  string
+		value float64
+	}{
+		{"fix_available", params.FixAvailable},
+		{"sla_boost", params.SLABoost},
+	} {
+		if v.value <= 0 || v.value > 5 {
+			errs = append(errs, fmt.Errorf("scoring.timing.%s must be greater than 0 and at most 5", v.name))
+		}
+	}
+
+	if params.AgeWeight < 0 || params.AgeWeight > 4 {
+		errs = append(errs, fmt.Errorf("scoring.timing.age_weight must be between 0 and 4"))
+	}
+
+	if params.AgeCapDays < 1 {
+		errs = append(errs, fmt.Errorf("scoring.timing.age_cap_days must be at least 1"))
+	}
+
+	validSeverities := []string{"CRITICAL", "HIGH", "MEDIUM", "LOW"}
+	for severity, days := range params.SLADays {
+		if !contains(validSeverities, strings.ToUpper(severity)) {
+			errs = append(errs, fmt.Errorf("scoring.timing.sla_days: unknown severity %q (valid: %s)", severity, strings.Join(validSeverities, ", ")))
+		}
+		if days < 0 {
+			errs = append(errs, fmt.Errorf("scoring.timing.sla_days.%s cannot be negative", severity))
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// validateAggregation checks the project score aggregation settings
+func validateAggregation(params scorer.AggregationParams) error {
+	var errs []error
+	if !contains(scorer.AggregationStrategies, params.Strategy) {
+		errs = append(errs, fmt.Errorf("scoring.aggregation.strategy must be one of: %s", strings.Join(scorer.AggregationStrategies, ", ")))
+	}
+
+	if params.TopN < 1 {
+		errs = append(errs, fmt.Errorf("scoring.aggregation.top_n must be at least 1"))
+	}
+
+	if params.Decay <= 0 || params.Decay > 1 {
+		errs = append(errs, fmt.Errorf("scoring.aggregation.decay must be greater than 0 and at most 1"))
+	}
+
+	if params.DensityScale <= 0 {
+		errs = append(errs, fmt.Errorf("scoring.aggregation.density_scale must be positive"))
+	}
+
+	validSeverities := []string{"CRITICAL", "HIGH", "MEDIUM", "LOW"}
+	for severity, weight := range params.SeverityWeights {
+		if !contains(validSeverities, severity) {
+			errs = append(errs, fmt.Errorf("scoring.aggregation.severity_weights: unknown severity %q (valid: %s)", severity, strings.Join(validSeverities, ", ")))
+		}
+		if weight < 0 {
+			errs = append(errs, fmt.Errorf("scoring.aggregation.severity_weights.%s cannot be negative", severity))
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// sortedKeys returns the keys of a modifier table in a stable order
+func sortedKeys(modifiers map[string]float64) []string {
+	keys := make([]string, 0, len(modifiers))
+	for key := range modifiers {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -1,8 +1,17 @@
 package config
 
 import (
+	"net/http"
+	"net/http/httptest"
 	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
 	"testing"
+	"time"
+
+	"github.com/dep-risk/dep-risk/internal/scanner"
+	"github.com/dep-risk/dep-risk/internal/scorer"
 )
 
 func TestDefaultConfig(t *testing.T) {
@@ -70,4 +79,1001 @@ func TestValidation(t *testing.T) {
 	if err := cfg.validate(); err == nil {
 		t.Error("Expected validation error for warn_threshold > fail_threshold")
 	}
-}
\ No newline at end of file
+}
+
+func TestLoadDependencyScoring(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "dep-risk.yml")
+	data := `dependency_scoring:
+  direct_score: 2.0
+  type_modifiers:
+    development: 0.1
+`
+	if err := os.WriteFile(path, []byte(data), 0644); err != nil {
+		t.Fatalf("Failed to write config: %v", err)
+	}
+	
+	cfg, err := LoadConfig(path)
+	if err != nil {
+		t.Fatalf("Failed to load config: %v", err)
+	}
+	
+	params := cfg.DependencyScoring
+	if params.DirectScore != 2.0 || params.DepthDecay != 1.5 {
+		t.Errorf("Expected overridden direct_score and default depth_decay, got %+v", params)
+	}
+	if params.TypeModifiers["development"] != 0.1 || params.TypeModifiers["optional"] != 0.5 {
+		t.Errorf("Expected type modifiers to merge with defaults, got %v", params.TypeModifiers)
+	}
+	
+	cfg.DependencyScoring.TypeModifiers["vendored"] = 1.0
+	if err := cfg.validate(); err == nil {
+		t.Error("Expected unknown dependency type to fail validation")
+	}
+}
+
+func TestLoadContext(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "dep-risk.yml")
+	data := `context:
+  execution: [server, library]
+  network_exposed: true
+  data_sensitivity: confidential
+  compliance: [PCI-DSS]
+  environment: production
+`
+	if err := os.WriteFile(path, []byte(data), 0644); err != nil {
+		t.Fatalf("Failed to write config: %v", err)
+	}
+	
+	cfg, err := LoadConfig(path)
+	if err != nil {
+		t.Fatalf("Failed to load config: %v", err)
+	}
+	if cfg.Context == nil || len(cfg.Context.Execution) != 2 || !cfg.Context.NetworkExposed || cfg.Context.DataSensitivity != "confidential" {
+		t.Errorf("Unexpected context: %+v", cfg.Context)
+	}
+	
+	cfg.Context.Environment = "qa"
+	if err := cfg.validate(); err == nil {
+		t.Error("Expected unknown environment to fail validation")
+	}
+	
+	cfg.Context.Environment = ""
+	cfg.Context.Execution = []string{"daemon"}
+	if err := cfg.validate(); err == nil {
+		t.Error("Expected unknown execution context to fail validation")
+	}
+}
+
+func TestLoadModifiers(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "dep-risk.yml")
+	data := `scoring:
+  modifiers:
+    industry: fintech
+    ecosystems_enabled: true
+    industries:
+      fintech: 1.6
+    ecosystems:
+      PyPI: 1.2
+`
+	if err := os.WriteFile(path, []byte(data), 0644); err != nil {
+		t.Fatalf("Failed to write config: %v", err)
+	}
+	
+	cfg, err := LoadConfig(path)
+	if err != nil {
+		t.Fatalf("Failed to load config: %v", err)
+	}
+	modifiers := cfg.Scoring.Modifiers
+	if modifiers.Industry != "fintech" || !modifiers.EcosystemsEnabled || modifiers.Ecosystems["PyPI"] != 1.2 {
+		t.Errorf("Unexpected modifiers: %+v", modifiers)
+	}
+	
+	cfg.Scoring.Modifiers.Industry = "retail"
+	if err := cfg.validate(); err == nil {
+		t.Error("Expected unknown industry to fail validation")
+	}
+	
+	cfg.Scoring.Modifiers.Industry = "finance"
+	cfg.Scoring.Modifiers.Ecosystems["npm"] = 0
+	if err := cfg.validate(); err == nil {
+		t.Error("Expected a zero multiplier to fail validation")
+	}
+}
+
+func TestLoadRules(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "dep-risk.yml")
+	data := `scoring:
+  rules:
+    - name: Development only
+      condition: "scope == 'development'"
+      multiplier: 0.3
+    - name: Broken
+      condition: "severity >= 'HIGH'"
+      offset: 1
+`
+	if err := os.WriteFile(path, []byte(data), 0644); err != nil {
+		t.Fatalf("Failed to write config: %v", err)
+	}
+	
+	_, err := LoadConfig(path)
+	if err == nil || !strings.Contains(err.Error(), "rule Broken") {
+		t.Errorf("Expected the ill-typed rule to fail config loading, got %v", err)
+	}
+}
+
+func TestLoadScoringModel(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "dep-risk.yml")
+	data := `scoring:
+  model: v1
+dependency_scoring:
+  transitive_score: 5
+`
+	if err := os.WriteFile(path, []byte(data), 0644); err != nil {
+		t.Fatalf("Failed to write config: %v", err)
+	}
+	
+	cfg, err := LoadConfig(path)
+	if err != nil {
+		t.Fatalf("Failed to load config: %v", err)
+	}
+	if cfg.Scoring.Model != "v1" || cfg.DependencyScoring.DirectScore != 2.0 {
+		t.Errorf("Expected v1 defaults, got model %s and %+v", cfg.Scoring.Model, cfg.DependencyScoring)
+	}
+	if cfg.DependencyScoring.TransitiveScore != 5 {
+		t.Errorf("Expected explicit transitive score to win, got %.1f", cfg.DependencyScoring.TransitiveScore)
+	}
+	
+	cfg.Scoring.Model = "v9"
+	if err := cfg.validate(); err == nil {
+		t.Error("Expected unknown model to fail validation")
+	}
+}
+
+func TestLoadTiming(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "dep-risk.yml")
+	data := `scoring:
+  timing:
+    enabled: true
+    sla_days:
+      CRITICAL: 7
+      HIGH: 30
+`
+	if err := os.WriteFile(path, []byte(data), 0644); err != nil {
+		t.Fatalf("Failed to write config: %v", err)
+	}
+	
+	cfg, err := LoadConfig(path)
+	if err != nil {
+		t.Fatalf("Failed to load config: %v", err)
+	}
+	timing := cfg.Scoring.Timing
+	if !timing.Enabled || timing.FixAvailable != 1.2 || timing.SLADays["HIGH"] != 30 {
+		t.Errorf("Unexpected timing settings: %+v", timing)
+	}
+	
+	cfg.Scoring.Timing.SLADays["URGENT"] = 1
+	if err := cfg.validate(); err == nil {
+		t.Error("Expected unknown SLA severity to fail validation")
+	}
+}
+
+func TestAggregationStrategy(t *testing.T) {
+	os.Setenv("INPUT_AGGREGATION_STRATEGY", "probabilistic_or")
+	defer os.Unsetenv("INPUT_AGGREGATION_STRATEGY")
+	
+	cfg := DefaultConfig()
+	cfg.loadFromEnv()
+	if cfg.Scoring.Aggregation.Strategy != "probabilistic_or" || cfg.Scoring.Aggregation.TopN != 5 {
+		t.Errorf("Unexpected aggregation: %+v", cfg.Scoring.Aggregation)
+	}
+	if err := cfg.validate(); err != nil {
+		t.Errorf("Expected valid config, got %v", err)
+	}
+	
+	cfg.Scoring.Aggregation.Strategy = "average"
+	if err := cfg.validate(); err == nil {
+		t.Error("Expected unknown strategy to fail validation")
+	}
+}
+
+func TestLoadVersionedConfig(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "dep-risk.yml")
+	data := `version: 1
+scoring:
+  model: v2
+  weights:
+    cvss: 0.4
+    popularity: 0.2
+    dependency: 0.2
+    context: 0.2
+  dependency:
+    direct_score: 2.0
+thresholds:
+  fail_score: 8
+  warn_score: 4
+ignore:
+  packages: [lodash@4.17.*]
+  cves: [CVE-2021-1234]
+  paths: [examples/**]
+notifications:
+  comment_mode: always
+scan:
+  parallel_jobs: 2
+`
+	if err := os.WriteFile(path, []byte(data), 0644); err != nil {
+		t.Fatalf("Failed to write config: %v", err)
+	}
+	
+	cfg, err := LoadConfig(path)
+	if err != nil {
+		t.Fatalf("Failed to load config: %v", err)
+	}
+	if cfg.LegacyFormat {
+		t.Error("Expected a versioned file not to be reported as legacy")
+	}
+	if cfg.FailThreshold != 8 || cfg.WarnThreshold != 4 || cfg.CVSSWeight != 0.4 || cfg.ParallelJobs != 2 {
+		t.Errorf("Unexpected settings: %+v", cfg)
+	}
+	if cfg.DependencyScoring.DirectScore != 2.0 || cfg.DependencyScoring.DepthDecay != 1.5 {
+		t.Errorf("Expected dependency settings to merge with defaults, got %+v", cfg.DependencyScoring)
+	}
+	if cfg.CommentMode != "always" || !cfg.SarifUpload {
+		t.Errorf("Unexpected notifications: %s %v", cfg.CommentMode, cfg.SarifUpload)
+	}
+	if !contains(cfg.IgnoreList, "CVE-2021-1234") || len(cfg.IgnorePackages) != 1 || len(cfg.IgnorePaths) != 1 {
+		t.Errorf("Unexpected ignore settings: %v %v %v", cfg.IgnoreList, cfg.IgnorePackages, cfg.IgnorePaths)
+	}
+	if unjustified := cfg.UnjustifiedIgnores(); len(unjustified) != len(cfg.IgnoreList)+2 {
+		t.Errorf("Expected every legacy ignore to be listed for auditing, got %v", unjustified)
+	}
+}
+
+func TestLoadFlatConfigUpgrades(t *testing.T) {
+	dir := t.TempDir()
+	flat := `fail_threshold: 8
+ignore_list: [CVE-2021-1234]
+cvss_weight: 0.4
+popularity_weight: 0.2
+dependency_weight: 0.2
+context_weight: 0.2
+scoring:
+  timing:
+    enabled: true
+dependency_scoring:
+  direct_score: 2.0
+eol_warning_days: 30
+`
+	versioned := `version: 1
+scoring:
+  weights: {cvss: 0.4, popularity: 0.2, dependency: 0.2, context: 0.2}
+  timing:
+    enabled: true
+  dependency:
+    direct_score: 2.0
+thresholds:
+  fail_score: 8
+ignore:
+  cves: [CVE-2021-1234]
+eol:
+  warning_days: 30
+`
+	load := func(name, data string) *Config {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, []byte(data), 0644); err != nil {
+			t.Fatalf("Failed to write config: %v", err)
+		}
+		cfg, err := LoadConfig(path)
+		if err != nil {
+			t.Fatalf("Failed to load %s: %v", name, err)
+		}
+		return cfg
+	}
+	
+	legacy, current := load("flat.yml", flat), load("v1.yml", versioned)
+	if !legacy.LegacyFormat {
+		t.Error("Expected the flat file to be reported as legacy")
+	}
+	// Settings match; keys sit on different lines
+	legacy.LegacyFormat, legacy.lines, current.lines = false, nil, nil
+	if !reflect.DeepEqual(legacy, current) {
+		t.Errorf("Expected the flat file to load like its versioned form:\n%+v\n%+v", legacy, current)
+	}
+}
+
+func TestUnknownKeys(t *testing.T) {
+	tests := []struct {
+		name   string
+		data   string
+		errMsg string
+	}{
+		{"versioned", "version: 1\nthresholds:\n  fail_score: 8\n  fail_scroe: 9\n", "line 4: unknown key thresholds.fail_scroe"},
+		{"nested", "version: 1\nscoring:\n  rules:\n    - name: a\n      when: x\n", "line 5: unknown key scoring.rules[0].when"},
+		{"flat", "fail_threshold: 8\nfail_treshold: 9\n", "line 2: unknown key fail_treshold"},
+		{"flat nested", "dependency_scoring:\n  direct: 2\n", "line 2: unknown key scoring.dependency.direct"},
+		{"section without version", "thresholds:\n  fail_score: 8\n", "add `version: 1`"},
+		{"unsupported version", "version: 2\n", "unsupported config version"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := DefaultConfig().load([]byte(tt.data), ".")
+			if err == nil || !strings.Contains(err.Error(), tt.errMsg) {
+				t.Errorf("Expected error containing %q, got %v", tt.errMsg, err)
+			}
+		})
+	}
+}
+
+func TestDesignDocumentConfig(t *testing.T) {
+	// The version 1 example of the design document
+	doc := `# Dep-Risk Configuration
+version: 1
+
+scoring:
+  cvss_weight: 0.7
+  popularity_weight: 0.2
+  depth_weight: 0.1
+
+thresholds:
+  fail_score: 7.0
+  warn_score: 5.0
+
+ignore:
+  packages:
+    - "lodash@4.17.20"
+    - "express@*"
+  cves:
+    - "CVE-2021-44228"
+  paths:
+    - "test/**"
+    - "docs/**"
+
+notifications:
+  slack:
+    webhook_url: ${{ secrets.SLACK_WEBHOOK }}
+    channel: "#security-alerts"
+  email:
+    recipients:
+      - "security@company.com"
+`
+	cfg := DefaultConfig()
+	if err := cfg.load([]byte(doc), "."); err != nil {
+		t.Fatalf("Expected the design document example to load, got %v", err)
+	}
+	if err := cfg.validate(); err != nil {
+		t.Fatalf("Expected the design document example to be valid, got %v", err)
+	}
+	
+	if cfg.CVSSWeight != 0.7 || cfg.PopularityWeight != 0.2 || cfg.DependencyWeight != 0.1 || cfg.ContextWeight != 0 || cfg.MaintenanceWeight != 0 {
+		t.Errorf("Expected weights 0.7/0.2/0.1/0/0, got %.1f/%.1f/%.1f/%.1f/%.1f",
+			cfg.CVSSWeight, cfg.PopularityWeight, cfg.DependencyWeight, cfg.ContextWeight, cfg.MaintenanceWeight)
+	}
+	if cfg.SlackNotification == nil || cfg.SlackNotification.Channel != "#security-alerts" || cfg.EmailNotification == nil {
+		t.Errorf("Expected the notification settings to be read, got %+v %+v", cfg.SlackNotification, cfg.EmailNotification)
+	}
+	
+	combined := "version: 1\nscoring:\n  cvss_weight: 0.7\n  weights:\n    cvss: 0.5\n"
+	if err := DefaultConfig().load([]byte(combined), "."); err == nil || !strings.Contains(err.Error(), "cannot be combined") {
+		t.Errorf("Expected weights and cvss_weight to conflict, got %v", err)
+	}
+}
+
+func TestMigrate(t *testing.T) {
+	flat := `# Risk settings for the payments service
+
+fail_threshold: 8.0 # stricter than the default
+ignore_list:
+  - CVE-2021-1234
+cvss_weight: 0.4
+popularity_weight: 0.2
+dependency_weight: 0.2
+context_weight: 0.2
+scoring:
+  model: v1
+comment_mode: always
+`
+	migrated, changed, err := Migrate([]byte(flat))
+	if err != nil {
+		t.Fatalf("Migrate failed: %v", err)
+	}
+	if !changed {
+		t.Fatal("Expected the flat file to be migrated")
+	}
+	for _, want := range []string{"# Risk settings", "version: 1\n", "  fail_score: 8.0 # stricter than the default\n", "  weights:\n    cvss: 0.4\n", "  cves:\n    - CVE-2021-1234\n", "  model: v1\n"} {
+		if !strings.Contains(string(migrated), want) {
+			t.Errorf("Expected migrated file to contain %q:\n%s", want, migrated)
+		}
+	}
+	
+	before, after := DefaultConfig(), DefaultConfig()
+	if err := before.load([]byte(flat), "."); err != nil {
+		t.Fatal(err)
+	}
+	if err := after.load(migrated, "."); err != nil {
+		t.Fatal(err)
+	}
+	before.LegacyFormat, before.lines, after.lines = false, nil, nil
+	if !reflect.DeepEqual(before, after) {
+		t.Errorf("Expected the migrated file to load like the original")
+	}
+	
+	if again, changed, err := Migrate(migrated); err != nil || changed || string(again) != string(migrated) {
+		t.Errorf("Expected a versioned file to be left alone, got changed=%v err=%v", changed, err)
+	}
+}
+
+func TestCheckIgnoreLists(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.IgnoreList = []string{"CVE-1"}
+	cfg.IgnorePackages = []string{"lodash@4.17.*", "@babel/core", "minimist@1.2.5"}
+	cfg.IgnorePaths = []string{"examples/**", "**/testdata", "tools/*.json"}
+	
+	tests := []struct {
+		vuln scanner.Vulnerability
+		want bool
+	}{
+		{scanner.Vulnerability{ID: "CVE-1"}, true},
+		{scanner.Vulnerability{ID: "CVE-2", Package: "lodash", Version: "4.17.20"}, true},
+		{scanner.Vulnerability{ID: "CVE-2", Package: "lodash", Version: "4.18.0"}, false},
+		{scanner.Vulnerability{ID: "CVE-2", Package: "@babel/core", Version: "7.0.0"}, true},
+		{scanner.Vulnerability{ID: "CVE-2", Package: "minimist", Version: "1.2.6"}, false},
+		{scanner.Vulnerability{ID: "CVE-2", Source: "examples/web/package-lock.json"}, true},
+		{scanner.Vulnerability{ID: "CVE-2", File: "internal/testdata/go.mod"}, true},
+		{scanner.Vulnerability{ID: "CVE-2", Source: "tools/a/package.json"}, false},
+		{scanner.Vulnerability{ID: "CVE-2", Source: "tools/package.json"}, true},
+		{scanner.Vulnerability{ID: "CVE-2", Source: "package-lock.json"}, false},
+	}
+	for _, tt := range tests {
+		if got, _ := cfg.CheckIgnore(tt.vuln, time.Now()); got != tt.want {
+			t.Errorf("CheckIgnore(%+v) = %v, want %v", tt.vuln, got, tt.want)
+		}
+	}
+}
+
+func TestIgnoreRules(t *testing.T) {
+	data := `version: 1
+ignore:
+  rules:
+    - id: CVE-2021-23337
+      reason: Template compilation is not reachable
+      owner: "@acme/platform"
+      expires: 2026-01-31
+    - package: express
+      version: ">=4.0.0, <4.19.2"
+      reason: Only used by the local dev server
+    - package: jest
+      paths: ["test/**", "**/__tests__"]
+      reason: Test tooling
+`
+	cfg := DefaultConfig()
+	if err := cfg.load([]byte(data), "."); err != nil {
+		t.Fatalf("Failed to load config: %v", err)
+	}
+	if err := cfg.validate(); err != nil {
+		t.Fatalf("Expected valid rules, got %v", err)
+	}
+	if len(cfg.IgnoreRules) != 3 || cfg.IgnoreRules[0].Expires != "2026-01-31" {
+		t.Fatalf("Unexpected rules: %+v", cfg.IgnoreRules)
+	}
+	
+	before := time.Date(2026, 1, 30, 23, 0, 0, 0, time.UTC)
+	onDate := time.Date(2026, 1, 31, 23, 59, 0, 0, time.UTC)
+	after := time.Date(2026, 2, 1, 0, 0, 0, 0, time.UTC)
+	lodash := scanner.Vulnerability{ID: "GHSA-35jh-r3h4-6jhm", Aliases: []string{"CVE-2021-23337"}, Package: "lodash", Version: "4.17.20"}
+	if ignored, _ := cfg.CheckIgnore(lodash, before); !ignored {
+		t.Error("Expected the rule to ignore the finding by alias before it expires")
+	}
+	if ignored, _ := cfg.CheckIgnore(lodash, onDate); !ignored {
+		t.Error("Expected the rule to still apply on its expiry date")
+	}
+	ignored, expired := cfg.CheckIgnore(lodash, after)
+	if ignored || expired == nil || expired.Owner != "@acme/platform" {
+		t.Errorf("Expected the expired rule to be returned, got ignored=%v rule=%+v", ignored, expired)
+	}
+	
+	tests := []struct {
+		vuln scanner.Vulnerability
+		want bool
+	}{
+		{scanner.Vulnerability{ID: "X", Package: "express", Version: "4.18.2"}, true},
+		{scanner.Vulnerability{ID: "X", Package: "express", Version: "4.19.2"}, false},
+		{scanner.Vulnerability{ID: "X", Package: "express", Version: "3.21.0"}, false},
+		{scanner.Vulnerability{ID: "X", Package: "jest", Version: "29.0.0", Source: "test/e2e/package-lock.json"}, true},
+		{scanner.Vulnerability{ID: "X", Package: "jest", Version: "29.0.0", Source: "web/src/__tests__/package.json"}, true},
+		{scanner.Vulnerability{ID: "X", Package: "jest", Version: "29.0.0", Source: "package-lock.json"}, false},
+	}
+	for _, tt := range tests {
+		if got, _ := cfg.CheckIgnore(tt.vuln, before); got != tt.want {
+			t.Errorf("CheckIgnore(%s@%s in %q) = %v, want %v", tt.vuln.Package, tt.vuln.Version, tt.vuln.Source, got, tt.want)
+		}
+	}
+	
+	for _, rule := range []IgnoreRule{
+		{ID: "CVE-1"},
+		{Reason: "no selector"},
+		{Version: "1.0.0", Reason: "version without package"},
+		{ID: "CVE-1", Reason: "bad date", Expires: "31/01/2026"},
+		{Package: "express", Version: ">=", Reason: "bad constraint"},
+	} {
+		cfg.IgnoreRules = []IgnoreRule{rule}
+		if err := cfg.validate(); err == nil {
+			t.Errorf("Expected %+v to fail validation", rule)
+		}
+	}
+}
+
+func TestMatchVersion(t *testing.T) {
+	tests := []struct {
+		constraint string
+		version    string
+		want       bool
+	}{
+		{"", "1.0.0", true},
+		{"*", "1.0.0", true},
+		{"4.17.*", "4.17.20", true},
+		{"4.17.*", "4.18.0", false},
+		{"1.2.5", "1.2.5", true},
+		{"< 1.10", "1.9.3", true},
+		{">=1.2.0 <2", "2.0.0", false},
+		{"<1.0.0", "1.0.0-rc.1", true},
+		{"=v1.4.1", "1.4.1", true},
+		{"<1.0 || >=2.0", "2.1", true},
+		{"<1.0 || >=2.0", "1.5", false},
+	}
+	for _, tt := range tests {
+		if got := matchVersion(tt.constraint, tt.version); got != tt.want {
+			t.Errorf("matchVersion(%q, %q) = %v, want %v", tt.constraint, tt.version, got, tt.want)
+		}
+	}
+}
+
+func TestExtendsMerge(t *testing.T) {
+	dir := t.TempDir()
+	base := `version: 1
+scoring:
+  weights:
+    cvss: 0.5
+    popularity: 0.2
+    dependency: 0.1
+    context: 0.1
+    maintenance: 0.1
+    hygiene: 0.8
+thresholds:
+  fail_score: 6.0
+  warn_score: 4.0
+ignore:
+  cves: [CVE-2021-1]
+scan:
+  languages: [go, javascript]
+`
+	repo := `version: 1
+extends: base.yml
+scoring:
+  weights:
+    cvss: 0.6
+    popularity: 0.4
+thresholds:
+  warn_score: 3.0
+ignore:
+  cves: [CVE-2021-2, CVE-2021-1]
+scan:
+  languages: [go]
+`
+	if err := os.WriteFile(filepath.Join(dir, "base.yml"), []byte(base), 0644); err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(dir, "dep-risk.yml")
+	if err := os.WriteFile(path, []byte(repo), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg, err := LoadConfig(path)
+	if err != nil {
+		t.Fatalf("LoadConfig failed: %v", err)
+	}
+	
+	if cfg.FailThreshold != 6.0 || cfg.WarnThreshold != 3.0 {
+		t.Errorf("Expected thresholds 6.0/3.0, got %.1f/%.1f", cfg.FailThreshold, cfg.WarnThreshold)
+	}
+	// Weights replace the base weights as a set
+	if cfg.CVSSWeight != 0.6 || cfg.PopularityWeight != 0.4 || cfg.DependencyWeight != 0 {
+		t.Errorf("Expected the weights of the repo config, got %.1f/%.1f/%.1f", cfg.CVSSWeight, cfg.PopularityWeight, cfg.DependencyWeight)
+	}
+	// The hygiene weight is not part of the set and is inherited
+	if cfg.HygieneWeight != 0.8 {
+		t.Errorf("Expected the base hygiene weight 0.8, got %.1f", cfg.HygieneWeight)
+	}
+	if !reflect.DeepEqual(cfg.IgnoreList, []string{"CVE-2021-1", "CVE-2021-2"}) {
+		t.Errorf("Expected the union of the ignore lists, got %v", cfg.IgnoreList)
+	}
+	if !reflect.DeepEqual(cfg.Languages, []string{"go"}) {
+		t.Errorf("Expected languages to be replaced, got %v", cfg.Languages)
+	}
+	if cfg.Extends != filepath.Join(dir, "base.yml") {
+		t.Errorf("Expected Extends to name the base config, got %q", cfg.Extends)
+	}
+	
+	effective, err := cfg.Effective()
+	if err != nil {
+		t.Fatalf("Effective failed: %v", err)
+	}
+	if !strings.Contains(string(effective), "fail_score: 6") {
+		t.Errorf("Expected the effective config to include the base threshold, got:\n%s", effective)
+	}
+}
+
+func TestExtendsLocked(t *testing.T) {
+	dir := t.TempDir()
+	base := `version: 1
+locked: [thresholds.fail_score, ignore.cves, workflows.require_sha]
+thresholds:
+  fail_score: 6.0
+  warn_score: 4.0
+ignore:
+  cves: [CVE-2021-1]
+workflows:
+  require_sha: true
+`
+	if err := os.WriteFile(filepath.Join(dir, "base.yml"), []byte(base), 0644); err != nil {
+		t.Fatal(err)
+	}
+	
+	tests := []struct {
+		name    string
+		config  string
+		env     string
+		wantErr string
+	}{
+		{"stricter", "thresholds:\n  fail_score: 5.0\n", "", ""},
+		{"unlocked key", "thresholds:\n  warn_score: 5.0\n", "", ""},
+		{"raised threshold", "thresholds:\n  fail_score: 8.0\n", "", "thresholds.fail_score is locked"},
+		{"raised by input", "", "9", "thresholds.fail_score is locked"},
+		{"added ignore", "ignore:\n  cves: [CVE-2021-2]\n", "", "ignore.cves is locked"},
+		{"disabled check", "workflows:\n  require_sha: false\n", "", "workflows.require_sha is locked"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(dir, "dep-risk.yml")
+			if err := os.WriteFile(path, []byte("version: 1\nextends: base.yml\n"+tt.config), 0644); err != nil {
+				t.Fatal(err)
+			}
+			t.Setenv("INPUT_FAIL_THRESHOLD", tt.env)
+			
+			_, err := LoadConfig(path)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("Expected config to load, got %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Expected error containing %q, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestExtendsErrors(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"a.yml": "version: 1\nextends: b.yml\n",
+		"b.yml": "version: 1\nextends: a.yml\n",
+		"c.yml": "version: 1\nlocked: [thresholds.fail]\n",
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	
+	tests := map[string]string{
+		"version: 1\nextends: a.yml\n":       "cycle",
+		"version: 1\nextends: missing.yml\n": "extends missing.yml",
+		"version: 1\nextends: c.yml\n":       "unknown key thresholds.fail",
+	}
+	for config, wantErr := range tests {
+		err := DefaultConfig().load([]byte(config), dir)
+		if err == nil || !strings.Contains(err.Error(), wantErr) {
+			t.Errorf("Expected error containing %q for %q, got %v", wantErr, config, err)
+		}
+	}
+}
+
+func TestExtendsAPI(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/v1/orgs/acme/policies/baseline" || r.Header.Get("X-API-Key") != "secret" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.Write([]byte("version: 1\nthresholds:\n  fail_score: 5.5\n"))
+	}))
+	defer server.Close()
+	t.Setenv("DEP_RISK_API_ENDPOINT", server.URL)
+	t.Setenv("DEP_RISK_API_TOKEN", "secret")
+	t.Setenv("GITHUB_REPOSITORY_OWNER", "acme")
+	
+	cfg := DefaultConfig()
+	if err := cfg.load([]byte("version: 1\nextends: api:baseline\n"), "."); err != nil {
+		t.Fatalf("load failed: %v", err)
+	}
+	if cfg.FailThreshold != 5.5 {
+		t.Errorf("Expected FailThreshold 5.5 from the policy, got %.1f", cfg.FailThreshold)
+	}
+	if cfg.Extends != "api:acme/baseline" {
+		t.Errorf("Expected Extends api:acme/baseline, got %q", cfg.Extends)
+	}
+	
+	if err := DefaultConfig().load([]byte("version: 1\nextends: api:other/baseline\n"), "."); err == nil {
+		t.Error("Expected an unknown policy to fail")
+	}
+}
+
+func TestValidatePolicy(t *testing.T) {
+	if err := ValidatePolicy([]byte("version: 1\nlocked: [thresholds]\nthresholds:\n  fail_score: 6.0\n")); err != nil {
+		t.Errorf("Expected policy to validate, got %v", err)
+	}
+	for _, policy := range []string{
+		"version: 1\nextends: other.yml\n",
+		"version: 1\nthresholds:\n  fail_score: 12\n",
+		"version: 1\nlocked: [nope]\n",
+	} {
+		if err := ValidatePolicy([]byte(policy)); err == nil {
+			t.Errorf("Expected %q to be rejected", policy)
+		}
+	}
+}
+
+func TestValidateReportsEveryProblem(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "dep-risk.yml")
+	data := `version: 1
+thresholds:
+  fail_score: 12
+  warn_scroe: 2
+scan:
+  timeout: soon
+notifications:
+  comment_mode: sometimes
+ignore:
+  rules:
+    - id: CVE-2021-1
+`
+	if err := os.WriteFile(path, []byte(data), 0644); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("INPUT_CVSS_WEIGHT", "abc")
+	
+	problems, err := Validate(path)
+	if err != nil {
+		t.Fatalf("Validate failed: %v", err)
+	}
+	want := []struct {
+		line    int
+		message string
+	}{
+		{3, "thresholds.fail_score must be between 0 and 10"},
+		{4, "unknown key thresholds.warn_scroe"},
+		{6, "cannot unmarshal !!str `soon`"},
+		{8, "notifications.comment_mode must be one of"},
+		{11, "ignore.rules[0]: reason is required"},
+		{0, `input cvss_weight must be a number, got "abc"`},
+	}
+	if len(problems) != len(want) {
+		t.Fatalf("Expected %d problems, got %d: %v", len(want), len(problems), problems)
+	}
+	for i, w := range want {
+		if problems[i].Line != w.line || !strings.Contains(problems[i].Message, w.message) {
+			t.Errorf("Expected problem %d at line %d containing %q, got %v", i, w.line, w.message, problems[i])
+		}
+	}
+}
+
+func TestInvalidInputs(t *testing.T) {
+	t.Setenv("INPUT_TIMEOUT", "5m")
+	t.Setenv("INPUT_SARIF_UPLOAD", "yes")
+	
+	_, err := LoadConfig("")
+	if err == nil {
+		t.Fatal("Expected invalid inputs to fail")
+	}
+	for _, want := range []string{"input timeout must be a whole number", "input sarif_upload must be true or false"} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("Expected error containing %q, got %v", want, err)
+		}
+	}
+}
+
+func TestJSONSchemaPublished(t *testing.T) {
+	schema, err := JSONSchema()
+	if err != nil {
+		t.Fatalf("JSONSchema failed: %v", err)
+	}
+	published, err := os.ReadFile(filepath.Join("..", "..", "schema", "dep-risk.schema.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(schema) != string(published) {
+		t.Error("schema/dep-risk.schema.json is out of date; regenerate it with `dep-risk config schema > schema/dep-risk.schema.json`")
+	}
+}
+
+func TestOverrides(t *testing.T) {
+	data := `version: 1
+thresholds:
+  fail_score: 7.0
+  warn_score: 3.0
+overrides:
+  - name: release
+    when:
+      branches: [main, "release/*"]
+      events: [push, pull_request]
+    thresholds:
+      fail_score: 5.0
+  - name: nightly
+    when:
+      events: [schedule]
+    thresholds:
+      fail_score: 4.0
+      warn_score: 2.0
+  - name: examples
+    when:
+      paths: ["examples/**"]
+    thresholds:
+      fail_score: 9.0
+`
+	tests := []struct {
+		run     RunContext
+		applied []string
+		fail    float64
+		warn    float64
+	}{
+		{RunContext{Branch: "main", Event: "pull_request"}, []string{"release"}, 5.0, 3.0},
+		{RunContext{Branch: "release/1.2", Event: "push"}, []string{"release"}, 5.0, 3.0},
+		{RunContext{Branch: "release/1.2/hotfix", Event: "push"}, nil, 7.0, 3.0},
+		{RunContext{Branch: "feature/x", Event: "pull_request"}, nil, 7.0, 3.0},
+		{RunContext{Branch: "main", Event: "schedule"}, []string{"nightly"}, 4.0, 2.0},
+	}
+	for _, tt := range tests {
+		cfg := DefaultConfig()
+		if err := cfg.load([]byte(data), "."); err != nil {
+			t.Fatalf("load failed: %v", err)
+		}
+		if err := cfg.validate(); err != nil {
+			t.Fatalf("validate failed: %v", err)
+		}
+		
+		applied := cfg.ApplyOverrides(tt.run)
+		if !reflect.DeepEqual(applied, tt.applied) || cfg.FailThreshold != tt.fail || cfg.WarnThreshold != tt.warn {
+			t.Errorf("%+v: expected %v with %.1f/%.1f, got %v with %.1f/%.1f", tt.run, tt.applied, tt.fail, tt.warn, applied, cfg.FailThreshold, cfg.WarnThreshold)
+		}
+		
+		scope := cfg.ScopeOf(tt.run, scanner.Vulnerability{File: "examples/demo/package-lock.json"})
+		if scope == nil || scope.Name != "examples" {
+			t.Errorf("%+v: expected examples findings in the examples scope, got %v", tt.run, scope)
+		}
+		if scope := cfg.ScopeOf(tt.run, scanner.Vulnerability{File: "go.sum"}); scope != nil {
+			t.Errorf("%+v: expected other findings in the default scope, got %v", tt.run, scope.Name)
+		}
+	}
+}
+
+func TestOverrideValidation(t *testing.T) {
+	fail := func(score float64) *float64 { return &score }
+	tests := []struct {
+		override Override
+		errMsg   string
+	}{
+		{Override{When: OverrideWhen{Events: []string{"push"}}, Thresholds: OverrideThresholds{FailScore: fail(5)}}, "name is required"},
+		{Override{Name: "always", Thresholds: OverrideThresholds{FailScore: fail(5)}}, "when needs at least one"},
+		{Override{Name: "empty", When: OverrideWhen{Events: []string{"push"}}}, "sets no thresholds"},
+		{Override{Name: "high", When: OverrideWhen{Events: []string{"push"}}, Thresholds: OverrideThresholds{FailScore: fail(11)}}, "must be between 0 and 10"},
+		{Override{Name: "below warn", When: OverrideWhen{Events: []string{"push"}}, Thresholds: OverrideThresholds{FailScore: fail(2)}}, "cannot be greater than fail_score"},
+	}
+	for _, tt := range tests {
+		cfg := DefaultConfig()
+		cfg.Overrides = []Override{tt.override}
+		if err := cfg.validate(); err == nil || !strings.Contains(err.Error(), tt.errMsg) {
+			t.Errorf("Expected error containing %q for %+v, got %v", tt.errMsg, tt.override, err)
+		}
+	}
+	
+	// Override blocks cannot loosen a locked threshold
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "base.yml"), []byte("version: 1\nlocked: [thresholds.fail_score]\nthresholds:\n  fail_score: 6.0\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	cfg := DefaultConfig()
+	data := "version: 1\nextends: base.yml\noverrides:\n  - name: loose\n    when:\n      branches: [\"feature/*\"]\n    thresholds:\n      fail_score: 9.0\n"
+	if err := cfg.load([]byte(data), dir); err != nil {
+		t.Fatal(err)
+	}
+	if err := cfg.validate(); err == nil || !strings.Contains(err.Error(), "overrides[0]: thresholds.fail_score is locked") {
+		t.Errorf("Expected the override to violate the lock, got %v", err)
+	}
+}
+
+func TestGates(t *testing.T) {
+	dir := t.TempDir()
+	base := "version: 1\nlocked: [gates, kev.enabled]\nkev:\n  enabled: true\ngates:\n  - name: known-exploited\n    condition: kev\n"
+	if err := os.WriteFile(filepath.Join(dir, "base.yml"), []byte(base), 0644); err != nil {
+		t.Fatal(err)
+	}
+	
+	cfg := DefaultConfig()
+	data := "version: 1\nextends: base.yml\ngates:\n  - name: too-many-highs\n    condition: \"severity == 'HIGH'\"\n    max_count: 5\n    action: warn\n"
+	if err := cfg.load([]byte(data), dir); err != nil {
+		t.Fatal(err)
+	}
+	if err := cfg.validate(); err != nil {
+		t.Fatalf("Expected a valid config, got %v", err)
+	}
+	expected := []scorer.GateSpec{
+		{Name: "known-exploited", Condition: "kev"},
+		{Name: "too-many-highs", Condition: "severity == 'HIGH'", MaxCount: 5, Action: "warn"},
+	}
+	if !cfg.KEVEnabled || !reflect.DeepEqual(cfg.Gates, expected) {
+		t.Errorf("Expected the base gate and KEV lookup to be kept, got %v %+v", cfg.KEVEnabled, cfg.Gates)
+	}
+	
+	// The locked KEV lookup cannot be turned off
+	cfg = DefaultConfig()
+	if err := cfg.load([]byte("version: 1\nextends: base.yml\nkev:\n  enabled: false\n"), dir); err != nil {
+		t.Fatal(err)
+	}
+	if err := cfg.validate(); err == nil || !strings.Contains(err.Error(), "kev.enabled is locked") {
+		t.Errorf("Expected kev.enabled to be locked, got %v", err)
+	}
+	
+	// Gates and rules reading kev need the lookup
+	cfg = DefaultConfig()
+	multiplier := 2.0
+	cfg.Gates = []scorer.GateSpec{{Name: "known-exploited", Condition: "kev && is_direct"}, {Name: "bad", Condition: "kev >= 1"}}
+	cfg.Scoring.Rules = []scorer.RuleSpec{{Name: "exploited", Condition: "kev", Multiplier: &multiplier}}
+	err := cfg.validate()
+	if err == nil || !strings.Contains(err.Error(), "gates: gate bad: invalid condition") ||
+		!strings.Contains(err.Error(), "scoring.rules[0]: rule exploited reads kev, which requires kev.enabled") {
+		t.Errorf("Expected gate and rule errors, got %v", err)
+	}
+	cfg.Gates = cfg.Gates[:1]
+	if err := cfg.validate(); err == nil || !strings.Contains(err.Error(), "gates[0]: gate known-exploited reads kev, which requires kev.enabled") {
+		t.Errorf("Expected the gate to require kev.enabled, got %v", err)
+	}
+	
+	t.Setenv("INPUT_KEV_ENABLED", "true")
+	t.Setenv("INPUT_KEV_CATALOG", "kev.json")
+	if err := cfg.loadFromEnv(); err != nil {
+		t.Fatal(err)
+	}
+	if err := cfg.validate(); err != nil || cfg.KEVCatalog != "kev.json" {
+		t.Errorf("Expected the kev inputs to enable the lookup, got %v", err)
+	}
+}
+
+func TestPolicyFiles(t *testing.T) {
+	dir := t.TempDir()
+	base := "version: 1\nlocked: [policies.files]\npolicies:\n  files: [.github/org-policy.yml]\n"
+	if err := os.WriteFile(filepath.Join(dir, "base.yml"), []byte(base), 0644); err != nil {
+		t.Fatal(err)
+	}
+	
+	cfg := DefaultConfig()
+	if err := cfg.load([]byte("version: 1\nextends: base.yml\npolicies:\n  files: [.github/policy.yml]\n"), dir); err != nil {
+		t.Fatal(err)
+	}
+	if err := cfg.validate(); err != nil {
+		t.Fatalf("Expected a valid config, got %v", err)
+	}
+	expected := []string{".github/org-policy.yml", ".github/policy.yml"}
+	if !reflect.DeepEqual(cfg.PolicyFiles, expected) {
+		t.Errorf("Expected %v, got %v", expected, cfg.PolicyFiles)
+	}
+	
+	// The input replaces the list, so it cannot drop a locked policy
+	t.Setenv("INPUT_POLICY_FILES", ".github/policy.yml, ")
+	if err := cfg.loadFromEnv(); err != nil {
+		t.Fatal(err)
+	}
+	err := cfg.validate()
+	if err == nil || !strings.Contains(err.Error(), "policies.files is locked") || !strings.Contains(err.Error(), "policies.files[1] cannot be empty") {
+		t.Errorf("Expected lock and empty entry errors, got %v", err)
+	}
+}
+
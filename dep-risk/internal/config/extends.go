@@ -0,0 +1,496 @@
+package config
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// apiPrefix marks an `extends` reference to a policy served by the dep-risk API
+const apiPrefix = "api:"
+
+// maxExtendsDepth bounds chains of base configs
+const maxExtendsDepth = 5
+
+// mergeStrategies are the merge rules of keys that are not deep-merged.
+// Ignore lists, override blocks, gates and policy files accumulate entries; the weights are replaced as a set, with
+// the weights a config omits set to 0, so they keep summing to 1. Fields in
+// keptFields are outside the set and merge like other keys.
+var mergeStrategies = map[string]string{
+	"ignore.cves":     "append",
+	"ignore.packages": "append",
+	"ignore.paths":    "append",
+	"ignore.rules":    "append",
+	"overrides":       "append",
+	"gates":           "append",
+	"policies.files":  "append",
+	"scoring.weights": "replace",
+}
+
+// keptFields are the fields of replaced sections that a config omitting them
+// inherits from the base. The hygiene weight scales hygiene findings and is
+// not part of the weights that sum to 1.
+var keptFields = map[string][]string{
+	"scoring.weights": {"hygiene"},
+}
+
+// lockRules decide whether a value of a locked key is at least as strict as
+// the base value. Keys without a rule must keep the base value.
+var lockRules = map[string]func(base, value reflect.Value) bool{
+	"thresholds.fail_score":      func(base, value reflect.Value) bool { return value.Float() <= base.Float() },
+	"thresholds.warn_score":      func(base, value reflect.Value) bool { return value.Float() <= base.Float() },
+	"eol.warning_days":           func(base, value reflect.Value) bool { return value.Int() >= base.Int() },
+	"hygiene.enabled":            keepsEnabled,
+	"eol.enabled":                keepsEnabled,
+	"workflows.enabled":          keepsEnabled,
+	"workflows.require_sha":      keepsEnabled,
+	"notifications.sarif_upload": keepsEnabled,
+	"workflows.allowed_owners":   func(base, value reflect.Value) bool { return base.Len() == 0 || isSubset(base, value) },
+	"ignore.cves":                isSubset,
+	"ignore.packages":            isSubset,
+	"ignore.paths":               isSubset,
+	"ignore.rules":               isSubset,
+	"gates":                      func(base, value reflect.Value) bool { return isSubset(value, base) },
+	"kev.enabled":                keepsEnabled,
+	"policies.files":             func(base, value reflect.Value) bool { return isSubset(value, base) },
+}
+
+// resolved is a config file merged over the files it extends
+type resolved struct {
+	root *yaml.Node
+	// base is the resolved file extended, if any
+	base *resolved
+	// locked are the keys locked by the file and the files it extends
+	locked []string
+	// sources are the files the file extends, nearest first
+	sources []string
+}
+
+// resolveExtends merges a parsed version 1 file over the files it extends.
+// Relative paths are resolved against dir.
+func resolveExtends(root *yaml.Node, dir string, depth int, seen map[string]bool) (*resolved, error) {
+	result := &resolved{root: root}
+	if locked := mappingValue(root, "locked"); locked != nil {
+		if err := locked.Decode(&result.locked); err != nil {
+			return nil, fmt.Errorf("line %d: locked: %w", locked.Line, err)
+		}
+		for _, key := range result.locked {
+			if _, ok := fieldType(reflect.TypeOf(FileV1{}), key); !ok {
+				return nil, fmt.Errorf("line %d: locked: unknown key %s", locked.Line, key)
+			}
+		}
+	}
+
+	extends := mappingValue(root, "extends")
+	if extends == nil || extends.Value == "" {
+		return result, nil
+	}
+	if depth >= maxExtendsDepth {
+		return nil, fmt.Errorf("extends: more than %d levels of base configs", maxExtendsDepth)
+	}
+
+	reference := extends.Value
+	data, source, baseDir, err := fetchBase(reference, dir)
+	if err != nil {
+		return nil, fmt.Errorf("extends %s: %w", reference, err)
+	}
+	if seen[source] {
+		return nil, fmt.Errorf("extends %s: cycle through %s", reference, source)
+	}
+	seen[source] = true
+
+	baseRoot, _, err := parseVersioned(data)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", source, err)
+	}
+	if unknown := unknownKeys(baseRoot, reflect.TypeOf(FileV1{}), ""); len(unknown) > 0 {
+		return nil, fmt.Errorf("%s: %w", source, unknown)
+	}
+	base, err := resolveExtends(baseRoot, baseDir, depth+1, seen)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", source, err)
+	}
+
+	result.base = base
+	result.root = mergeNodes(base.root, root, "")
+	result.locked = append(base.locked, result.locked...)
+	result.sources = append([]string{source}, base.sources...)
+	return result, nil
+}
+
+// parseVersioned parses a config file in the current schema. Flat files are
+// migrated first and reported as legacy.
+func parseVersioned(data []byte) (*yaml.Node, bool, error) {
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return nil, false, err
+	}
+	root := documentRoot(&doc)
+	if root == nil {
+		return &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map"}, false, nil
+	}
+
+	legacy := false
+	if version := mappingValue(root, "version"); version == nil {
+		migrated, err := migrateRoot(root)
+		if err != nil {
+			return nil, false, err
+		}
+		root, legacy = migrated, true
+	} else if version.Value != fmt.Sprint(CurrentVersion) {
+		return nil, false, fmt.Errorf("line %d: unsupported config version %q (supported: %d)", version.Line, version.Value, CurrentVersion)
+	} else if err := expandWeightKeys(root); err != nil {
+		return nil, false, err
+	}
+	return root, legacy, nil
+}
+
+// fetchBase reads a base config, returning its contents, a name identifying
+// it and the directory its own relative references resolve against
+func fetchBase(reference, dir string) ([]byte, string, string, error) {
+	if strings.HasPrefix(reference, apiPrefix) {
+		data, source, err := fetchPolicy(strings.TrimPrefix(reference, apiPrefix))
+		return data, source, dir, err
+	}
+
+	path := reference
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(dir, path)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, "", "", err
+	}
+	return data, path, filepath.Dir(path), nil
+}
+
+// fetchPolicy downloads an `[org/]name` policy from the dep-risk API. The
+// organization defaults to the owner of the repository being scanned.
+func fetchPolicy(name string) ([]byte, string, error) {
+	endpoint := os.Getenv("DEP_RISK_API_ENDPOINT")
+	if endpoint == "" {
+		return nil, "", fmt.Errorf("DEP_RISK_API_ENDPOINT is not set")
+	}
+
+	org, policy, found := strings.Cut(name, "/")
+	if !found {
+		org, policy = os.Getenv("GITHUB_REPOSITORY_OWNER"), name
+	}
+	if org == "" || policy == "" {
+		return nil, "", fmt.Errorf("expected %sorg/name, or %sname with GITHUB_REPOSITORY_OWNER set", apiPrefix, apiPrefix)
+	}
+
+	policyURL := fmt.Sprintf("%s/api/v1/orgs/%s/policies/%s", strings.TrimSuffix(endpoint, "/"), url.PathEscape(org), url.PathEscape(policy))
+	req, err := http.NewRequest(http.MethodGet, policyURL, nil)
+	if err != nil {
+		return nil, "", err
+	}
+	if token := os.Getenv("DEP_RISK_API_TOKEN"); token != "" {
+		req.Header.Set("X-API-Key", token)
+	}
+
+	client := &http.Client{Timeout: 30 * time.Second}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, "", err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, "", fmt.Errorf("GET %s returned status %d", policyURL, resp.StatusCode)
+	}
+
+	data, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return nil, "", err
+	}
+	return data, apiPrefix + org + "/" + policy, nil
+}
+
+// mergeNodes merges a config over its base. Mappings are merged key by key,
+// lists and scalars of the config replace those of the base, except for
+// the keys in mergeStrategies.
+func mergeNodes(base, override *yaml.Node, path string) *yaml.Node {
+	strategy := mergeStrategies[path]
+	switch {
+	case strategy == "append" && base.Kind == yaml.SequenceNode && override.Kind == yaml.SequenceNode:
+		merged := *base
+		merged.Content = append([]*yaml.Node(nil), base.Content...)
+		for _, item := range override.Content {
+			if !containsNode(merged.Content, item) {
+				merged.Content = append(merged.Content, item)
+			}
+		}
+		return &merged
+	case strategy == "replace":
+		return completeMapping(base, override, path)
+	case base.Kind != yaml.MappingNode, override.Kind != yaml.MappingNode:
+		return override
+	}
+
+	merged := *base
+	merged.Content = append([]*yaml.Node(nil), base.Content...)
+	for i := 0; i+1 < len(override.Content); i += 2 {
+		key, value := override.Content[i], override.Content[i+1]
+		if key.Value == "extends" {
+			// Resolved already
+			continue
+		}
+
+		replaced := false
+		for j := 0; j+1 < len(merged.Content); j += 2 {
+			if merged.Content[j].Value == key.Value {
+				merged.Content[j+1] = mergeNodes(merged.Content[j+1], value, joinPath(path, key.Value))
+				replaced = true
+				break
+			}
+		}
+		if !replaced {
+			merged.Content = append(merged.Content, key, value)
+		}
+	}
+	return &merged
+}
+
+// completeMapping sets the fields of the section at path that a mapping
+// omits to their zero value, so it replaces the base section as a whole.
+// Fields in keptFields keep the base value instead.
+func completeMapping(base, mapping *yaml.Node, path string) *yaml.Node {
+	t, ok := fieldType(reflect.TypeOf(FileV1{}), path)
+	if !ok || t.Kind() != reflect.Struct || mapping.Kind != yaml.MappingNode {
+		return mapping
+	}
+
+	completed := *mapping
+	completed.Content = append([]*yaml.Node(nil), mapping.Content...)
+	fields := yamlFields(t)
+	for _, name := range sortedFieldNames(fields) {
+		if mappingValue(mapping, name) != nil {
+			continue
+		}
+		if slices.Contains(keptFields[path], name) {
+			if value := mappingValue(base, name); value != nil {
+				completed.Content = append(completed.Content, &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: name}, value)
+			}
+			continue
+		}
+		var zero yaml.Node
+		if err := zero.Encode(reflect.Zero(fields[name]).Interface()); err != nil {
+			continue
+		}
+		completed.Content = append(completed.Content, &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: name}, &zero)
+	}
+	return &completed
+}
+
+// containsNode reports whether a list holds a node with the same content
+func containsNode(nodes []*yaml.Node, node *yaml.Node) bool {
+	for _, candidate := range nodes {
+		if sameNode(candidate, node) {
+			return true
+		}
+	}
+	return false
+}
+
+// sameNode compares the content of two nodes, ignoring style and position
+func sameNode(a, b *yaml.Node) bool {
+	if a.Kind != b.Kind || a.Value != b.Value || len(a.Content) != len(b.Content) {
+		return false
+	}
+	for i := range a.Content {
+		if !sameNode(a.Content[i], b.Content[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+// checkLocks reports the locked keys whose values are less strict than in the
+// base config
+func (c *Config) checkLocks() error {
+	if c.base == nil {
+		return nil
+	}
+	base := reflect.ValueOf(*c.base)
+	var errs []error
+	check := func(prefix string, file FileV1) {
+		current := reflect.ValueOf(file)
+		for _, key := range c.Locked {
+			baseValue, _ := fieldByPath(base, key)
+			value, _ := fieldByPath(current, key)
+			if err := checkLocked(key, baseValue, value); err != nil {
+				errs = append(errs, fmt.Errorf("%s%w (locked by %s)", prefix, err, c.Extends))
+			}
+		}
+	}
+
+	check("", c.File())
+	// Override blocks cannot loosen locked thresholds either
+	for i, override := range c.Overrides {
+		file := c.File()
+		file.Thresholds.FailScore, file.Thresholds.WarnScore = override.Apply(file.Thresholds.FailScore, file.Thresholds.WarnScore)
+		check(fmt.Sprintf("overrides[%d]: ", i), file)
+	}
+	return errors.Join(errs...)
+}
+
+// checkLocked compares a locked value with its base value, field by field
+// for sections
+func checkLocked(key string, base, value reflect.Value) error {
+	if base.Kind() == reflect.Struct {
+		for i := 0; i < base.NumField(); i++ {
+			name := strings.Split(base.Type().Field(i).Tag.Get("yaml"), ",")[0]
+			if err := checkLocked(joinPath(key, name), base.Field(i), value.Field(i)); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	allowed := reflect.DeepEqual(base.Interface(), value.Interface())
+	if rule, ok := lockRules[key]; ok {
+		allowed = rule(base, value)
+	}
+	if !allowed {
+		return fmt.Errorf("%s is locked and cannot be loosened: base %s, got %s", key, formatValue(base), formatValue(value))
+	}
+	return nil
+}
+
+// keepsEnabled allows a locked setting that is enabled in the base to stay
+// enabled only
+func keepsEnabled(base, value reflect.Value) bool {
+	return !base.Bool() || value.Bool()
+}
+
+// isSubset allows a locked list to drop entries of the base but not add any
+func isSubset(base, value reflect.Value) bool {
+	for i := 0; i < value.Len(); i++ {
+		found := false
+		for j := 0; j < base.Len(); j++ {
+			if reflect.DeepEqual(value.Index(i).Interface(), base.Index(j).Interface()) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
+
+// formatValue renders a locked value for error messages
+func formatValue(value reflect.Value) string {
+	data, err := yaml.Marshal(value.Interface())
+	if err != nil {
+		return fmt.Sprint(value.Interface())
+	}
+	return strings.TrimSpace(strings.ReplaceAll(string(data), "\n", " "))
+}
+
+// fieldByPath returns the field of a FileV1 value at a dotted yaml path
+func fieldByPath(value reflect.Value, path string) (reflect.Value, bool) {
+	for _, name := range strings.Split(path, ".") {
+		for value.Kind() == reflect.Ptr {
+			if value.IsNil() {
+				value = reflect.New(value.Type().Elem())
+			}
+			value = value.Elem()
+		}
+		if value.Kind() != reflect.Struct {
+			return reflect.Value{}, false
+		}
+		field, ok := structField(value.Type(), name)
+		if !ok {
+			return reflect.Value{}, false
+		}
+		value = value.FieldByIndex(field.Index)
+	}
+	for value.Kind() == reflect.Ptr {
+		if value.IsNil() {
+			value = reflect.New(value.Type().Elem())
+		}
+		value = value.Elem()
+	}
+	return value, true
+}
+
+// fieldType returns the type of the field at a dotted yaml path
+func fieldType(t reflect.Type, path string) (reflect.Type, bool) {
+	for _, name := range strings.Split(path, ".") {
+		for t.Kind() == reflect.Ptr {
+			t = t.Elem()
+		}
+		if t.Kind() != reflect.Struct {
+			return nil, false
+		}
+		field, ok := structField(t, name)
+		if !ok {
+			return nil, false
+		}
+		t = field.Type
+	}
+	return t, true
+}
+
+// structField finds a struct field by its yaml key
+func structField(t reflect.Type, name string) (reflect.StructField, bool) {
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if strings.Split(field.Tag.Get("yaml"), ",")[0] == name {
+			return field, true
+		}
+	}
+	return reflect.StructField{}, false
+}
+
+// ValidatePolicy checks a base config served by the dep-risk API. Policies
+// are read by many repositories, so they cannot extend other files.
+func ValidatePolicy(data []byte) error {
+	root, _, err := parseVersioned(data)
+	if err != nil {
+		return err
+	}
+	if unknown := unknownKeys(root, reflect.TypeOf(FileV1{}), ""); len(unknown) > 0 {
+		return unknown
+	}
+	if extends := mappingValue(root, "extends"); extends != nil {
+		return fmt.Errorf("line %d: policies cannot use extends", extends.Line)
+	}
+	if _, err := resolveExtends(root, "", 0, nil); err != nil {
+		return err
+	}
+
+	cfg := DefaultConfig()
+	if err := cfg.apply(root); err != nil {
+		return err
+	}
+	return cfg.validate()
+}
+
+// Effective renders the configuration in effect, merged over its base configs
+// and with the environment applied, as a version 1 file
+func (c *Config) Effective() ([]byte, error) {
+	var out bytes.Buffer
+	encoder := yaml.NewEncoder(&out)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(c.File()); err != nil {
+		return nil, err
+	}
+	if err := encoder.Close(); err != nil {
+		return nil, err
+	}
+	return out.Bytes(), nil
+}
@@ -0,0 +1,330 @@
+package config
+
+import (
+	"fmt"
+	"path"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/dep-risk/dep-risk/internal/scanner"
+)
+
+// IgnoreRule is a structured entry of ignore.rules. Every selector that is
+// set must match; the rule stops applying on its expiry date.
+type IgnoreRule struct {
+	// ID is an advisory ID or one of its aliases
+	ID string `yaml:"id"`
+	// Package is a package name, with Version an optional constraint such as
+	// `4.17.*` or `>=4.0.0, <4.17.21`
+	Package string `yaml:"package"`
+	Version string `yaml:"version"`
+	// Paths are globs matched against the file a finding was reported in
+	Paths []string `yaml:"paths"`
+
+	Reason  string `yaml:"reason"`
+	Owner   string `yaml:"owner"`
+	Expires string `yaml:"expires"`
+}
+
+// ExpiryLayout is the date format of IgnoreRule.Expires
+const ExpiryLayout = "2006-01-02"
+
+// Matches reports whether the selectors of a rule match a finding
+func (r IgnoreRule) Matches(vuln scanner.Vulnerability) bool {
+	if r.ID != "" && !matchID(r.ID, vuln) {
+		return false
+	}
+	if r.Package != "" && (r.Package != vuln.Package || !matchVersion(r.Version, vuln.Version)) {
+		return false
+	}
+	if len(r.Paths) > 0 && !matchPaths(r.Paths, vuln) {
+		return false
+	}
+	return true
+}
+
+// Expired reports whether a rule is past its expiry date. The rule still
+// applies on that date (UTC); rules without one never expire.
+func (r IgnoreRule) Expired(now time.Time) bool {
+	if r.Expires == "" {
+		return false
+	}
+	expires, err := time.Parse(ExpiryLayout, r.Expires)
+	return err == nil && !now.UTC().Before(expires.AddDate(0, 0, 1))
+}
+
+// String describes the selectors of a rule
+func (r IgnoreRule) String() string {
+	var parts []string
+	if r.ID != "" {
+		parts = append(parts, r.ID)
+	}
+	if r.Package != "" {
+		pkg := r.Package
+		if r.Version != "" {
+			pkg += "@" + r.Version
+		}
+		parts = append(parts, pkg)
+	}
+	if len(r.Paths) > 0 {
+		parts = append(parts, "in "+strings.Join(r.Paths, ", "))
+	}
+	return strings.Join(parts, " ")
+}
+
+// validate checks the selectors, justification and expiry of a rule
+func (r IgnoreRule) validate() error {
+	if r.ID == "" && r.Package == "" && len(r.Paths) == 0 {
+		return fmt.Errorf("needs at least one of id, package or paths")
+	}
+	if r.Version != "" && r.Package == "" {
+		return fmt.Errorf("version requires package")
+	}
+	if _, err := parseConstraint(r.Version); err != nil {
+		return fmt.Errorf("version: %w", err)
+	}
+	for _, pattern := range r.Paths {
+		if strings.TrimSpace(pattern) == "" {
+			return fmt.Errorf("paths cannot contain empty entries")
+		}
+	}
+	if strings.TrimSpace(r.Reason) == "" {
+		return fmt.Errorf("reason is required")
+	}
+	if r.Expires != "" {
+		if _, err := time.Parse(ExpiryLayout, r.Expires); err != nil {
+			return fmt.Errorf("expires must be a date in YYYY-MM-DD format, got %q", r.Expires)
+		}
+	}
+	return nil
+}
+
+// CheckIgnore checks a finding against the ignore section: advisory IDs,
+// packages, paths and the ignore rules in effect at now. A finding that is
+// not ignored returns the expired rule that used to cover it, if any.
+func (c *Config) CheckIgnore(vuln scanner.Vulnerability, now time.Time) (bool, *IgnoreRule) {
+	for _, id := range c.IgnoreList {
+		if matchID(id, vuln) {
+			return true, nil
+		}
+	}
+
+	for _, entry := range c.IgnorePackages {
+		if matchPackage(entry, vuln.Package, vuln.Version) {
+			return true, nil
+		}
+	}
+
+	if matchPaths(c.IgnorePaths, vuln) {
+		return true, nil
+	}
+
+	var expired *IgnoreRule
+	for i, rule := range c.IgnoreRules {
+		if !rule.Matches(vuln) {
+			continue
+		}
+		if !rule.Expired(now) {
+			return true, nil
+		}
+		if expired == nil {
+			expired = &c.IgnoreRules[i]
+		}
+	}
+	return false, expired
+}
+
+// UnjustifiedIgnores lists the entries of ignore.cves, ignore.packages and
+// ignore.paths. Unlike rules they carry no reason, owner or expiry, so they
+// are reported for auditing.
+func (c *Config) UnjustifiedIgnores() []string {
+	var entries []string
+	for _, id := range c.IgnoreList {
+		entries = append(entries, "ignore.cves: "+id)
+	}
+	for _, entry := range c.IgnorePackages {
+		entries = append(entries, "ignore.packages: "+entry)
+	}
+	for _, pattern := range c.IgnorePaths {
+		entries = append(entries, "ignore.paths: "+pattern)
+	}
+	return entries
+}
+
+// matchID matches an advisory ID against the ID and aliases of a finding
+func matchID(id string, vuln scanner.Vulnerability) bool {
+	if strings.EqualFold(id, vuln.ID) {
+		return true
+	}
+	for _, alias := range vuln.Aliases {
+		if strings.EqualFold(id, alias) {
+			return true
+		}
+	}
+	return false
+}
+
+// matchPaths matches the file a finding was reported in against path globs
+func matchPaths(patterns []string, vuln scanner.Vulnerability) bool {
+	for _, file := range []string{vuln.File, vuln.Source} {
+		if file == "" {
+			continue
+		}
+		for _, pattern := range patterns {
+			if matchGlob(pattern, file) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// matchPackage matches a `name` or `name@constraint` entry. Scoped npm names
+// keep their leading @.
+func matchPackage(entry, name, version string) bool {
+	entryName, constraint := splitPackageEntry(entry)
+	return entryName == name && matchVersion(constraint, version)
+}
+
+// splitPackageEntry splits a `name@constraint` entry at its last @
+func splitPackageEntry(entry string) (string, string) {
+	if at := strings.LastIndex(entry, "@"); at > 0 {
+		return entry[:at], entry[at+1:]
+	}
+	return entry, ""
+}
+
+// matchVersion reports whether a version satisfies a constraint. An empty
+// constraint matches every version, and one that does not parse none.
+func matchVersion(constraint, version string) bool {
+	alternatives, err := parseConstraint(constraint)
+	if err != nil {
+		return false
+	}
+	if len(alternatives) == 0 {
+		return true
+	}
+	for _, terms := range alternatives {
+		satisfied := true
+		for _, term := range terms {
+			if !term.matches(version) {
+				satisfied = false
+				break
+			}
+		}
+		if satisfied {
+			return true
+		}
+	}
+	return false
+}
+
+// versionTerm is a single comparison or glob of a version constraint
+type versionTerm struct {
+	op      string
+	version string
+}
+
+func (t versionTerm) matches(version string) bool {
+	if t.op == "glob" {
+		matched, _ := path.Match(t.version, version)
+		return matched
+	}
+	cmp := scanner.CompareVersions(version, t.version)
+	switch t.op {
+	case ">=":
+		return cmp >= 0
+	case ">":
+		return cmp > 0
+	case "<=":
+		return cmp <= 0
+	case "<":
+		return cmp < 0
+	case "!=":
+		return cmp != 0
+	default:
+		return cmp == 0
+	}
+}
+
+// parseConstraint parses a version constraint: alternatives separated by
+// `||`, each a list of terms separated by commas or spaces. A term is a
+// comparison (`>=1.2.0`, `<2`, `=1.4.1`, `!=1.4.0`), a glob (`4.17.*`) or an
+// exact version.
+func parseConstraint(constraint string) ([][]versionTerm, error) {
+	if strings.TrimSpace(constraint) == "" {
+		return nil, nil
+	}
+
+	var alternatives [][]versionTerm
+	for _, alternative := range strings.Split(constraint, "||") {
+		var terms []versionTerm
+		fields := strings.FieldsFunc(alternative, func(r rune) bool { return r == ',' || r == ' ' })
+		for i := 0; i < len(fields); i++ {
+			field := fields[i]
+			op := ""
+			for _, candidate := range []string{">=", "<=", "!=", ">", "<", "="} {
+				if strings.HasPrefix(field, candidate) {
+					op, field = candidate, strings.TrimPrefix(field, candidate)
+					break
+				}
+			}
+			// Allow a space between the operator and the version
+			if op != "" && field == "" && i+1 < len(fields) {
+				i++
+				field = fields[i]
+			}
+			if field == "" {
+				return nil, fmt.Errorf("missing version after %q in %q", op, constraint)
+			}
+			if op == "" && strings.ContainsAny(field, "*?[") {
+				if _, err := path.Match(field, ""); err != nil {
+					return nil, fmt.Errorf("invalid version pattern %q", field)
+				}
+				op = "glob"
+			}
+			terms = append(terms, versionTerm{op: op, version: field})
+		}
+		if len(terms) == 0 {
+			return nil, fmt.Errorf("empty alternative in %q", constraint)
+		}
+		alternatives = append(alternatives, terms)
+	}
+	return alternatives, nil
+}
+
+// matchGlob matches a slash-separated path against a glob where * and ?
+// stay within a path segment and ** spans any number of segments
+func matchGlob(pattern, name string) bool {
+	re, err := globRegexp(strings.TrimPrefix(pattern, "./"), true)
+	return err == nil && re.MatchString(strings.TrimPrefix(name, "./"))
+}
+
+// globRegexp translates a glob to an anchored regular expression. With
+// below, a pattern also matches everything below the directory it names.
+func globRegexp(pattern string, below bool) (*regexp.Regexp, error) {
+	var re strings.Builder
+	re.WriteString("^")
+	for i := 0; i < len(pattern); i++ {
+		switch ch := pattern[i]; {
+		case strings.HasPrefix(pattern[i:], "**/"):
+			re.WriteString("(?:.*/)?")
+			i += 2
+		case strings.HasPrefix(pattern[i:], "**"):
+			re.WriteString(".*")
+			i++
+		case ch == '*':
+			re.WriteString("[^/]*")
+		case ch == '?':
+			re.WriteString("[^/]")
+		default:
+			re.WriteString(regexp.QuoteMeta(string(ch)))
+		}
+	}
+	if below {
+		re.WriteString("(?:/.*)?")
+	}
+	re.WriteString("$")
+	return regexp.Compile(re.String())
+}
@@ -0,0 +1,116 @@
+package config
+
+import (
+	"encoding/json"
+	"reflect"
+
+	"github.com/dep-risk/dep-risk/internal/scorer"
+)
+
+// SchemaID is the URL the published JSON Schema of the config file is served from
+const SchemaID = "https://raw.githubusercontent.com/dep-risk/dep-risk/main/schema/dep-risk.schema.json"
+
+// schemaHints add descriptions, allowed values and ranges to the keys of the
+// generated JSON Schema. List items are addressed as `key[]`.
+var schemaHints = map[string]map[string]interface{}{
+	"version":                      {"const": CurrentVersion, "description": "Config schema version"},
+	"extends":                      {"description": "Base config merged under this one: a path relative to this file, or api:[org/]name"},
+	"locked":                       {"description": "Keys files extending this one cannot loosen"},
+	"scoring":                      {"description": "Scoring model, weights and factors"},
+	"scoring.model":                {"enum": modelVersions()},
+	"scoring.aggregation.strategy": {"enum": scorer.AggregationStrategies},
+	"scoring.cvss_weight":          {"description": "Same as weights.cvss; cannot be combined with weights"},
+	"scoring.popularity_weight":    {"description": "Same as weights.popularity; cannot be combined with weights"},
+	"scoring.depth_weight":         {"description": "Same as weights.dependency; cannot be combined with weights"},
+	"thresholds":                   {"description": "Project scores that fail or warn the check"},
+	"thresholds.fail_score":        {"minimum": 0, "maximum": 10},
+	"thresholds.warn_score":        {"minimum": 0, "maximum": 10},
+	"ignore":                       {"description": "Findings excluded from scoring"},
+	"ignore.rules[].expires":       {"format": "date"},
+	"notifications.comment_mode":   {"enum": commentModes},
+	"notifications.slack":          {"description": "Accepted for compatibility; not delivered by the action"},
+	"notifications.email":          {"description": "Accepted for compatibility; not delivered by the action"},
+	"scan.timeout":                 {"minimum": 1, "description": "Scan timeout in seconds"},
+	"scan.parallel_jobs":           {"minimum": 1},
+	"cache.ttl":                    {"description": "Cache lifetime in hours"},
+	"context":                      {"description": "Execution context of the project; estimated from package names when absent"},
+	"context.execution[]":          {"enum": sortedKeys(scorer.ExecutionModifiers)},
+	"context.data_sensitivity":     {"enum": sortedKeys(scorer.SensitivityModifiers)},
+	"context.environment":          {"enum": sortedKeys(scorer.EnvironmentModifiers)},
+	"popularity.provider":          {"enum": popularityProviders},
+	"eol.warning_days":             {"minimum": 0},
+	"overrides":                    {"description": "Thresholds for some branches, events or paths; later blocks win"},
+	"overrides[].when.branches":    {"description": "Branch globs; pull requests match on the branch they target"},
+	"overrides[].when.events":      {"description": "Workflow event names such as pull_request, push or schedule"},
+	"overrides[].when.paths":       {"description": "Globs of the files findings are reported in; these findings are judged on their own"},
+	"kev":                          {"description": "Known Exploited Vulnerabilities lookup for the kev field of gates and rules"},
+	"kev.catalog":                  {"description": "Local copy of the CISA KEV catalog; the catalog URL is downloaded when unset"},
+	"gates":                        {"description": "Named gates that fail or warn the check when more than max_count findings match"},
+	"gates[].condition":            {"description": "Expression over the fields of a finding, as in scoring rules, plus its final score"},
+	"gates[].max_count":            {"minimum": 0},
+	"gates[].action":               {"enum": []string{scorer.GateFail, scorer.GateWarn}},
+	"policies.files":               {"description": "CEL policy files evaluated against the scored report, relative to the repository root"},
+}
+
+// JSONSchema returns a JSON Schema of the version 1 config file, for editor
+// completion and validation
+func JSONSchema() ([]byte, error) {
+	schema := typeSchema(reflect.TypeOf(FileV1{}), "")
+	schema["$schema"] = "http://json-schema.org/draft-07/schema#"
+	schema["$id"] = SchemaID
+	schema["title"] = "dep-risk configuration"
+	schema["required"] = []string{"version"}
+
+	data, err := json.MarshalIndent(schema, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append(data, '\n'), nil
+}
+
+// typeSchema describes the values of a Go type decoded from the config file
+func typeSchema(t reflect.Type, path string) map[string]interface{} {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	schema := make(map[string]interface{})
+	switch t.Kind() {
+	case reflect.Struct:
+		properties := make(map[string]interface{})
+		for name, field := range yamlFields(t) {
+			properties[name] = typeSchema(field, joinPath(path, name))
+		}
+		schema["type"] = "object"
+		schema["properties"] = properties
+		schema["additionalProperties"] = false
+	case reflect.Map:
+		schema["type"] = "object"
+		schema["additionalProperties"] = typeSchema(t.Elem(), path+".*")
+	case reflect.Slice:
+		schema["type"] = "array"
+		schema["items"] = typeSchema(t.Elem(), path+"[]")
+	case reflect.String:
+		schema["type"] = "string"
+	case reflect.Bool:
+		schema["type"] = "boolean"
+	case reflect.Int, reflect.Int64:
+		schema["type"] = "integer"
+	case reflect.Float64:
+		schema["type"] = "number"
+	}
+
+	for key, value := range schemaHints[path] {
+		schema[key] = value
+	}
+	return schema
+}
+
+// modelVersions returns the versions of the released scoring models
+func modelVersions() []string {
+	var versions []string
+	for _, model := range scorer.Models() {
+		versions = append(versions, model.Version)
+	}
+	return versions
+}
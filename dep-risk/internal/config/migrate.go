@@ -0,0 +1,350 @@
+package config
+
+import (
+	"bytes"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// flatKeys maps the keys of the unversioned flat format to their location in
+// the version 1 schema
+var flatKeys = map[string][]string{
+	"fail_threshold":          {"thresholds", "fail_score"},
+	"warn_threshold":          {"thresholds", "warn_score"},
+	"ignore_list":             {"ignore", "cves"},
+	"cvss_weight":             {"scoring", "weights", "cvss"},
+	"popularity_weight":       {"scoring", "weights", "popularity"},
+	"dependency_weight":       {"scoring", "weights", "dependency"},
+	"context_weight":          {"scoring", "weights", "context"},
+	"maintenance_weight":      {"scoring", "weights", "maintenance"},
+	"hygiene_weight":          {"scoring", "weights", "hygiene"},
+	"dependency_scoring":      {"scoring", "dependency"},
+	"comment_mode":            {"notifications", "comment_mode"},
+	"sarif_upload":            {"notifications", "sarif_upload"},
+	"dashboard_upload":        {"notifications", "dashboard_upload"},
+	"scan_paths":              {"scan", "paths"},
+	"exclude_paths":           {"scan", "exclude_paths"},
+	"languages":               {"scan", "languages"},
+	"timeout":                 {"scan", "timeout"},
+	"parallel_jobs":           {"scan", "parallel_jobs"},
+	"cache_enabled":           {"cache", "enabled"},
+	"cache_ttl":               {"cache", "ttl"},
+	"context":                 {"context"},
+	"popularity_provider":     {"popularity", "provider"},
+	"popularity_snapshot":     {"popularity", "snapshot"},
+	"popularity_url":          {"popularity", "url"},
+	"scorecard_results":       {"maintenance", "scorecard_results"},
+	"release_metadata":        {"maintenance", "release_metadata"},
+	"hygiene_enabled":         {"hygiene", "enabled"},
+	"goproxy":                 {"hygiene", "goproxy"},
+	"npm_registry_snapshot":   {"hygiene", "npm_registry_snapshot"},
+	"eol_enabled":             {"eol", "enabled"},
+	"eol_dataset":             {"eol", "dataset"},
+	"eol_warning_days":        {"eol", "warning_days"},
+	"workflow_scan_enabled":   {"workflows", "enabled"},
+	"workflow_advisories":     {"workflows", "advisories"},
+	"workflow_require_sha":    {"workflows", "require_sha"},
+	"workflow_allowed_owners": {"workflows", "allowed_owners"},
+}
+
+// weightKeys maps the weight keys of the scoring section in the design
+// document to the weights they set
+var weightKeys = []struct{ key, weight string }{
+	{"cvss_weight", "cvss"},
+	{"popularity_weight", "popularity"},
+	{"depth_weight", "dependency"},
+}
+
+// sectionOrder is the order of the top-level sections in a migrated file
+var sectionOrder = []string{
+	"version", "scoring", "thresholds", "ignore", "notifications", "scan", "cache",
+	"context", "popularity", "maintenance", "hygiene", "eol", "workflows", "overrides", "kev", "gates", "policies",
+}
+
+// Migrate rewrites a flat configuration file in the version 1 schema. Values,
+// comments and formatting of the moved entries are kept. Files that already
+// declare a version are returned unchanged.
+func Migrate(data []byte) ([]byte, bool, error) {
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return nil, false, err
+	}
+	root := documentRoot(&doc)
+	if root == nil || mappingValue(root, "version") != nil {
+		return data, false, nil
+	}
+
+	migrated, err := migrateRoot(root)
+	if err != nil {
+		return nil, false, err
+	}
+	doc.Content[0] = migrated
+
+	var out bytes.Buffer
+	encoder := yaml.NewEncoder(&out)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(&doc); err != nil {
+		return nil, false, err
+	}
+	if err := encoder.Close(); err != nil {
+		return nil, false, err
+	}
+
+	// The migrated file must load to the same settings
+	before, after := DefaultConfig(), DefaultConfig()
+	if err := before.load(data, "."); err != nil {
+		return nil, false, err
+	}
+	if err := after.load(out.Bytes(), "."); err != nil {
+		return nil, false, fmt.Errorf("migrated file does not load: %w", err)
+	}
+	before.LegacyFormat, before.lines, after.lines = false, nil, nil
+	if !reflect.DeepEqual(before, after) {
+		return nil, false, fmt.Errorf("migrated file does not load to the same settings")
+	}
+	return out.Bytes(), true, nil
+}
+
+// migrateRoot moves the entries of a flat top-level mapping into the
+// sections of the version 1 schema
+func migrateRoot(root *yaml.Node) (*yaml.Node, error) {
+	if root.Kind != yaml.MappingNode {
+		return nil, fmt.Errorf("line %d: expected a mapping at the top level", root.Line)
+	}
+
+	// Comments ahead of the first entry belong to the file
+	var fileComment string
+	if len(root.Content) > 0 {
+		first := *root.Content[0]
+		fileComment, first.HeadComment = first.HeadComment, ""
+		root.Content[0] = &first
+	}
+
+	sections := make(map[string]*yaml.Node)
+	sectionKeys := make(map[string]*yaml.Node)
+	section := func(name string) *yaml.Node {
+		if sections[name] == nil {
+			sections[name] = &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map"}
+		}
+		return sections[name]
+	}
+
+	for i := 0; i+1 < len(root.Content); i += 2 {
+		key, value := root.Content[i], root.Content[i+1]
+		if key.Value == "scoring" {
+			// The nested scoring settings keep their place under scoring
+			if value.Kind != yaml.MappingNode {
+				return nil, fmt.Errorf("line %d: scoring must be a mapping", value.Line)
+			}
+			for j := 0; j+1 < len(value.Content); j += 2 {
+				setPath(section("scoring"), nil, value.Content[j], value.Content[j+1])
+			}
+			sectionKeys["scoring"] = key
+			continue
+		}
+
+		path, ok := flatKeys[key.Value]
+		if !ok {
+			if contains(sectionOrder, key.Value) {
+				return nil, fmt.Errorf("line %d: %s is a version 1 section; add `version: %d` to the file", key.Line, key.Value, CurrentVersion)
+			}
+			return nil, fmt.Errorf("line %d: unknown key %s", key.Line, key.Value)
+		}
+		if len(path) == 1 {
+			sections[path[0]] = value
+			sectionKeys[path[0]] = key
+			continue
+		}
+		renamed := renameKey(key, path[len(path)-1])
+		setPath(section(path[0]), path[1:len(path)-1], renamed, value)
+	}
+
+	migrated := &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map"}
+	migrated.Content = append(migrated.Content,
+		&yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: "version", HeadComment: fileComment},
+		&yaml.Node{Kind: yaml.ScalarNode, Tag: "!!int", Value: fmt.Sprint(CurrentVersion)},
+	)
+	for _, name := range sectionOrder[1:] {
+		value := sections[name]
+		if value == nil {
+			continue
+		}
+		key := sectionKeys[name]
+		if key == nil {
+			key = &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: name}
+		}
+		migrated.Content = append(migrated.Content, key, value)
+	}
+	return migrated, nil
+}
+
+// setPath adds a key and value to a mapping, creating the intermediate
+// mappings of path
+func setPath(mapping *yaml.Node, path []string, key, value *yaml.Node) {
+	for _, name := range path {
+		next := mappingValue(mapping, name)
+		if next == nil {
+			next = &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map"}
+			mapping.Content = append(mapping.Content, &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: name}, next)
+		}
+		mapping = next
+	}
+	mapping.Content = append(mapping.Content, key, value)
+}
+
+// renameKey returns a copy of a key node with another name
+func renameKey(key *yaml.Node, name string) *yaml.Node {
+	renamed := *key
+	renamed.Value = name
+	return &renamed
+}
+
+// expandWeightKeys rewrites the design document's scoring.cvss_weight,
+// popularity_weight and depth_weight to scoring.weights. Like weights, they
+// replace the weights as a set: the context and maintenance weights are 0.
+func expandWeightKeys(root *yaml.Node) error {
+	scoring := mappingValue(root, "scoring")
+	if scoring == nil || scoring.Kind != yaml.MappingNode {
+		return nil
+	}
+
+	weights := &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map"}
+	var rest []*yaml.Node
+	for i := 0; i+1 < len(scoring.Content); i += 2 {
+		key, value := scoring.Content[i], scoring.Content[i+1]
+		moved := false
+		for _, shorthand := range weightKeys {
+			if key.Value == shorthand.key {
+				weights.Content = append(weights.Content, &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: shorthand.weight, Line: key.Line}, value)
+				moved = true
+			}
+		}
+		if !moved {
+			rest = append(rest, key, value)
+		}
+	}
+	if len(weights.Content) == 0 {
+		return nil
+	}
+	if existing := mappingValue(scoring, "weights"); existing != nil {
+		return fmt.Errorf("line %d: scoring.weights cannot be combined with scoring.cvss_weight, popularity_weight or depth_weight", existing.Line)
+	}
+
+	for _, omitted := range []string{"cvss", "popularity", "dependency", "context", "maintenance"} {
+		if mappingValue(weights, omitted) == nil {
+			weights.Content = append(weights.Content,
+				&yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: omitted},
+				&yaml.Node{Kind: yaml.ScalarNode, Tag: "!!float", Value: "0"})
+		}
+	}
+	scoring.Content = append(rest, &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: "weights"}, weights)
+	return nil
+}
+
+// documentRoot returns the top-level node of a document, or nil when the
+// document is empty
+func documentRoot(doc *yaml.Node) *yaml.Node {
+	if doc.Kind != yaml.DocumentNode || len(doc.Content) == 0 {
+		return nil
+	}
+	return doc.Content[0]
+}
+
+// mappingValue returns the value of a key in a mapping node
+func mappingValue(mapping *yaml.Node, key string) *yaml.Node {
+	if mapping == nil || mapping.Kind != yaml.MappingNode {
+		return nil
+	}
+	for i := 0; i+1 < len(mapping.Content); i += 2 {
+		if mapping.Content[i].Value == key {
+			return mapping.Content[i+1]
+		}
+	}
+	return nil
+}
+
+// unknownKeys reports the mapping keys that do not name a field of the type
+// decoded at their position, with the line they appear on
+func unknownKeys(node *yaml.Node, t reflect.Type, path string) Problems {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	var problems Problems
+	switch t.Kind() {
+	case reflect.Struct:
+		if node.Kind != yaml.MappingNode {
+			return nil
+		}
+		fields := yamlFields(t)
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			key := node.Content[i]
+			field, ok := fields[key.Value]
+			if !ok {
+				problems = append(problems, Problem{
+					Line:    key.Line,
+					Message: fmt.Sprintf("unknown key %s (valid: %s)", joinPath(path, key.Value), strings.Join(sortedFieldNames(fields), ", ")),
+				})
+				continue
+			}
+			problems = append(problems, unknownKeys(node.Content[i+1], field, joinPath(path, key.Value))...)
+		}
+	case reflect.Slice:
+		if node.Kind != yaml.SequenceNode {
+			return nil
+		}
+		for i, element := range node.Content {
+			problems = append(problems, unknownKeys(element, t.Elem(), fmt.Sprintf("%s[%d]", path, i))...)
+		}
+	case reflect.Map:
+		if node.Kind != yaml.MappingNode {
+			return nil
+		}
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			problems = append(problems, unknownKeys(node.Content[i+1], t.Elem(), joinPath(path, node.Content[i].Value))...)
+		}
+	}
+	return problems
+}
+
+// yamlFields returns the field types of a struct by yaml key
+func yamlFields(t reflect.Type) map[string]reflect.Type {
+	fields := make(map[string]reflect.Type)
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+		name := strings.Split(field.Tag.Get("yaml"), ",")[0]
+		if name == "-" {
+			continue
+		}
+		if name == "" {
+			name = strings.ToLower(field.Name)
+		}
+		fields[name] = field.Type
+	}
+	return fields
+}
+
+// sortedFieldNames returns the yaml keys of a struct in a stable order
+func sortedFieldNames(fields map[string]reflect.Type) []string {
+	names := make([]string, 0, len(fields))
+	for name := range fields {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// joinPath appends a key to a dotted configuration path
+func joinPath(path, key string) string {
+	if path == "" {
+		return key
+	}
+	return path + "." + key
+}
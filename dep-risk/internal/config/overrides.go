@@ -0,0 +1,132 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/dep-risk/dep-risk/internal/scanner"
+)
+
+// Override is an entry of `overrides`: thresholds that apply when its
+// conditions match. Blocks apply in order, so later blocks win.
+type Override struct {
+	Name       string             `yaml:"name"`
+	When       OverrideWhen       `yaml:"when"`
+	Thresholds OverrideThresholds `yaml:"thresholds"`
+}
+
+// OverrideWhen are the conditions of an override block. Every condition
+// that is set must match.
+type OverrideWhen struct {
+	// Branches are globs matched against the branch of the run; pull
+	// requests match on the branch they target
+	Branches []string `yaml:"branches"`
+	// Events are workflow event names such as pull_request or schedule
+	Events []string `yaml:"events"`
+	// Paths are globs matched against the file a finding was reported in.
+	// Findings of blocks with paths are judged apart from the others.
+	Paths []string `yaml:"paths"`
+}
+
+// OverrideThresholds are the thresholds an override block sets; unset ones
+// keep their value
+type OverrideThresholds struct {
+	FailScore *float64 `yaml:"fail_score,omitempty"`
+	WarnScore *float64 `yaml:"warn_score,omitempty"`
+}
+
+// RunContext is the run that override conditions are matched against
+type RunContext struct {
+	Branch string
+	Event  string
+}
+
+// RunContextFromEnv reads the branch and event of a GitHub Actions run
+func RunContextFromEnv() RunContext {
+	branch := os.Getenv("GITHUB_BASE_REF")
+	if branch == "" {
+		branch = os.Getenv("GITHUB_REF_NAME")
+	}
+	return RunContext{Branch: branch, Event: os.Getenv("GITHUB_EVENT_NAME")}
+}
+
+// matchesRun reports whether the branch and event conditions of a block
+// match a run
+func (o Override) matchesRun(run RunContext) bool {
+	if len(o.When.Branches) > 0 && !matchAny(o.When.Branches, run.Branch) {
+		return false
+	}
+	if len(o.When.Events) > 0 && !contains(o.When.Events, run.Event) {
+		return false
+	}
+	return true
+}
+
+// Apply returns thresholds with the values the block sets
+func (o Override) Apply(fail, warn float64) (float64, float64) {
+	if o.Thresholds.FailScore != nil {
+		fail = *o.Thresholds.FailScore
+	}
+	if o.Thresholds.WarnScore != nil {
+		warn = *o.Thresholds.WarnScore
+	}
+	return fail, warn
+}
+
+// validate checks the conditions and settings of a block
+func (o Override) validate() error {
+	if strings.TrimSpace(o.Name) == "" {
+		return fmt.Errorf("name is required")
+	}
+	if len(o.When.Branches) == 0 && len(o.When.Events) == 0 && len(o.When.Paths) == 0 {
+		return fmt.Errorf("when needs at least one of branches, events or paths")
+	}
+	for _, list := range [][]string{o.When.Branches, o.When.Events, o.When.Paths} {
+		for _, entry := range list {
+			if strings.TrimSpace(entry) == "" {
+				return fmt.Errorf("when cannot contain empty entries")
+			}
+		}
+	}
+	if o.Thresholds.FailScore == nil && o.Thresholds.WarnScore == nil {
+		return fmt.Errorf("sets no thresholds")
+	}
+	return nil
+}
+
+// ApplyOverrides applies the thresholds of the blocks without paths that
+// match a run, in order, and returns their names
+func (c *Config) ApplyOverrides(run RunContext) []string {
+	var applied []string
+	for _, override := range c.Overrides {
+		if len(override.When.Paths) > 0 || !override.matchesRun(run) {
+			continue
+		}
+		c.FailThreshold, c.WarnThreshold = override.Apply(c.FailThreshold, c.WarnThreshold)
+		applied = append(applied, override.Name)
+	}
+	return applied
+}
+
+// ScopeOf returns the last block with paths that matches a finding in a run,
+// or nil when the finding is judged against the run thresholds
+func (c *Config) ScopeOf(run RunContext, vuln scanner.Vulnerability) *Override {
+	var scope *Override
+	for i, override := range c.Overrides {
+		if len(override.When.Paths) > 0 && override.matchesRun(run) && matchPaths(override.When.Paths, vuln) {
+			scope = &c.Overrides[i]
+		}
+	}
+	return scope
+}
+
+// matchAny matches a slash-separated name such as a branch against globs
+func matchAny(patterns []string, name string) bool {
+	for _, pattern := range patterns {
+		if re, err := globRegexp(pattern, false); err == nil && re.MatchString(name) {
+			return true
+		}
+	}
+	return false
+}
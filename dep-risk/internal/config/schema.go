@@ -0,0 +1,281 @@
+package config
+
+import (
+	"github.com/dep-risk/dep-risk/internal/scorer"
+)
+
+// CurrentVersion is the version of the nested configuration schema
+const CurrentVersion = 1
+
+// FileV1 is the version 1 configuration file:
+//
+//	version: 1
+//	scoring:       model, weights, dependency, modifiers, timing, rules, aggregation
+//	thresholds:    fail_score, warn_score
+//	ignore:        packages, cves, paths, rules
+//	notifications: comment_mode, sarif_upload, dashboard_upload
+//
+// followed by the sections of the individual detectors and data sources.
+// `extends` names a base config the file is merged over, and `locked` the
+// keys files extending this one cannot loosen. `overrides` are thresholds
+// for some branches, events or paths.
+type FileV1 struct {
+	Version       int                 `yaml:"version"`
+	Extends       string              `yaml:"extends,omitempty"`
+	Locked        []string            `yaml:"locked,omitempty"`
+	Scoring       ScoringV1           `yaml:"scoring"`
+	Thresholds    ThresholdsV1        `yaml:"thresholds"`
+	Ignore        IgnoreV1            `yaml:"ignore"`
+	Notifications NotificationsV1     `yaml:"notifications"`
+	Scan          ScanV1              `yaml:"scan"`
+	Cache         CacheV1             `yaml:"cache"`
+	Context       *scorer.ContextInfo `yaml:"context,omitempty"`
+	Popularity    PopularityV1        `yaml:"popularity"`
+	Maintenance   MaintenanceV1       `yaml:"maintenance"`
+	Hygiene       HygieneV1           `yaml:"hygiene"`
+	EOL           EOLV1               `yaml:"eol"`
+	Workflows     WorkflowsV1         `yaml:"workflows"`
+	Overrides     []Override          `yaml:"overrides,omitempty"`
+	KEV           KEVV1               `yaml:"kev"`
+	Gates         []scorer.GateSpec   `yaml:"gates,omitempty"`
+	Policies      PoliciesV1          `yaml:"policies"`
+}
+
+// ScoringV1 is the `scoring` section
+type ScoringV1 struct {
+	Model       string                   `yaml:"model"`
+	Weights     scorer.ScoringWeights    `yaml:"weights"`
+	Dependency  scorer.DependencyParams  `yaml:"dependency"`
+	Modifiers   scorer.ModifierParams    `yaml:"modifiers"`
+	Timing      scorer.TimingParams      `yaml:"timing"`
+	Rules       []scorer.RuleSpec        `yaml:"rules"`
+	Aggregation scorer.AggregationParams `yaml:"aggregation"`
+
+	// The weight keys of the design document. They are rewritten to weights
+	// when the file is read, so they are always nil after loading.
+	CVSSWeight       *float64 `yaml:"cvss_weight,omitempty"`
+	PopularityWeight *float64 `yaml:"popularity_weight,omitempty"`
+	DepthWeight      *float64 `yaml:"depth_weight,omitempty"`
+}
+
+// ThresholdsV1 is the `thresholds` section
+type ThresholdsV1 struct {
+	FailScore float64 `yaml:"fail_score"`
+	WarnScore float64 `yaml:"warn_score"`
+}
+
+// IgnoreV1 is the `ignore` section
+type IgnoreV1 struct {
+	// Packages are `name` or `name@constraint` entries
+	Packages []string `yaml:"packages"`
+	// CVEs are advisory IDs
+	CVEs []string `yaml:"cves"`
+	// Paths are globs matched against the file a finding was reported in
+	Paths []string `yaml:"paths"`
+	// Rules are ignore entries with a justification and an optional expiry
+	Rules []IgnoreRule `yaml:"rules"`
+}
+
+// NotificationsV1 is the `notifications` section
+type NotificationsV1 struct {
+	CommentMode     string `yaml:"comment_mode"`
+	SarifUpload     bool   `yaml:"sarif_upload"`
+	DashboardUpload bool   `yaml:"dashboard_upload"`
+	// Slack and Email are read for compatibility with the design document;
+	// the action does not deliver them
+	Slack *SlackV1 `yaml:"slack,omitempty"`
+	Email *EmailV1 `yaml:"email,omitempty"`
+}
+
+// SlackV1 is the `notifications.slack` section
+type SlackV1 struct {
+	WebhookURL string `yaml:"webhook_url"`
+	Channel    string `yaml:"channel,omitempty"`
+}
+
+// EmailV1 is the `notifications.email` section
+type EmailV1 struct {
+	Recipients []string `yaml:"recipients"`
+}
+
+// ScanV1 is the `scan` section
+type ScanV1 struct {
+	Paths        []string `yaml:"paths"`
+	ExcludePaths []string `yaml:"exclude_paths"`
+	Languages    []string `yaml:"languages"`
+	Timeout      int      `yaml:"timeout"`
+	ParallelJobs int      `yaml:"parallel_jobs"`
+}
+
+// CacheV1 is the `cache` section
+type CacheV1 struct {
+	Enabled bool `yaml:"enabled"`
+	TTL     int  `yaml:"ttl"`
+}
+
+// PopularityV1 is the `popularity` section
+type PopularityV1 struct {
+	Provider string `yaml:"provider"`
+	Snapshot string `yaml:"snapshot,omitempty"`
+	URL      string `yaml:"url,omitempty"`
+}
+
+// MaintenanceV1 is the `maintenance` section
+type MaintenanceV1 struct {
+	ScorecardResults string `yaml:"scorecard_results,omitempty"`
+	ReleaseMetadata  string `yaml:"release_metadata,omitempty"`
+}
+
+// HygieneV1 is the `hygiene` section
+type HygieneV1 struct {
+	Enabled             bool   `yaml:"enabled"`
+	GoProxy             string `yaml:"goproxy,omitempty"`
+	NPMRegistrySnapshot string `yaml:"npm_registry_snapshot,omitempty"`
+}
+
+// EOLV1 is the `eol` section
+type EOLV1 struct {
+	Enabled     bool   `yaml:"enabled"`
+	Dataset     string `yaml:"dataset,omitempty"`
+	WarningDays int    `yaml:"warning_days"`
+}
+
+// WorkflowsV1 is the `workflows` section
+type WorkflowsV1 struct {
+	Enabled       bool     `yaml:"enabled"`
+	Advisories    string   `yaml:"advisories,omitempty"`
+	RequireSHA    bool     `yaml:"require_sha"`
+	AllowedOwners []string `yaml:"allowed_owners,omitempty"`
+}
+
+// KEVV1 is the `kev` section
+type KEVV1 struct {
+	Enabled bool   `yaml:"enabled"`
+	Catalog string `yaml:"catalog,omitempty"`
+	URL     string `yaml:"url,omitempty"`
+}
+
+// PoliciesV1 is the `policies` section
+type PoliciesV1 struct {
+	Files []string `yaml:"files,omitempty"`
+}
+
+// File returns the configuration as a version 1 file
+func (c *Config) File() FileV1 {
+	return FileV1{
+		Version: CurrentVersion,
+		Extends: c.Extends,
+		Locked:  c.Locked,
+		Scoring: ScoringV1{
+			Model: c.Scoring.Model,
+			Weights: scorer.ScoringWeights{
+				CVSS:        c.CVSSWeight,
+				Popularity:  c.PopularityWeight,
+				Dependency:  c.DependencyWeight,
+				Context:     c.ContextWeight,
+				Maintenance: c.MaintenanceWeight,
+				Hygiene:     c.HygieneWeight,
+			},
+			Dependency:  c.DependencyScoring,
+			Modifiers:   c.Scoring.Modifiers,
+			Timing:      c.Scoring.Timing,
+			Rules:       c.Scoring.Rules,
+			Aggregation: c.Scoring.Aggregation,
+		},
+		Thresholds: ThresholdsV1{FailScore: c.FailThreshold, WarnScore: c.WarnThreshold},
+		Ignore:     IgnoreV1{Packages: c.IgnorePackages, CVEs: c.IgnoreList, Paths: c.IgnorePaths, Rules: c.IgnoreRules},
+		Notifications: NotificationsV1{
+			CommentMode:     c.CommentMode,
+			SarifUpload:     c.SarifUpload,
+			DashboardUpload: c.DashboardUpload,
+			Slack:           c.SlackNotification,
+			Email:           c.EmailNotification,
+		},
+		Scan: ScanV1{
+			Paths:        c.ScanPaths,
+			ExcludePaths: c.ExcludePaths,
+			Languages:    c.Languages,
+			Timeout:      c.Timeout,
+			ParallelJobs: c.ParallelJobs,
+		},
+		Cache:       CacheV1{Enabled: c.CacheEnabled, TTL: c.CacheTTL},
+		Context:     c.Context,
+		Popularity:  PopularityV1{Provider: c.PopularityProvider, Snapshot: c.PopularitySnapshot, URL: c.PopularityURL},
+		Maintenance: MaintenanceV1{ScorecardResults: c.ScorecardResults, ReleaseMetadata: c.ReleaseMetadata},
+		Hygiene:     HygieneV1{Enabled: c.HygieneEnabled, GoProxy: c.GoProxy, NPMRegistrySnapshot: c.NPMRegistrySnapshot},
+		EOL:         EOLV1{Enabled: c.EOLEnabled, Dataset: c.EOLDataset, WarningDays: c.EOLWarningDays},
+		Workflows: WorkflowsV1{
+			Enabled:       c.WorkflowScanEnabled,
+			Advisories:    c.WorkflowAdvisories,
+			RequireSHA:    c.WorkflowRequireSHA,
+			AllowedOwners: c.WorkflowAllowedOwners,
+		},
+		Overrides: c.Overrides,
+		KEV:       KEVV1{Enabled: c.KEVEnabled, Catalog: c.KEVCatalog, URL: c.KEVURL},
+		Gates:     c.Gates,
+		Policies:  PoliciesV1{Files: c.PolicyFiles},
+	}
+}
+
+// applyFile sets the configuration from a version 1 file
+func (c *Config) applyFile(file FileV1) {
+	c.Scoring = ScoringConfig{
+		Model:       file.Scoring.Model,
+		Modifiers:   file.Scoring.Modifiers,
+		Timing:      file.Scoring.Timing,
+		Rules:       file.Scoring.Rules,
+		Aggregation: file.Scoring.Aggregation,
+	}
+	c.CVSSWeight = file.Scoring.Weights.CVSS
+	c.PopularityWeight = file.Scoring.Weights.Popularity
+	c.DependencyWeight = file.Scoring.Weights.Dependency
+	c.ContextWeight = file.Scoring.Weights.Context
+	c.MaintenanceWeight = file.Scoring.Weights.Maintenance
+	c.HygieneWeight = file.Scoring.Weights.Hygiene
+	c.DependencyScoring = file.Scoring.Dependency
+
+	c.FailThreshold = file.Thresholds.FailScore
+	c.WarnThreshold = file.Thresholds.WarnScore
+
+	c.IgnorePackages = file.Ignore.Packages
+	c.IgnoreList = file.Ignore.CVEs
+	c.IgnorePaths = file.Ignore.Paths
+	c.IgnoreRules = file.Ignore.Rules
+
+	c.CommentMode = file.Notifications.CommentMode
+	c.SarifUpload = file.Notifications.SarifUpload
+	c.DashboardUpload = file.Notifications.DashboardUpload
+
+	c.ScanPaths = file.Scan.Paths
+	c.ExcludePaths = file.Scan.ExcludePaths
+	c.Languages = file.Scan.Languages
+	c.Timeout = file.Scan.Timeout
+	c.ParallelJobs = file.Scan.ParallelJobs
+	c.CacheEnabled = file.Cache.Enabled
+	c.CacheTTL = file.Cache.TTL
+
+	c.Context = file.Context
+	c.PopularityProvider = file.Popularity.Provider
+	c.PopularitySnapshot = file.Popularity.Snapshot
+	c.PopularityURL = file.Popularity.URL
+	c.ScorecardResults = file.Maintenance.ScorecardResults
+	c.ReleaseMetadata = file.Maintenance.ReleaseMetadata
+	c.HygieneEnabled = file.Hygiene.Enabled
+	c.GoProxy = file.Hygiene.GoProxy
+	c.NPMRegistrySnapshot = file.Hygiene.NPMRegistrySnapshot
+	c.EOLEnabled = file.EOL.Enabled
+	c.EOLDataset = file.EOL.Dataset
+	c.EOLWarningDays = file.EOL.WarningDays
+	c.WorkflowScanEnabled = file.Workflows.Enabled
+	c.WorkflowAdvisories = file.Workflows.Advisories
+	c.WorkflowRequireSHA = file.Workflows.RequireSHA
+	c.WorkflowAllowedOwners = file.Workflows.AllowedOwners
+	c.Overrides = file.Overrides
+	c.KEVEnabled = file.KEV.Enabled
+	c.KEVCatalog = file.KEV.Catalog
+	c.KEVURL = file.KEV.URL
+	c.Gates = file.Gates
+	c.PolicyFiles = file.Policies.Files
+	c.SlackNotification = file.Notifications.Slack
+	c.EmailNotification = file.Notifications.Email
+}
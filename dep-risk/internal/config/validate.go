@@ -0,0 +1,143 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Problem is an error in a configuration, with the line of the config file
+// it concerns when there is one
+type Problem struct {
+	Line    int
+	Message string
+}
+
+// String formats a problem as `line N: message`
+func (p Problem) String() string {
+	if p.Line > 0 {
+		return fmt.Sprintf("line %d: %s", p.Line, p.Message)
+	}
+	return p.Message
+}
+
+// Problems are the errors found in a configuration, ordered by line with
+// problems outside the config file last
+type Problems []Problem
+
+// Error joins the problems on one line
+func (p Problems) Error() string {
+	messages := make([]string, len(p))
+	for i, problem := range p {
+		messages[i] = problem.String()
+	}
+	return strings.Join(messages, "; ")
+}
+
+// sort orders problems by line
+func (p Problems) sort() {
+	sort.SliceStable(p, func(i, j int) bool {
+		if (p[i].Line == 0) != (p[j].Line == 0) {
+			return p[j].Line == 0
+		}
+		return p[i].Line < p[j].Line
+	})
+}
+
+// Validate loads a config file like LoadConfig and reports every problem in
+// the file and the action inputs instead of the first one. The error is set
+// when the file cannot be read or parsed at all.
+func Validate(path string) (Problems, error) {
+	cfg := DefaultConfig()
+	var problems Problems
+	if err := cfg.loadFromFile(path); err != nil {
+		if !errors.As(err, &problems) {
+			return nil, err
+		}
+	}
+	problems = append(problems, cfg.problems(cfg.loadFromEnv())...)
+	problems = append(problems, cfg.problems(cfg.validate())...)
+	problems.sort()
+	return problems, nil
+}
+
+// problems converts the errors joined in err to problems, locating each one
+// by the key its message starts with
+func (c *Config) problems(err error) Problems {
+	if err == nil {
+		return nil
+	}
+
+	switch err := err.(type) {
+	case Problems:
+		return err
+	case *yaml.TypeError:
+		return typeProblems(err)
+	case interface{ Unwrap() []error }:
+		var problems Problems
+		for _, err := range err.Unwrap() {
+			problems = append(problems, c.problems(err)...)
+		}
+		return problems
+	}
+
+	message := err.Error()
+	key, _, _ := strings.Cut(message, " ")
+	return Problems{{Line: c.keyLine(strings.TrimSuffix(key, ":")), Message: message}}
+}
+
+// keyLine returns the line of a dotted key in the config file, or of the
+// nearest enclosing key that is set
+func (c *Config) keyLine(key string) int {
+	for key != "" {
+		if line := c.lines[key]; line > 0 {
+			return line
+		}
+		cut := strings.LastIndexAny(key, ".[")
+		if cut < 0 {
+			break
+		}
+		key = key[:cut]
+	}
+	return 0
+}
+
+// keyLines records the line of every key and list entry of a config file
+func keyLines(node *yaml.Node, path string, lines map[string]int) {
+	switch node.Kind {
+	case yaml.MappingNode:
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			key := joinPath(path, node.Content[i].Value)
+			lines[key] = node.Content[i].Line
+			keyLines(node.Content[i+1], key, lines)
+		}
+	case yaml.SequenceNode:
+		for i, item := range node.Content {
+			key := fmt.Sprintf("%s[%d]", path, i)
+			lines[key] = item.Line
+			keyLines(item, key, lines)
+		}
+	}
+}
+
+// typeErrorLine splits the `line N: ` prefix off yaml decoding errors
+var typeErrorLine = regexp.MustCompile(`^line (\d+): (.*)$`)
+
+// typeProblems converts the values that failed to decode to problems
+func typeProblems(err *yaml.TypeError) Problems {
+	problems := make(Problems, 0, len(err.Errors))
+	for _, message := range err.Errors {
+		problem := Problem{Message: message}
+		if match := typeErrorLine.FindStringSubmatch(message); match != nil {
+			problem.Line, _ = strconv.Atoi(match[1])
+			problem.Message = match[2]
+		}
+		problems = append(problems, problem)
+	}
+	return problems
+}
@@ -100,6 +100,7 @@ func Migrate() error {
 		&models.Repository{},
 		&models.Scan{},
 		&models.Vulnerability{},
+		&models.Policy{},
 	)
 	if err != nil {
 		return fmt.Errorf("failed to migrate database: %w", err)
@@ -35,6 +35,8 @@ CREATE TABLE IF NOT EXISTS scans (
     medium_risk_count INTEGER DEFAULT 0,
     low_risk_count INTEGER DEFAULT 0,
     scan_duration INTEGER DEFAULT 0, -- seconds
+    model_version VARCHAR(20),
+    config_hash VARCHAR(64),
     created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
     updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
 );
@@ -51,10 +53,23 @@ CREATE TABLE IF NOT EXISTS vulnerabilities (
     severity VARCHAR(20),
     is_direct BOOLEAN DEFAULT FALSE,
     description TEXT,
+    explanation JSONB,
+    finding JSONB,
     created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
     updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
 );
 
+-- Policies table: shared base configs served to `extends: api:<org>/<name>`
+CREATE TABLE IF NOT EXISTS policies (
+    id SERIAL PRIMARY KEY,
+    org_id INTEGER NOT NULL REFERENCES organizations(id) ON DELETE CASCADE,
+    name VARCHAR(100) NOT NULL,
+    content TEXT,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    UNIQUE(org_id, name)
+);
+
 -- Performance indexes
 CREATE INDEX IF NOT EXISTS idx_organizations_github_org ON organizations(github_org);
 CREATE INDEX IF NOT EXISTS idx_repositories_org_id ON repositories(org_id);
@@ -0,0 +1,381 @@
+package eol
+
+import (
+	"bufio"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/dep-risk/dep-risk/internal/scanner"
+)
+
+// Runtime is a runtime or framework version declared in a project file
+type Runtime struct {
+	Product string `json:"product"`
+	Version string `json:"version"`
+	File    string `json:"file"`
+	Line    int    `json:"line"`
+}
+
+// detector extracts runtime declarations from the lines of a file
+type detector func(lines []string) []Runtime
+
+var (
+	versionPattern      = regexp.MustCompile(`\d+(?:\.\d+)*`)
+	goDirectivePattern  = regexp.MustCompile(`^go\s+(\d+(?:\.\d+)*)`)
+	nodeEnginePattern   = regexp.MustCompile(`"node"\s*:\s*"([^"]+)"`)
+	requiresPyPattern   = regexp.MustCompile(`^requires-python\s*=\s*["']([^"']+)["']`)
+	dockerFromPattern   = regexp.MustCompile(`(?i)^FROM\s+(?:--\S+\s+)*(\S+)`)
+	pomVersionPattern   = regexp.MustCompile(`<version>\s*([^<\s]+)\s*</version>`)
+	gradleBootPattern   = regexp.MustCompile(`org\.springframework\.boot["']\)?\s+version\s+["']([^"']+)["']`)
+	toolVersionsPattern = regexp.MustCompile(`^(\S+)\s+(\S+)`)
+	constraintPattern   = regexp.MustCompile(`(~=|===|==|>=|<=|!=|[<>=^~]|)\s*v?(\d+(?:\.\d+)*)`)
+)
+
+// dockerImageProducts maps official image names to dataset products
+var dockerImageProducts = map[string]string{
+	"node":            "nodejs",
+	"python":          "python",
+	"golang":          "go",
+	"openjdk":         "java",
+	"eclipse-temurin": "java",
+	"amazoncorretto":  "java",
+	"ruby":            "ruby",
+	"php":             "php",
+}
+
+// toolVersionsProducts maps asdf plugin names to dataset products
+var toolVersionsProducts = map[string]string{
+	"nodejs": "nodejs",
+	"python": "python",
+	"golang": "go",
+	"java":   "java",
+	"ruby":   "ruby",
+	"php":    "php",
+}
+
+// detectorFor returns the detector for a file name, if any
+func detectorFor(name string) detector {
+	switch name {
+	case "go.mod":
+		return detectGoMod
+	case ".nvmrc", ".node-version":
+		return versionFileDetector("nodejs")
+	case ".python-version", "runtime.txt":
+		return versionFileDetector("python")
+	case ".ruby-version":
+		return versionFileDetector("ruby")
+	case ".java-version":
+		return versionFileDetector("java")
+	case ".tool-versions":
+		return detectToolVersions
+	case "package.json":
+		return detectNodeEngines
+	case "pyproject.toml":
+		return detectRequiresPython
+	case "pom.xml":
+		return detectSpringBootPom
+	case "build.gradle", "build.gradle.kts":
+		return detectSpringBootGradle
+	}
+
+	if name == "Dockerfile" || name == "Containerfile" ||
+		strings.HasPrefix(name, "Dockerfile.") || strings.HasSuffix(name, ".Dockerfile") {
+		return detectDockerfile
+	}
+	return nil
+}
+
+// DetectRuntimes walks a project and collects declared runtime and framework versions
+func DetectRuntimes(root string, excludePaths []string) ([]Runtime, []scanner.Diagnostic) {
+	excluded := make(map[string]bool)
+	for _, path := range excludePaths {
+		excluded[strings.Trim(path, "/")] = true
+	}
+
+	var runtimes []Runtime
+	var diagnostics []scanner.Diagnostic
+
+	filepath.WalkDir(root, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return nil
+		}
+		rel, _ := filepath.Rel(root, path)
+		if entry.IsDir() {
+			if path != root && (excluded[entry.Name()] || excluded[filepath.ToSlash(rel)]) {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+
+		detect := detectorFor(entry.Name())
+		if detect == nil {
+			return nil
+		}
+
+		lines, err := readLines(path)
+		if err != nil {
+			diagnostics = append(diagnostics, scanner.Diagnostic{
+				Tool:    "dep-risk",
+				Kind:    scanner.DiagnosticWarning,
+				Message: fmt.Sprintf("EOL check could not read %s: %v", rel, err),
+				Path:    filepath.ToSlash(rel),
+			})
+			return nil
+		}
+
+		for _, runtime := range detect(lines) {
+			runtime.File = filepath.ToSlash(rel)
+			runtimes = append(runtimes, runtime)
+		}
+		return nil
+	})
+
+	return runtimes, diagnostics
+}
+
+// readLines reads a file as a slice of lines
+func readLines(path string) ([]string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	var lines []string
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		lines = append(lines, scanner.Text())
+	}
+	return lines, scanner.Err()
+}
+
+// normalizeVersion extracts the leading numeric version from a version string or range
+func normalizeVersion(product, raw string) string {
+	version := versionPattern.FindString(raw)
+	// Java 8 and earlier are also written as 1.8
+	if product == "java" && strings.HasPrefix(version, "1.") {
+		version = strings.TrimPrefix(version, "1.")
+	}
+	return version
+}
+
+// runtimeAt builds a runtime declaration for a 0-based line index
+func runtimeAt(product, raw string, index int) []Runtime {
+	version := normalizeVersion(product, raw)
+	if version == "" {
+		return nil
+	}
+	return []Runtime{{Product: product, Version: version, Line: index + 1}}
+}
+
+// versionFileDetector reads single-version files such as .nvmrc
+func versionFileDetector(product string) detector {
+	return func(lines []string) []Runtime {
+		for i, line := range lines {
+			line = strings.TrimSpace(line)
+			if line == "" || strings.HasPrefix(line, "#") {
+				continue
+			}
+			// Aliases such as lts/hydrogen do not name a version
+			if strings.Contains(line, "/") {
+				return nil
+			}
+			return runtimeAt(product, line, i)
+		}
+		return nil
+	}
+}
+
+// detectGoMod reads the go directive of a go.mod file
+func detectGoMod(lines []string) []Runtime {
+	for i, line := range lines {
+		if match := goDirectivePattern.FindStringSubmatch(strings.TrimSpace(line)); match != nil {
+			return runtimeAt("go", match[1], i)
+		}
+	}
+	return nil
+}
+
+// detectToolVersions reads an asdf .tool-versions file
+func detectToolVersions(lines []string) []Runtime {
+	var runtimes []Runtime
+	for i, line := range lines {
+		match := toolVersionsPattern.FindStringSubmatch(strings.TrimSpace(line))
+		if match == nil {
+			continue
+		}
+		if product, ok := toolVersionsProducts[match[1]]; ok {
+			runtimes = append(runtimes, runtimeAt(product, match[2], i)...)
+		}
+	}
+	return runtimes
+}
+
+// detectNodeEngines reads the newest Node.js version package.json engines
+// allows, when the range has an upper bound
+func detectNodeEngines(lines []string) []Runtime {
+	inEngines := false
+	for i, line := range lines {
+		if strings.Contains(line, `"engines"`) {
+			inEngines = true
+		}
+		if !inEngines {
+			continue
+		}
+		if match := nodeEnginePattern.FindStringSubmatch(line); match != nil {
+			return runtimeAt("nodejs", rangeCeiling(match[1]), i)
+		}
+		if strings.Contains(line, "}") {
+			inEngines = false
+		}
+	}
+	return nil
+}
+
+// detectRequiresPython reads the newest Python version requires-python in
+// pyproject.toml allows, when the range has an upper bound
+func detectRequiresPython(lines []string) []Runtime {
+	for i, line := range lines {
+		if match := requiresPyPattern.FindStringSubmatch(strings.TrimSpace(line)); match != nil {
+			return runtimeAt("python", rangeCeiling(match[1]), i)
+		}
+	}
+	return nil
+}
+
+// rangeCeiling returns the newest version line an npm or PEP 440 range
+// allows, or "" when the range has no upper bound. A range such as >=16
+// accepts supported releases, so only exact pins and capped ranges can keep a
+// project on an end-of-life version.
+func rangeCeiling(raw string) string {
+	ceiling := ""
+	for _, alternative := range strings.Split(raw, "||") {
+		// Hyphen ranges such as 14 - 16 include their upper end
+		if _, upper, ok := strings.Cut(alternative, " - "); ok {
+			alternative = "<=" + upper
+		}
+		bound := ""
+		for _, match := range constraintPattern.FindAllStringSubmatch(alternative, -1) {
+			if upper := upperBound(match[1], match[2]); upper != "" &&
+				(bound == "" || scanner.CompareVersions(upper, bound) < 0) {
+				bound = upper
+			}
+		}
+		// One open-ended alternative makes the whole range open-ended
+		if bound == "" {
+			return ""
+		}
+		if ceiling == "" || scanner.CompareVersions(bound, ceiling) > 0 {
+			ceiling = bound
+		}
+	}
+	return ceiling
+}
+
+// upperBound returns the newest version line a single constraint allows, or
+// "" for lower bounds and exclusions
+func upperBound(operator, version string) string {
+	segments := strings.Split(version, ".")
+	switch operator {
+	case ">", ">=", "!=":
+		return ""
+	case "<":
+		return previousVersion(segments)
+	case "^":
+		// The first non-zero segment is fixed
+		for i, segment := range segments {
+			if segment != "0" {
+				return strings.Join(segments[:i+1], ".")
+			}
+		}
+		return version
+	case "~":
+		if len(segments) > 1 {
+			return strings.Join(segments[:2], ".")
+		}
+		return version
+	case "~=":
+		if len(segments) > 1 {
+			return strings.Join(segments[:len(segments)-1], ".")
+		}
+		return ""
+	}
+	// Exact versions, <= and wildcards such as 16.x or 3.8.*
+	return version
+}
+
+// previousVersion returns the version line just below an exclusive bound,
+// e.g. 3.8 for <3.9 and 16 for <17.0.0
+func previousVersion(segments []string) string {
+	for len(segments) > 1 && segments[len(segments)-1] == "0" {
+		segments = segments[:len(segments)-1]
+	}
+	last, err := strconv.Atoi(segments[len(segments)-1])
+	if err != nil || last == 0 {
+		return ""
+	}
+	segments = append(segments[:len(segments)-1:len(segments)-1], strconv.Itoa(last-1))
+	return strings.Join(segments, ".")
+}
+
+// detectDockerfile reads official runtime base images from FROM lines
+func detectDockerfile(lines []string) []Runtime {
+	var runtimes []Runtime
+	for i, line := range lines {
+		match := dockerFromPattern.FindStringSubmatch(strings.TrimSpace(line))
+		if match == nil {
+			continue
+		}
+
+		image := match[1]
+		if at := strings.Index(image, "@"); at >= 0 {
+			image = image[:at]
+		}
+		name, tag := image, ""
+		if colon := strings.LastIndex(image, ":"); colon > strings.LastIndex(image, "/") {
+			name, tag = image[:colon], image[colon+1:]
+		}
+		name = name[strings.LastIndex(name, "/")+1:]
+
+		product, ok := dockerImageProducts[name]
+		// Tags such as latest or alpine do not pin a version
+		if !ok || tag == "" || tag[0] < '0' || tag[0] > '9' {
+			continue
+		}
+		runtimes = append(runtimes, runtimeAt(product, tag, i)...)
+	}
+	return runtimes
+}
+
+// detectSpringBootPom reads the Spring Boot version from a Maven parent or BOM import
+func detectSpringBootPom(lines []string) []Runtime {
+	for i, line := range lines {
+		if !strings.Contains(line, "<artifactId>spring-boot-starter-parent</artifactId>") &&
+			!strings.Contains(line, "<artifactId>spring-boot-dependencies</artifactId>") {
+			continue
+		}
+		// The version element follows the artifactId within the same block
+		for j := i; j < len(lines) && j <= i+3; j++ {
+			if match := pomVersionPattern.FindStringSubmatch(lines[j]); match != nil {
+				return runtimeAt("spring-boot", match[1], j)
+			}
+		}
+	}
+	return nil
+}
+
+// detectSpringBootGradle reads the Spring Boot plugin version from a Gradle build
+func detectSpringBootGradle(lines []string) []Runtime {
+	for i, line := range lines {
+		if match := gradleBootPattern.FindStringSubmatch(line); match != nil {
+			return runtimeAt("spring-boot", match[1], i)
+		}
+	}
+	return nil
+}
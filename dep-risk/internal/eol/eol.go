@@ -0,0 +1,173 @@
+package eol
+
+import (
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/dep-risk/dep-risk/internal/scanner"
+)
+
+// bundledDataset is the end-of-life dataset shipped with dep-risk
+//
+//go:embed data/eol.json
+var bundledDataset []byte
+
+// Cycle is a release line of a product and the date it stops receiving security fixes
+type Cycle struct {
+	Cycle string `json:"cycle"`
+	EOL   string `json:"eol"`
+}
+
+// Product lists the release cycles of a runtime or framework
+type Product struct {
+	Label  string  `json:"label"`
+	Cycles []Cycle `json:"cycles"`
+}
+
+// Dataset maps product identifiers to their release cycles
+type Dataset struct {
+	Updated  string             `json:"updated"`
+	Products map[string]Product `json:"products"`
+}
+
+// LoadDataset reads an end-of-life dataset, falling back to the bundled one when path is empty
+func LoadDataset(path string) (*Dataset, error) {
+	data := bundledDataset
+	if path != "" {
+		var err error
+		data, err = os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read EOL dataset: %w", err)
+		}
+	}
+
+	var dataset Dataset
+	if err := json.Unmarshal(data, &dataset); err != nil {
+		return nil, fmt.Errorf("failed to parse EOL dataset: %w", err)
+	}
+
+	for name, product := range dataset.Products {
+		for _, cycle := range product.Cycles {
+			if _, err := time.Parse("2006-01-02", cycle.EOL); err != nil {
+				return nil, fmt.Errorf("invalid EOL date %q for %s %s", cycle.EOL, name, cycle.Cycle)
+			}
+		}
+	}
+
+	return &dataset, nil
+}
+
+// Lookup finds the release cycle that a version belongs to
+func (d *Dataset) Lookup(product, version string) (Cycle, bool) {
+	var match Cycle
+	found := false
+	for _, cycle := range d.Products[product].Cycles {
+		if version != cycle.Cycle && !strings.HasPrefix(version, cycle.Cycle+".") {
+			continue
+		}
+		// Prefer the most specific cycle, e.g. 3.10 over 3
+		if !found || len(cycle.Cycle) > len(match.Cycle) {
+			match = cycle
+			found = true
+		}
+	}
+	return match, found
+}
+
+// supportedCycles lists the cycles of a product still supported at a given time
+func (d *Dataset) supportedCycles(product string, now time.Time) []string {
+	var cycles []string
+	for _, cycle := range d.Products[product].Cycles {
+		if eolDate, err := time.Parse("2006-01-02", cycle.EOL); err == nil && eolDate.After(now) {
+			cycles = append(cycles, cycle.Cycle)
+		}
+	}
+	return cycles
+}
+
+// Options configures the end-of-life check
+type Options struct {
+	// WarningDays reports runtimes reaching end of life within this many days
+	WarningDays int
+
+	// ExcludePaths lists directory names that are not searched for version files
+	ExcludePaths []string
+}
+
+// Checker detects runtime and framework versions that no longer receive security fixes
+type Checker struct {
+	WorkingDir string
+	Dataset    *Dataset
+	Options    Options
+
+	now func() time.Time
+}
+
+// NewChecker creates a new end-of-life checker for a project directory
+func NewChecker(workingDir string, dataset *Dataset, options Options) *Checker {
+	return &Checker{
+		WorkingDir: workingDir,
+		Dataset:    dataset,
+		Options:    options,
+		now:        time.Now,
+	}
+}
+
+// Check detects runtime versions and reports the ones past or near end of life
+func (c *Checker) Check() ([]scanner.Vulnerability, []scanner.Diagnostic) {
+	runtimes, diagnostics := DetectRuntimes(c.WorkingDir, c.Options.ExcludePaths)
+
+	now := c.now()
+	var findings []scanner.Vulnerability
+	for _, runtime := range runtimes {
+		cycle, ok := c.Dataset.Lookup(runtime.Product, runtime.Version)
+		if !ok {
+			continue
+		}
+
+		eolDate, _ := time.Parse("2006-01-02", cycle.EOL)
+		daysLeft := int(eolDate.Sub(now).Hours() / 24)
+
+		label := c.Dataset.Products[runtime.Product].Label
+		if label == "" {
+			label = runtime.Product
+		}
+
+		var severity, summary string
+		switch {
+		case !eolDate.After(now):
+			severity = "HIGH"
+			summary = fmt.Sprintf("%s %s reached end of life on %s and no longer receives security fixes", label, cycle.Cycle, cycle.EOL)
+		case daysLeft <= c.Options.WarningDays:
+			severity = "LOW"
+			summary = fmt.Sprintf("%s %s reaches end of life on %s (%d days left)", label, cycle.Cycle, cycle.EOL, daysLeft)
+		default:
+			continue
+		}
+
+		description := fmt.Sprintf("%s declares %s %s.", runtime.File, label, runtime.Version)
+		if supported := c.Dataset.supportedCycles(runtime.Product, now); len(supported) > 0 {
+			description += fmt.Sprintf(" Supported release lines: %s.", strings.Join(supported, ", "))
+		}
+
+		findings = append(findings, scanner.Vulnerability{
+			ID:          fmt.Sprintf("EOL-%s-%s", runtime.Product, cycle.Cycle),
+			Package:     runtime.Product,
+			Version:     runtime.Version,
+			Severity:    severity,
+			Summary:     summary,
+			Description: description,
+			References:  []string{fmt.Sprintf("https://endoflife.date/%s", runtime.Product)},
+			IsDirect:    true,
+			Class:       scanner.ClassEOL,
+			File:        runtime.File,
+			Line:        runtime.Line,
+		})
+	}
+
+	return findings, diagnostics
+}
@@ -0,0 +1,169 @@
+package eol
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/dep-risk/dep-risk/internal/scanner"
+)
+
+// writeFile creates a file and its parent directories for a test fixture
+func writeFile(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatalf("Failed to create directory: %v", err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatalf("Failed to write %s: %v", path, err)
+	}
+}
+
+func TestDatasetLookup(t *testing.T) {
+	dataset, err := LoadDataset("")
+	if err != nil {
+		t.Fatalf("Failed to load bundled dataset: %v", err)
+	}
+
+	tests := []struct {
+		product string
+		version string
+		cycle   string
+		found   bool
+	}{
+		{"python", "3.10.4", "3.10", true},
+		{"python", "3.1", "", false},
+		{"nodejs", "18.19.0", "18", true},
+		{"go", "1.21", "1.21", true},
+		{"cobol", "1", "", false},
+	}
+
+	for _, tt := range tests {
+		cycle, found := dataset.Lookup(tt.product, tt.version)
+		if found != tt.found || cycle.Cycle != tt.cycle {
+			t.Errorf("Lookup(%s, %s) = %q, %v; want %q, %v", tt.product, tt.version, cycle.Cycle, found, tt.cycle, tt.found)
+		}
+	}
+}
+
+func TestLoadDatasetRejectsInvalidDates(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "eol.json")
+	writeFile(t, path, `{"products": {"nodejs": {"cycles": [{"cycle": "18", "eol": "soon"}]}}}`)
+
+	if _, err := LoadDataset(path); err == nil {
+		t.Error("Expected an error for an invalid EOL date")
+	}
+}
+
+func TestDetectRuntimes(t *testing.T) {
+	projectDir := t.TempDir()
+	writeFile(t, filepath.Join(projectDir, "go.mod"), "module example.com/app\n\ngo 1.20\n")
+	writeFile(t, filepath.Join(projectDir, ".nvmrc"), "v16.20.2\n")
+	writeFile(t, filepath.Join(projectDir, "Dockerfile"), `FROM --platform=linux/amd64 golang:1.20-alpine AS build
+FROM build AS test
+FROM python:latest
+FROM eclipse-temurin:1.8-jre
+`)
+	writeFile(t, filepath.Join(projectDir, "service/pom.xml"), `<parent>
+  <groupId>org.springframework.boot</groupId>
+  <artifactId>spring-boot-starter-parent</artifactId>
+  <version>2.7.18</version>
+</parent>`)
+	writeFile(t, filepath.Join(projectDir, "node_modules/lib/.nvmrc"), "10\n")
+
+	runtimes, diagnostics := DetectRuntimes(projectDir, []string{"node_modules"})
+	if len(diagnostics) != 0 {
+		t.Errorf("Expected no diagnostics, got %+v", diagnostics)
+	}
+
+	found := make(map[string]Runtime)
+	for _, runtime := range runtimes {
+		found[runtime.File+":"+runtime.Product] = runtime
+	}
+
+	expected := map[string]Runtime{
+		"go.mod:go":                   {Version: "1.20", Line: 3},
+		".nvmrc:nodejs":               {Version: "16.20.2", Line: 1},
+		"Dockerfile:go":               {Version: "1.20", Line: 1},
+		"Dockerfile:java":             {Version: "8", Line: 4},
+		"service/pom.xml:spring-boot": {Version: "2.7.18", Line: 4},
+	}
+	if len(runtimes) != len(expected) {
+		t.Errorf("Expected %d runtimes, got %+v", len(expected), runtimes)
+	}
+	for key, want := range expected {
+		got, ok := found[key]
+		if !ok {
+			t.Errorf("Expected runtime %s to be detected", key)
+			continue
+		}
+		if got.Version != want.Version || got.Line != want.Line {
+			t.Errorf("%s: got version %s line %d, want version %s line %d", key, got.Version, got.Line, want.Version, want.Line)
+		}
+	}
+}
+
+func TestRangeCeiling(t *testing.T) {
+	tests := []struct {
+		raw  string
+		want string
+	}{
+		// Lower bounds accept supported releases
+		{">=16", ""},
+		{">=3.7", ""},
+		{"*", ""},
+		{"^14 || >=16", ""},
+		// Exact pins and capped ranges
+		{"16", "16"},
+		{"16.x", "16"},
+		{"==3.8.*", "3.8"},
+		{"^16.2.0", "16"},
+		{"~16.2.0", "16.2"},
+		{"~=3.8.1", "3.8"},
+		{">=14 <17", "16"},
+		{">=3.7, <3.10", "3.9"},
+		{"<=18", "18"},
+		{"^14 || ^16", "16"},
+		{"14 - 16", "16"},
+	}
+	for _, tt := range tests {
+		if got := rangeCeiling(tt.raw); got != tt.want {
+			t.Errorf("rangeCeiling(%q) = %q, want %q", tt.raw, got, tt.want)
+		}
+	}
+}
+
+func TestCheck(t *testing.T) {
+	projectDir := t.TempDir()
+	writeFile(t, filepath.Join(projectDir, ".tool-versions"), "nodejs 16.20.2\npython 3.12.1\ngolang 1.23.4\n")
+
+	dataset := &Dataset{Products: map[string]Product{
+		"nodejs": {Label: "Node.js", Cycles: []Cycle{{Cycle: "16", EOL: "2023-09-11"}}},
+		"python": {Label: "Python", Cycles: []Cycle{{Cycle: "3.12", EOL: "2024-07-01"}}},
+		"go":     {Label: "Go", Cycles: []Cycle{{Cycle: "1.23", EOL: "2026-08-01"}}},
+	}}
+
+	checker := NewChecker(projectDir, dataset, Options{WarningDays: 90})
+	checker.now = func() time.Time { return time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC) }
+
+	findings, _ := checker.Check()
+	if len(findings) != 2 {
+		t.Fatalf("Expected 2 findings, got %+v", findings)
+	}
+
+	bySeverity := make(map[string]scanner.Vulnerability)
+	for _, finding := range findings {
+		if finding.Class != scanner.ClassEOL {
+			t.Errorf("Expected class %s, got %s", scanner.ClassEOL, finding.Class)
+		}
+		bySeverity[finding.Severity] = finding
+	}
+
+	if node := bySeverity["HIGH"]; node.ID != "EOL-nodejs-16" || node.File != ".tool-versions" || node.Line != 1 {
+		t.Errorf("Unexpected past end-of-life finding: %+v", node)
+	}
+	if python := bySeverity["LOW"]; python.ID != "EOL-python-3.12" || python.Line != 2 {
+		t.Errorf("Unexpected upcoming end-of-life finding: %+v", python)
+	}
+}
@@ -0,0 +1,280 @@
+// Package expr implements the small expression language used by custom
+// scoring rules. Expressions are parsed and type-checked against declared
+// variables up front, so evaluation cannot fail.
+//
+// The language supports boolean, number and string values, string lists,
+// the operators ||, &&, !, ==, !=, <, <=, >, >=, unary -, `in` (membership
+// in a list) and `matches` (glob match), and parentheses. String
+// comparisons are case-insensitive.
+package expr
+
+import (
+	"fmt"
+	"path"
+	"strings"
+)
+
+// Type is the type of a value or variable
+type Type int
+
+const (
+	TypeBool Type = iota
+	TypeNumber
+	TypeString
+	TypeList
+)
+
+// String returns the name of the type
+func (t Type) String() string {
+	switch t {
+	case TypeBool:
+		return "bool"
+	case TypeNumber:
+		return "number"
+	case TypeString:
+		return "string"
+	case TypeList:
+		return "list"
+	default:
+		return "unknown"
+	}
+}
+
+// Env declares the variables an expression may reference
+type Env map[string]Type
+
+// Values holds variable values: bool, float64, string or []string matching
+// the declared types. Missing variables evaluate to their zero value.
+type Values map[string]interface{}
+
+// Program is a compiled boolean expression
+type Program struct {
+	source string
+	root   node
+}
+
+// Compile parses an expression and checks that it is a well-typed boolean
+// expression over the variables in env
+func Compile(source string, env Env) (*Program, error) {
+	tokens, err := lex(source)
+	if err != nil {
+		return nil, err
+	}
+
+	p := &parser{tokens: tokens}
+	root, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	if tok := p.peek(); tok.kind != tokenEOF {
+		return nil, fmt.Errorf("column %d: unexpected %q", tok.pos+1, tok.text)
+	}
+
+	typ, err := root.check(env)
+	if err != nil {
+		return nil, err
+	}
+	if typ != TypeBool {
+		return nil, fmt.Errorf("expression must be a bool, got %s", typ)
+	}
+
+	return &Program{source: source, root: root}, nil
+}
+
+// String returns the source of the expression
+func (p *Program) String() string {
+	return p.source
+}
+
+// Eval evaluates the expression
+func (p *Program) Eval(values Values) bool {
+	return p.root.eval(values).(bool)
+}
+
+// References reports whether the expression reads a variable
+func (p *Program) References(name string) bool {
+	return references(p.root, name)
+}
+
+// references walks an expression tree looking for a variable
+func references(n node, name string) bool {
+	switch n := n.(type) {
+	case *variable:
+		return n.name == name
+	case unary:
+		return references(n.operand, name)
+	case binary:
+		return references(n.left, name) || references(n.right, name)
+	}
+	return false
+}
+
+// node is an expression tree node
+type node interface {
+	check(env Env) (Type, error)
+	eval(values Values) interface{}
+}
+
+type literal struct {
+	typ   Type
+	value interface{}
+}
+
+func (n literal) check(env Env) (Type, error)    { return n.typ, nil }
+func (n literal) eval(values Values) interface{} { return n.value }
+
+type variable struct {
+	name string
+	pos  int
+	typ  Type
+}
+
+func (n *variable) check(env Env) (Type, error) {
+	typ, ok := env[n.name]
+	if !ok {
+		return 0, fmt.Errorf("column %d: unknown field %q", n.pos+1, n.name)
+	}
+	n.typ = typ
+	return typ, nil
+}
+
+func (n *variable) eval(values Values) interface{} {
+	if value, ok := values[n.name]; ok && value != nil {
+		return value
+	}
+	switch n.typ {
+	case TypeBool:
+		return false
+	case TypeNumber:
+		return 0.0
+	case TypeString:
+		return ""
+	default:
+		return []string(nil)
+	}
+}
+
+type unary struct {
+	op      string
+	pos     int
+	operand node
+}
+
+func (n unary) check(env Env) (Type, error) {
+	typ, err := n.operand.check(env)
+	if err != nil {
+		return 0, err
+	}
+	want := TypeBool
+	if n.op == "-" {
+		want = TypeNumber
+	}
+	if typ != want {
+		return 0, fmt.Errorf("column %d: %s needs a %s operand, got %s", n.pos+1, n.op, want, typ)
+	}
+	return want, nil
+}
+
+func (n unary) eval(values Values) interface{} {
+	value := n.operand.eval(values)
+	if n.op == "-" {
+		return -value.(float64)
+	}
+	return !value.(bool)
+}
+
+type binary struct {
+	op          string
+	pos         int
+	left, right node
+}
+
+func (n binary) check(env Env) (Type, error) {
+	left, err := n.left.check(env)
+	if err != nil {
+		return 0, err
+	}
+	right, err := n.right.check(env)
+	if err != nil {
+		return 0, err
+	}
+
+	mismatch := func() error {
+		return fmt.Errorf("column %d: cannot apply %s to %s and %s", n.pos+1, n.op, left, right)
+	}
+
+	switch n.op {
+	case "&&", "||":
+		if left != TypeBool || right != TypeBool {
+			return 0, mismatch()
+		}
+	case "==", "!=":
+		if left != right || left == TypeList {
+			return 0, mismatch()
+		}
+	case "<", "<=", ">", ">=":
+		if left != TypeNumber || right != TypeNumber {
+			return 0, mismatch()
+		}
+	case "in":
+		if left != TypeString || right != TypeList {
+			return 0, mismatch()
+		}
+	case "matches":
+		if left != TypeString || right != TypeString {
+			return 0, mismatch()
+		}
+		pattern, ok := n.right.(literal)
+		if !ok {
+			return 0, fmt.Errorf("column %d: matches needs a string literal pattern", n.pos+1)
+		}
+		if _, err := path.Match(pattern.value.(string), ""); err != nil {
+			return 0, fmt.Errorf("column %d: invalid pattern %q: %w", n.pos+1, pattern.value, err)
+		}
+	}
+	return TypeBool, nil
+}
+
+func (n binary) eval(values Values) interface{} {
+	switch n.op {
+	case "&&":
+		return n.left.eval(values).(bool) && n.right.eval(values).(bool)
+	case "||":
+		return n.left.eval(values).(bool) || n.right.eval(values).(bool)
+	}
+
+	left, right := n.left.eval(values), n.right.eval(values)
+	switch n.op {
+	case "==":
+		return equal(left, right)
+	case "!=":
+		return !equal(left, right)
+	case "<":
+		return left.(float64) < right.(float64)
+	case "<=":
+		return left.(float64) <= right.(float64)
+	case ">":
+		return left.(float64) > right.(float64)
+	case ">=":
+		return left.(float64) >= right.(float64)
+	case "in":
+		for _, item := range right.([]string) {
+			if strings.EqualFold(item, left.(string)) {
+				return true
+			}
+		}
+		return false
+	case "matches":
+		matched, _ := path.Match(strings.ToLower(right.(string)), strings.ToLower(left.(string)))
+		return matched
+	}
+	return false
+}
+
+// equal compares two values of the same type
+func equal(left, right interface{}) bool {
+	if l, ok := left.(string); ok {
+		return strings.EqualFold(l, right.(string))
+	}
+	return left == right
+}
@@ -0,0 +1,109 @@
+package expr
+
+import (
+	"strings"
+	"testing"
+)
+
+var testEnv = Env{
+	"package":           TypeString,
+	"severity":          TypeString,
+	"depth":             TypeNumber,
+	"is_direct":         TypeBool,
+	"context.exposed":   TypeBool,
+	"context.execution": TypeList,
+}
+
+func TestEval(t *testing.T) {
+	values := Values{
+		"package":           "@acme/http-client",
+		"severity":          "HIGH",
+		"depth":             3.0,
+		"is_direct":         false,
+		"context.exposed":   true,
+		"context.execution": []string{"server", "library"},
+	}
+
+	tests := []struct {
+		source   string
+		expected bool
+	}{
+		{"true", true},
+		{"severity == 'high'", true},
+		{`severity != "HIGH"`, false},
+		{"depth >= 3 && !is_direct", true},
+		{"depth > -1 && depth < 2", false},
+		{"is_direct || context.exposed", true},
+		{"!(is_direct || context.exposed)", false},
+		{"'server' in context.execution", true},
+		{"severity in ['CRITICAL', 'MEDIUM']", false},
+		{"package matches '@acme/*'", true},
+		{"package matches 'lodash*'", false},
+		{"severity in []", false},
+	}
+
+	for _, tt := range tests {
+		program, err := Compile(tt.source, testEnv)
+		if err != nil {
+			t.Errorf("Compile(%q) failed: %v", tt.source, err)
+			continue
+		}
+		if result := program.Eval(values); result != tt.expected {
+			t.Errorf("Eval(%q) = %v, expected %v", tt.source, result, tt.expected)
+		}
+	}
+}
+
+func TestEvalMissingValues(t *testing.T) {
+	program, err := Compile("depth == 0 && package == '' && !is_direct", testEnv)
+	if err != nil {
+		t.Fatalf("Compile failed: %v", err)
+	}
+	if !program.Eval(Values{}) {
+		t.Error("Expected missing values to evaluate to zero values")
+	}
+}
+
+func TestReferences(t *testing.T) {
+	program, err := Compile("!(is_direct || 'server' in context.execution) && depth > -1", testEnv)
+	if err != nil {
+		t.Fatalf("Compile failed: %v", err)
+	}
+	for _, name := range []string{"is_direct", "context.execution", "depth"} {
+		if !program.References(name) {
+			t.Errorf("Expected a reference to %s", name)
+		}
+	}
+	if program.References("package") || program.References("server") {
+		t.Error("Expected no reference to package or the string literal")
+	}
+}
+
+func TestCompileErrors(t *testing.T) {
+	tests := []struct {
+		source string
+		errMsg string
+	}{
+		{"", "unexpected end of expression"},
+		{"severity", "must be a bool"},
+		{"scope == 'dev'", `unknown field "scope"`},
+		{"depth == 'three'", "cannot apply == to number and string"},
+		{"severity > 2", "cannot apply >"},
+		{"is_direct && depth", "cannot apply &&"},
+		{"!depth", "! needs a bool operand"},
+		{"package matches severity", "string literal pattern"},
+		{"package matches '[a-'", "invalid pattern"},
+		{"severity in [1, 2]", "lists may only contain strings"},
+		{"(is_direct", `expected ")"`},
+		{"severity == 'HIGH", "unterminated string"},
+		{"depth = 2", "column 7: unexpected character"},
+		{"is_direct is_direct", `column 11: unexpected "is_direct"`},
+	}
+
+	for _, tt := range tests {
+		_, err := Compile(tt.source, testEnv)
+		if err == nil || !strings.Contains(err.Error(), tt.errMsg) {
+			t.Errorf("Compile(%q): expected error containing %q, got %v", tt.source, tt.errMsg, err)
+		}
+	}
+}
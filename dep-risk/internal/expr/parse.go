@@ -0,0 +1,265 @@
+package expr
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+type tokenKind int
+
+const (
+	tokenEOF tokenKind = iota
+	tokenIdent
+	tokenNumber
+	tokenString
+	tokenOperator
+)
+
+type token struct {
+	kind tokenKind
+	text string
+	pos  int
+}
+
+// operators are matched longest first
+var operators = []string{"&&", "||", "==", "!=", "<=", ">=", "<", ">", "!", "-", "(", ")", "[", "]", ","}
+
+// lex splits an expression into tokens
+func lex(source string) ([]token, error) {
+	var tokens []token
+	runes := []rune(source)
+
+	for i := 0; i < len(runes); {
+		r := runes[i]
+		switch {
+		case unicode.IsSpace(r):
+			i++
+
+		case unicode.IsLetter(r) || r == '_':
+			start := i
+			for i < len(runes) && (unicode.IsLetter(runes[i]) || unicode.IsDigit(runes[i]) || runes[i] == '_' || runes[i] == '.') {
+				i++
+			}
+			tokens = append(tokens, token{kind: tokenIdent, text: string(runes[start:i]), pos: start})
+
+		case unicode.IsDigit(r):
+			start := i
+			for i < len(runes) && (unicode.IsDigit(runes[i]) || runes[i] == '.') {
+				i++
+			}
+			tokens = append(tokens, token{kind: tokenNumber, text: string(runes[start:i]), pos: start})
+
+		case r == '\'' || r == '"':
+			start := i
+			var text strings.Builder
+			for i++; i < len(runes) && runes[i] != r; i++ {
+				if runes[i] == '\\' && i+1 < len(runes) {
+					i++
+				}
+				text.WriteRune(runes[i])
+			}
+			if i >= len(runes) {
+				return nil, fmt.Errorf("column %d: unterminated string", start+1)
+			}
+			i++
+			tokens = append(tokens, token{kind: tokenString, text: text.String(), pos: start})
+
+		default:
+			matched := false
+			for _, op := range operators {
+				if strings.HasPrefix(string(runes[i:]), op) {
+					tokens = append(tokens, token{kind: tokenOperator, text: op, pos: i})
+					i += len([]rune(op))
+					matched = true
+					break
+				}
+			}
+			if !matched {
+				return nil, fmt.Errorf("column %d: unexpected character %q", i+1, r)
+			}
+		}
+	}
+
+	return append(tokens, token{kind: tokenEOF, pos: len(runes)}), nil
+}
+
+// parser is a recursive descent parser over the token stream
+type parser struct {
+	tokens []token
+	pos    int
+}
+
+func (p *parser) peek() token {
+	return p.tokens[p.pos]
+}
+
+func (p *parser) next() token {
+	tok := p.tokens[p.pos]
+	if tok.kind != tokenEOF {
+		p.pos++
+	}
+	return tok
+}
+
+// accept consumes the next token when it is the given operator or keyword
+func (p *parser) accept(text string) (token, bool) {
+	tok := p.peek()
+	if (tok.kind == tokenOperator || tok.kind == tokenIdent) && tok.text == text {
+		return p.next(), true
+	}
+	return tok, false
+}
+
+func (p *parser) expect(text string) error {
+	if tok, ok := p.accept(text); !ok {
+		return fmt.Errorf("column %d: expected %q, got %s", tok.pos+1, text, describe(tok))
+	}
+	return nil
+}
+
+// parseOr parses a || b
+func (p *parser) parseOr() (node, error) {
+	left, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		tok, ok := p.accept("||")
+		if !ok {
+			return left, nil
+		}
+		right, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		left = binary{op: "||", pos: tok.pos, left: left, right: right}
+	}
+}
+
+// parseAnd parses a && b
+func (p *parser) parseAnd() (node, error) {
+	left, err := p.parseNot()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		tok, ok := p.accept("&&")
+		if !ok {
+			return left, nil
+		}
+		right, err := p.parseNot()
+		if err != nil {
+			return nil, err
+		}
+		left = binary{op: "&&", pos: tok.pos, left: left, right: right}
+	}
+}
+
+// parseNot parses !a
+func (p *parser) parseNot() (node, error) {
+	if tok, ok := p.accept("!"); ok {
+		operand, err := p.parseNot()
+		if err != nil {
+			return nil, err
+		}
+		return unary{op: "!", pos: tok.pos, operand: operand}, nil
+	}
+	return p.parseComparison()
+}
+
+// parseComparison parses a single comparison, membership or glob match
+func (p *parser) parseComparison() (node, error) {
+	left, err := p.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+	for _, op := range []string{"==", "!=", "<=", ">=", "<", ">", "in", "matches"} {
+		if tok, ok := p.accept(op); ok {
+			right, err := p.parseUnary()
+			if err != nil {
+				return nil, err
+			}
+			return binary{op: op, pos: tok.pos, left: left, right: right}, nil
+		}
+	}
+	return left, nil
+}
+
+// parseUnary parses -a
+func (p *parser) parseUnary() (node, error) {
+	if tok, ok := p.accept("-"); ok {
+		operand, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		return unary{op: "-", pos: tok.pos, operand: operand}, nil
+	}
+	return p.parsePrimary()
+}
+
+// parsePrimary parses literals, variables, lists and parenthesized expressions
+func (p *parser) parsePrimary() (node, error) {
+	tok := p.next()
+	switch tok.kind {
+	case tokenNumber:
+		value, err := strconv.ParseFloat(tok.text, 64)
+		if err != nil {
+			return nil, fmt.Errorf("column %d: invalid number %q", tok.pos+1, tok.text)
+		}
+		return literal{typ: TypeNumber, value: value}, nil
+
+	case tokenString:
+		return literal{typ: TypeString, value: tok.text}, nil
+
+	case tokenIdent:
+		switch tok.text {
+		case "true", "false":
+			return literal{typ: TypeBool, value: tok.text == "true"}, nil
+		case "in", "matches":
+			return nil, fmt.Errorf("column %d: unexpected %q", tok.pos+1, tok.text)
+		}
+		return &variable{name: tok.text, pos: tok.pos}, nil
+
+	case tokenOperator:
+		switch tok.text {
+		case "(":
+			inner, err := p.parseOr()
+			if err != nil {
+				return nil, err
+			}
+			return inner, p.expect(")")
+		case "[":
+			return p.parseList()
+		}
+	}
+
+	return nil, fmt.Errorf("column %d: unexpected %s", tok.pos+1, describe(tok))
+}
+
+// parseList parses a list of string literals after the opening bracket
+func (p *parser) parseList() (node, error) {
+	items := []string{}
+	if _, ok := p.accept("]"); ok {
+		return literal{typ: TypeList, value: items}, nil
+	}
+	for {
+		tok := p.next()
+		if tok.kind != tokenString {
+			return nil, fmt.Errorf("column %d: lists may only contain strings, got %s", tok.pos+1, describe(tok))
+		}
+		items = append(items, tok.text)
+		if _, ok := p.accept(","); !ok {
+			return literal{typ: TypeList, value: items}, p.expect("]")
+		}
+	}
+}
+
+// describe names a token in error messages
+func describe(tok token) string {
+	if tok.kind == tokenEOF {
+		return "end of expression"
+	}
+	return fmt.Sprintf("%q", tok.text)
+}
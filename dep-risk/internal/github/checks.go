@@ -3,9 +3,13 @@ package github
 import (
 	"context"
 	"fmt"
+	"math"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/google/go-github/v57/github"
+	"github.com/dep-risk/dep-risk/internal/scanner"
 	"github.com/dep-risk/dep-risk/internal/scorer"
 )
 
@@ -42,10 +46,20 @@ func (c *Client) CreateCheckRun(ctx context.Context, projectScore *scorer.Projec
 	return nil
 }
 
+// checkRunConclusion concludes a check run from the score, path scopes,
+// gates, policy decisions and scan coverage
+func (c *Client) checkRunConclusion(projectScore *scorer.ProjectRiskScore, failThreshold float64) CheckRunConclusion {
+	conclusion := c.determineConclusion(projectScore.OverallScore, failThreshold)
+	conclusion = c.adjustForPolicyScopes(conclusion, projectScore)
+	conclusion = c.adjustForGates(conclusion, projectScore)
+	conclusion = c.adjustForDecisions(conclusion, projectScore)
+	return c.adjustForPartialScan(conclusion, projectScore)
+}
+
 // buildCheckRun constructs the check run object
 func (c *Client) buildCheckRun(projectScore *scorer.ProjectRiskScore, failThreshold float64) github.CreateCheckRunOptions {
 	status := string(CheckRunStatusCompleted)
-	conclusion := c.determineConclusion(projectScore.OverallScore, failThreshold)
+	conclusion := c.checkRunConclusion(projectScore, failThreshold)
 	
 	checkRun := github.CreateCheckRunOptions{
 		Name:    "Dep-Risk Security Scan",
@@ -82,6 +96,42 @@ func (c *Client) determineConclusion(riskScore, failThreshold float64) CheckRunC
 	return CheckRunConclusionSuccess
 }
 
+// adjustForPolicyScopes judges each scope of path overrides against its own
+// threshold instead of the project score
+func (c *Client) adjustForPolicyScopes(conclusion CheckRunConclusion, projectScore *scorer.ProjectRiskScore) CheckRunConclusion {
+	if projectScore.Policy == nil || len(projectScore.Policy.Scopes) == 0 {
+		return conclusion
+	}
+	if projectScore.Policy.Failed() {
+		return CheckRunConclusionFailure
+	}
+	return CheckRunConclusionSuccess
+}
+
+// adjustForGates fails the check when a gate with the fail action failed
+func (c *Client) adjustForGates(conclusion CheckRunConclusion, projectScore *scorer.ProjectRiskScore) CheckRunConclusion {
+	if scorer.GatesFailed(projectScore.Gates, scorer.GateFail) > 0 {
+		return CheckRunConclusionFailure
+	}
+	return conclusion
+}
+
+// adjustForDecisions fails the check when a policy rule denied
+func (c *Client) adjustForDecisions(conclusion CheckRunConclusion, projectScore *scorer.ProjectRiskScore) CheckRunConclusion {
+	if scorer.CountDecisions(projectScore.Decisions, scorer.DecisionDeny) > 0 {
+		return CheckRunConclusionFailure
+	}
+	return conclusion
+}
+
+// adjustForPartialScan downgrades a passing conclusion to neutral when the scan was incomplete
+func (c *Client) adjustForPartialScan(conclusion CheckRunConclusion, projectScore *scorer.ProjectRiskScore) CheckRunConclusion {
+	if conclusion == CheckRunConclusionSuccess && projectScore.IsPartialScan() {
+		return CheckRunConclusionNeutral
+	}
+	return conclusion
+}
+
 // buildCheckRunOutput creates the detailed output for the check run
 func (c *Client) buildCheckRunOutput(projectScore *scorer.ProjectRiskScore, failThreshold float64, conclusion CheckRunConclusion) github.CheckRunOutput {
 	title := c.buildOutputTitle(projectScore, conclusion)
@@ -104,7 +154,15 @@ func (c *Client) buildOutputTitle(projectScore *scorer.ProjectRiskScore, conclus
 		}
 		return fmt.Sprintf("✅ Risk score %.1f/10 - Below threshold", projectScore.OverallScore)
 	case CheckRunConclusionFailure:
+		if denied := scorer.CountDecisions(projectScore.Decisions, scorer.DecisionDeny); denied > 0 {
+			return fmt.Sprintf("❌ Denied by %d policy rules - Risk score %.1f/10", denied, projectScore.OverallScore)
+		}
+		if failed := scorer.GatesFailed(projectScore.Gates, scorer.GateFail); failed > 0 {
+			return fmt.Sprintf("❌ %d of %d gates failed - Risk score %.1f/10", failed, len(projectScore.Gates), projectScore.OverallScore)
+		}
 		return fmt.Sprintf("❌ Risk score %.1f/10 - Above threshold", projectScore.OverallScore)
+	case CheckRunConclusionNeutral:
+		return fmt.Sprintf("⚠️ Risk score %.1f/10 - Scan incomplete", projectScore.OverallScore)
 	default:
 		return "🔍 Security scan completed"
 	}
@@ -114,6 +172,8 @@ func (c *Client) buildOutputTitle(projectScore *scorer.ProjectRiskScore, conclus
 func (c *Client) buildOutputSummary(projectScore *scorer.ProjectRiskScore, failThreshold float64) string {
 	summary := fmt.Sprintf("**Risk Score**: %.1f/10 (Threshold: %.1f)\n", 
 		projectScore.OverallScore, failThreshold)
+	summary += fmt.Sprintf("**Aggregation**: %s\n", projectScore.Aggregation)
+	summary += fmt.Sprintf("**Scoring Model**: %s (config `%.12s`)\n", projectScore.ModelVersion, projectScore.ConfigHash)
 	
 	if projectScore.Summary.TotalVulnerabilities > 0 {
 		summary += fmt.Sprintf("**Vulnerabilities Found**: %d total\n", 
@@ -125,16 +185,33 @@ func (c *Client) buildOutputSummary(projectScore *scorer.ProjectRiskScore, failT
 		summary += "**No vulnerabilities detected** in your dependencies.\n"
 	}
 	
+	if len(projectScore.ExpiredIgnores) > 0 {
+		summary += fmt.Sprintf("**Expired Ignores**: %d findings are reported again\n", len(projectScore.ExpiredIgnores))
+	}
+	
+	summary += buildPolicySummary(projectScore.Policy)
+	summary += buildGatesSummary(projectScore.Gates)
+	summary += buildDecisionsSummary(projectScore.Decisions)
+	
+	if len(projectScore.Diagnostics) > 0 {
+		summary += fmt.Sprintf("**Scanner Diagnostics**: %d reported", len(projectScore.Diagnostics))
+		if projectScore.IsPartialScan() {
+			summary += " - some dependencies may not have been scanned"
+		}
+		summary += "\n"
+	}
+	
 	return summary
 }
 
 // buildOutputText creates the detailed text for the check run output
 func (c *Client) buildOutputText(projectScore *scorer.ProjectRiskScore) string {
+	diagnostics := buildDecisionsText(projectScore.Decisions) + buildGatesText(projectScore.Gates) + c.buildExpiredIgnoresText(projectScore) + c.buildDiagnosticsText(projectScore) + c.buildEOLText(projectScore)
 	if len(projectScore.VulnerabilityScores) == 0 {
-		return "No vulnerabilities were found in the scanned dependencies. Your project appears to be secure!"
+		return diagnostics + "No vulnerabilities were found in the scanned dependencies. Your project appears to be secure!"
 	}
 	
-	text := "## Vulnerability Details\n\n"
+	text := diagnostics + "## Vulnerability Details\n\n"
 	
 	// Show top vulnerabilities
 	maxShow := 20
@@ -159,11 +236,24 @@ func (c *Client) buildOutputText(projectScore *scorer.ProjectRiskScore) string {
 		text += fmt.Sprintf("### %s %s (%s Risk - %.1f/10)\n", emoji, vuln.ID, riskLevel, score.Overall)
 		text += fmt.Sprintf("**Package**: `%s` version `%s`\n", vuln.Package, vuln.Version)
 		text += fmt.Sprintf("**CVSS Score**: %.1f (%s)\n", vuln.CVSS, vuln.Severity)
+		if vuln.KEV {
+			text += "**Known Exploited**: listed in the CISA KEV catalog\n"
+		}
+		if vuln.FixAvailable() {
+			text += fmt.Sprintf("**Fixed In**: %s\n", strings.Join(vuln.FixedVersions, ", "))
+		}
+		if vuln.Published != nil {
+			text += fmt.Sprintf("**Published**: %s\n", vuln.Published.Format("2006-01-02"))
+		}
 		
 		if vuln.Summary != "" {
 			text += fmt.Sprintf("**Summary**: %s\n", vuln.Summary)
 		}
 		
+		if vuln.File != "" {
+			text += fmt.Sprintf("**Location**: `%s:%d`\n", vuln.File, vuln.Line)
+		}
+		
 		text += fmt.Sprintf("**Dependency Type**: %s\n", 
 			map[bool]string{true: "Direct", false: "Transitive"}[vuln.IsDirect])
 		
@@ -173,6 +263,16 @@ func (c *Client) buildOutputText(projectScore *scorer.ProjectRiskScore) string {
 		text += fmt.Sprintf("- Popularity Component: %.1f\n", score.PopularityComponent)
 		text += fmt.Sprintf("- Dependency Component: %.1f\n", score.DependencyComponent)
 		text += fmt.Sprintf("- Context Component: %.1f\n", score.ContextComponent)
+		for _, adjustment := range score.ContextAdjustments {
+			text += fmt.Sprintf("  - %s\n", adjustment)
+		}
+		text += fmt.Sprintf("- Maintenance Component: %.1f\n", score.MaintenanceComponent)
+		for _, modifier := range score.Modifiers {
+			text += fmt.Sprintf("- Modifier: %s\n", modifier)
+		}
+		for _, rule := range score.Rules {
+			text += fmt.Sprintf("- Rule: %s\n", rule)
+		}
 		
 		if len(vuln.References) > 0 {
 			text += "**References**:\n"
@@ -192,11 +292,112 @@ func (c *Client) buildOutputText(projectScore *scorer.ProjectRiskScore) string {
 	// Add scoring methodology
 	text += "## Risk Scoring Methodology\n\n"
 	text += "The risk score is calculated using a weighted combination of factors:\n\n"
-	text += "- **CVSS Score (50%)**: Base vulnerability severity from the Common Vulnerability Scoring System\n"
-	text += "- **Package Popularity (20%)**: Less popular packages may have fewer eyes on security issues\n"
-	text += "- **Dependency Type (15%)**: Direct dependencies are easier to update than transitive ones\n"
-	text += "- **Context (15%)**: Package type and usage context (e.g., crypto, network, auth libraries are higher risk)\n\n"
-	text += "Scores range from 0.0 (lowest risk) to 10.0 (highest risk).\n"
+	for _, factor := range scoringFactors(projectScore.Weights) {
+		text += fmt.Sprintf("- **%s (%s)**: %s\n", factor.name, factor.weight, factor.description)
+	}
+	text += "\nScores range from 0.0 (lowest risk) to 10.0 (highest risk).\n"
+	
+	return text
+}
+
+// scoringFactor is a component of the risk score as described to users
+type scoringFactor struct {
+	name        string
+	weight      string
+	description string
+}
+
+// scoringFactors describes the components of the risk score with the weights
+// the findings were scored with. Components weighted 0 are left out.
+func scoringFactors(weights scorer.ScoringWeights) []scoringFactor {
+	components := []struct {
+		name        string
+		weight      float64
+		description string
+	}{
+		{"CVSS Score", weights.CVSS, "Base vulnerability severity from the Common Vulnerability Scoring System"},
+		{"Package Popularity", weights.Popularity, "Less popular packages may have fewer eyes on security issues"},
+		{"Dependency Graph", weights.Dependency, "Depth from the project, dependency type (production, peer, optional, development), how many packages depend on it and dependency cycles"},
+		{"Context", weights.Context, "Declared execution context (exposure, privileges, data sensitivity, compliance, environment), or the package type when none is declared"},
+		{"Maintenance", weights.Maintenance, "Archived repositories, Scorecard results and release cadence"},
+	}
+
+	var factors []scoringFactor
+	for _, component := range components {
+		if component.weight <= 0 {
+			continue
+		}
+		percent := strconv.FormatFloat(math.Round(component.weight*1000)/10, 'f', -1, 64) + "%"
+		factors = append(factors, scoringFactor{name: component.name, weight: percent, description: component.description})
+	}
+	return factors
+}
+
+// buildExpiredIgnoresText lists the findings whose ignore rule has expired
+func (c *Client) buildExpiredIgnoresText(projectScore *scorer.ProjectRiskScore) string {
+	if len(projectScore.ExpiredIgnores) == 0 {
+		return ""
+	}
+	
+	text := "## ⏰ Expired Ignores\n\n"
+	text += "These findings were ignored until their rule expired and are reported again.\n\n"
+	text += "| Vulnerability | Package | Rule | Expired | Owner | Reason |\n"
+	text += "|---------------|---------|------|---------|-------|--------|\n"
+	for _, expired := range projectScore.ExpiredIgnores {
+		owner := expired.Owner
+		if owner == "" {
+			owner = "-"
+		}
+		text += fmt.Sprintf("| %s | `%s@%s` | %s | %s | %s | %s |\n", expired.VulnerabilityID, expired.Package, expired.Version,
+			escapeCell(expired.Rule), expired.Expires, escapeCell(owner), escapeCell(expired.Reason))
+	}
+	text += "\n"
+	
+	return text
+}
+
+// escapeCell escapes a value for a Markdown table cell
+func escapeCell(value string) string {
+	return strings.ReplaceAll(strings.ReplaceAll(value, "|", "\\|"), "\n", " ")
+}
+
+// buildDiagnosticsText lists the scanner diagnostics for the check run output
+func (c *Client) buildDiagnosticsText(projectScore *scorer.ProjectRiskScore) string {
+	if len(projectScore.Diagnostics) == 0 {
+		return ""
+	}
+	
+	text := "## Scanner Diagnostics\n\n"
+	text += "| Tool | Kind | Path | Message |\n"
+	text += "|------|------|------|---------|\n"
+	for _, diagnostic := range projectScore.Diagnostics {
+		text += fmt.Sprintf("| %s | %s | %s | %s |\n", diagnostic.Tool, diagnostic.Kind,
+			diagnostic.Path, strings.ReplaceAll(diagnostic.Message, "|", "\\|"))
+	}
+	text += "\n"
+	
+	return text
+}
+
+// buildEOLText lists the end-of-life runtimes and frameworks for the check run output
+func (c *Client) buildEOLText(projectScore *scorer.ProjectRiskScore) string {
+	text := ""
+	for _, score := range projectScore.VulnerabilityScores {
+		vuln := score.Vulnerability
+		if vuln.FindingClass() != scanner.ClassEOL {
+			continue
+		}
+		if text == "" {
+			text = "## End-of-Life Runtimes\n\n"
+			text += "| Runtime | Version | Location | Status |\n"
+			text += "|---------|---------|----------|--------|\n"
+		}
+		text += fmt.Sprintf("| %s | %s | `%s:%d` | %s |\n", vuln.Package, vuln.Version,
+			vuln.File, vuln.Line, strings.ReplaceAll(vuln.Summary, "|", "\\|"))
+	}
+	if text != "" {
+		text += "\n"
+	}
 	
 	return text
 }
@@ -220,7 +421,7 @@ func (c *Client) buildCheckRunActions() []*github.CheckRunAction {
 // UpdateCheckRun updates an existing check run (for long-running scans)
 func (c *Client) UpdateCheckRun(ctx context.Context, checkRunID int64, projectScore *scorer.ProjectRiskScore, failThreshold float64) error {
 	status := string(CheckRunStatusCompleted)
-	conclusion := c.determineConclusion(projectScore.OverallScore, failThreshold)
+	conclusion := c.checkRunConclusion(projectScore, failThreshold)
 	conclusionStr := string(conclusion)
 	
 	now := github.Timestamp{Time: time.Now()}
@@ -264,4 +465,114 @@ func (c *Client) CreateInProgressCheckRun(ctx context.Context) (*github.CheckRun
 	}
 	
 	return result, nil
-}
\ No newline at end of file
+}
+
+// buildPolicySummary lists the override blocks applied to the run and the
+// scopes of path overrides with their thresholds
+func buildPolicySummary(policy *scorer.PolicyResult) string {
+	if policy == nil || (len(policy.Overrides) == 0 && len(policy.Scopes) == 0) {
+		return ""
+	}
+
+	summary := ""
+	if len(policy.Overrides) > 0 {
+		summary += fmt.Sprintf("**Policy Overrides**: %s (fail %.1f, warn %.1f)\n",
+			strings.Join(policy.Overrides, ", "), policy.FailThreshold, policy.WarnThreshold)
+	}
+	for _, scope := range policy.Scopes {
+		name := "Default scope"
+		if scope.Override != "" {
+			name = fmt.Sprintf("`%s` (%s)", scope.Override, strings.Join(scope.Paths, ", "))
+		}
+		status := "✅"
+		if scope.Score >= scope.FailThreshold {
+			status = "❌"
+		}
+		summary += fmt.Sprintf("- %s %s: %.1f/10 across %d findings (Threshold: %.1f)\n",
+			status, name, scope.Score, scope.Findings, scope.FailThreshold)
+	}
+	return summary
+}
+
+// buildGatesSummary counts the gates that passed
+func buildGatesSummary(gates []scorer.GateResult) string {
+	if len(gates) == 0 {
+		return ""
+	}
+	passed := 0
+	for _, gate := range gates {
+		if gate.Passed {
+			passed++
+		}
+	}
+	return fmt.Sprintf("**Gates**: %d/%d passed\n", passed, len(gates))
+}
+
+// buildGatesText lists each gate with its condition and result
+func buildGatesText(gates []scorer.GateResult) string {
+	if len(gates) == 0 {
+		return ""
+	}
+
+	text := "## 🚦 Gates\n\n"
+	text += "| Gate | Condition | Matches | Max | Result |\n"
+	text += "|------|-----------|---------|-----|--------|\n"
+	for _, gate := range gates {
+		result := "✅ Passed"
+		if !gate.Passed {
+			result = "❌ Failed"
+			if gate.Action == scorer.GateWarn {
+				result = "⚠️ Warned"
+			}
+		}
+		text += fmt.Sprintf("| %s | `%s` | %d | %d | %s |\n", escapeCell(gate.Name),
+			escapeCell(gate.Condition), gate.Count, gate.MaxCount, result)
+	}
+	text += "\n"
+	for _, gate := range gates {
+		if gate.Error != "" {
+			text += fmt.Sprintf("**%s** was not evaluated: %s\n\n", gate.Name, gate.Error)
+		}
+		if !gate.Passed && len(gate.Matches) > 0 {
+			text += fmt.Sprintf("**%s** matched: %s\n\n", gate.Name, strings.Join(gate.Matches, ", "))
+		}
+	}
+
+	return text
+}
+
+// buildDecisionsSummary counts the decisions of policy files
+func buildDecisionsSummary(decisions []scorer.PolicyDecision) string {
+	if len(decisions) == 0 {
+		return ""
+	}
+	return fmt.Sprintf("**Policy Decisions**: %d deny, %d warn\n",
+		scorer.CountDecisions(decisions, scorer.DecisionDeny), scorer.CountDecisions(decisions, scorer.DecisionWarn))
+}
+
+// buildDecisionsText lists the decisions of policy files, denials first
+func buildDecisionsText(decisions []scorer.PolicyDecision) string {
+	if len(decisions) == 0 {
+		return ""
+	}
+
+	text := "## 📜 Policy Decisions\n\n"
+	text += "| Decision | Rule | Policy | Message |\n"
+	text += "|----------|------|--------|---------|\n"
+	for _, action := range []string{scorer.DecisionDeny, scorer.DecisionWarn} {
+		for _, decision := range decisions {
+			if decision.Action != action {
+				continue
+			}
+			label := "❌ Deny"
+			if action == scorer.DecisionWarn {
+				label = "⚠️ Warn"
+			}
+			text += fmt.Sprintf("| %s | %s | `%s` | %s |\n", label, escapeCell(decision.Rule),
+				escapeCell(decision.Policy), escapeCell(decision.Message))
+		}
+	}
+	text += "\n"
+
+	return text
+}
@@ -87,6 +87,7 @@ func (c *Client) generateCommentBody(projectScore *scorer.ProjectRiskScore) stri
 	builder.WriteString("### 📊 Summary\n")
 	builder.WriteString(fmt.Sprintf("- **Overall Risk Score**: %.1f/10 (%s)\n", 
 		projectScore.OverallScore, template.RiskLevel))
+	builder.WriteString(fmt.Sprintf("- **Aggregation**: %s\n", projectScore.Aggregation))
 	builder.WriteString(fmt.Sprintf("- **Total Vulnerabilities**: %d\n", 
 		projectScore.Summary.TotalVulnerabilities))
 	builder.WriteString(fmt.Sprintf("- **High Risk**: %d | **Medium Risk**: %d | **Low Risk**: %d\n", 
@@ -101,6 +102,13 @@ func (c *Client) generateCommentBody(projectScore *scorer.ProjectRiskScore) stri
 	
 	builder.WriteString("\n")
 	
+	// Policy decisions and gate results
+	builder.WriteString(strings.Replace(buildDecisionsText(projectScore.Decisions), "## ", "### ", 1))
+	builder.WriteString(strings.Replace(buildGatesText(projectScore.Gates), "## ", "### ", 1))
+	
+	// Findings whose ignore rule has expired
+	builder.WriteString(strings.Replace(c.buildExpiredIgnoresText(projectScore), "## ", "### ", 1))
+	
 	// Detailed vulnerabilities section
 	if len(projectScore.VulnerabilityScores) > 0 {
 		builder.WriteString("### 🔍 Vulnerability Details\n\n")
@@ -129,17 +137,24 @@ func (c *Client) generateCommentBody(projectScore *scorer.ProjectRiskScore) stri
 				len(projectScore.VulnerabilityScores)-maxShow))
 		}
 		
-		builder.WriteString("\n")
+		builder.WriteString("\n<details>\n<summary>🧮 Score explanations</summary>\n\n")
+		for i := 0; i < maxShow; i++ {
+			score := projectScore.VulnerabilityScores[i]
+			builder.WriteString(fmt.Sprintf("**%s** in `%s`\n\n", score.Vulnerability.ID, score.Vulnerability.Package))
+			writeExplanation(&builder, score.Explanation, 0)
+			builder.WriteString("\n")
+		}
+		builder.WriteString("</details>\n\n")
 	}
 	
 	// Risk breakdown section
 	if projectScore.Summary.TotalVulnerabilities > 0 {
 		builder.WriteString("### ⚖️ Risk Score Breakdown\n")
 		builder.WriteString("The risk score is calculated using multiple factors:\n\n")
-		builder.WriteString("- **CVSS Score** (50%): Base vulnerability severity\n")
-		builder.WriteString("- **Package Popularity** (20%): Less popular packages are riskier\n")
-		builder.WriteString("- **Dependency Type** (15%): Direct dependencies are easier to update\n")
-		builder.WriteString("- **Context** (15%): Package type and usage context\n\n")
+		for _, factor := range scoringFactors(projectScore.Weights) {
+			builder.WriteString(fmt.Sprintf("- **%s** (%s): %s\n", factor.name, factor.weight, factor.description))
+		}
+		builder.WriteString("\n")
 	}
 	
 	// Footer with timestamp and actions
@@ -157,6 +172,14 @@ func (c *Client) generateCommentBody(projectScore *scorer.ProjectRiskScore) stri
 	return builder.String()
 }
 
+// writeExplanation renders an explanation tree as a nested Markdown list
+func writeExplanation(builder *strings.Builder, explanation scorer.Explanation, depth int) {
+	builder.WriteString(fmt.Sprintf("%s- %s\n", strings.Repeat("  ", depth), explanation.Summary()))
+	for _, child := range explanation.Children {
+		writeExplanation(builder, child, depth+1)
+	}
+}
+
 // getCommentTemplate returns the appropriate template based on risk score
 func (c *Client) getCommentTemplate(projectScore *scorer.ProjectRiskScore) CommentTemplate {
 	score := projectScore.OverallScore
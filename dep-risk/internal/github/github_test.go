@@ -80,6 +80,13 @@ func TestGenerateCommentBody(t *testing.T) {
 					CVSS:     8.0,
 					Severity: "HIGH",
 				},
+				Explanation: scorer.Explanation{
+					Factor: "risk_score",
+					Value:  8.0,
+					Children: []scorer.Explanation{
+						{Factor: "weighted_sum", Value: 8.0, Inputs: map[string]interface{}{"cvss": 8.0}},
+					},
+				},
 			},
 		},
 	}
@@ -102,4 +109,222 @@ func TestGenerateCommentBody(t *testing.T) {
 	if !strings.Contains(comment, "test-package") {
 		t.Error("Comment should contain package name")
 	}
-}
\ No newline at end of file
+	
+	if !strings.Contains(comment, "<details>") || !strings.Contains(comment, "  - weighted_sum: 8.00 (cvss=8)\n") {
+		t.Errorf("Comment should contain a collapsible score explanation, got:\n%s", comment)
+	}
+}
+
+func TestPartialScanCheckRun(t *testing.T) {
+	client := &Client{}
+	
+	projectScore := &scorer.ProjectRiskScore{
+		OverallScore: 2.0,
+		Diagnostics: []scanner.Diagnostic{
+			{
+				Tool:    "osv-scanner",
+				Kind:    scanner.DiagnosticUnsupportedLockfile,
+				Message: "Attempted to scan lockfile but failed: poetry.lock",
+				Path:    "poetry.lock",
+			},
+		},
+	}
+	
+	conclusion := client.adjustForPartialScan(CheckRunConclusionSuccess, projectScore)
+	if conclusion != CheckRunConclusionNeutral {
+		t.Errorf("Expected neutral conclusion for partial scan, got %s", conclusion)
+	}
+	
+	conclusion = client.adjustForPartialScan(CheckRunConclusionFailure, projectScore)
+	if conclusion != CheckRunConclusionFailure {
+		t.Errorf("Expected failure conclusion to be kept, got %s", conclusion)
+	}
+	
+	text := client.buildOutputText(projectScore)
+	if !strings.Contains(text, "unsupported_lockfile") || !strings.Contains(text, "poetry.lock") {
+		t.Error("Check run text should list scanner diagnostics")
+	}
+}
+
+func TestExpiredIgnores(t *testing.T) {
+	client := &Client{}
+	
+	projectScore := &scorer.ProjectRiskScore{
+		OverallScore: 5.0,
+		ExpiredIgnores: []scorer.ExpiredIgnore{
+			{
+				VulnerabilityID: "GHSA-29mw-wpgm-hmr9",
+				Package:         "lodash",
+				Version:         "4.17.20",
+				Rule:            "lodash@<4.17.21",
+				Reason:          "Not reachable | pending upgrade",
+				Owner:           "@acme/platform",
+				Expires:         "2026-01-31",
+			},
+		},
+	}
+	
+	comment := client.generateCommentBody(projectScore)
+	if !strings.Contains(comment, "### ⏰ Expired Ignores") || !strings.Contains(comment, "| GHSA-29mw-wpgm-hmr9 | `lodash@4.17.20` | lodash@<4.17.21 | 2026-01-31 | @acme/platform | Not reachable \\| pending upgrade |") {
+		t.Errorf("Comment should call out the expired ignore, got:\n%s", comment)
+	}
+	
+	summary := client.buildOutputSummary(projectScore, 7.0)
+	if !strings.Contains(summary, "**Expired Ignores**: 1 findings are reported again") {
+		t.Errorf("Check run summary should count expired ignores, got:\n%s", summary)
+	}
+	if text := client.buildOutputText(projectScore); !strings.Contains(text, "## ⏰ Expired Ignores") {
+		t.Error("Check run text should list expired ignores")
+	}
+}
+
+func TestPolicyScopesCheckRun(t *testing.T) {
+	client := &Client{}
+	
+	projectScore := &scorer.ProjectRiskScore{
+		OverallScore: 8.0,
+		Policy: &scorer.PolicyResult{
+			Overrides:     []string{"release"},
+			FailThreshold: 6.0,
+			WarnThreshold: 3.0,
+			Scopes: []scorer.PolicyScope{
+				{Findings: 2, Score: 5.0, FailThreshold: 6.0, WarnThreshold: 3.0},
+				{Override: "examples", Paths: []string{"examples/**"}, Findings: 1, Score: 8.0, FailThreshold: 9.0, WarnThreshold: 5.0},
+			},
+		},
+	}
+	
+	conclusion := client.adjustForPolicyScopes(CheckRunConclusionFailure, projectScore)
+	if conclusion != CheckRunConclusionSuccess {
+		t.Errorf("Expected scopes below their thresholds to pass, got %s", conclusion)
+	}
+	
+	projectScore.Policy.Scopes[1].FailThreshold = 7.0
+	conclusion = client.adjustForPolicyScopes(CheckRunConclusionSuccess, projectScore)
+	if conclusion != CheckRunConclusionFailure {
+		t.Errorf("Expected a scope above its threshold to fail, got %s", conclusion)
+	}
+	
+	summary := client.buildOutputSummary(projectScore, 6.0)
+	for _, want := range []string{"**Policy Overrides**: release", "❌ `examples` (examples/**): 8.0/10"} {
+		if !strings.Contains(summary, want) {
+			t.Errorf("Expected summary to contain %q, got:\n%s", want, summary)
+		}
+	}
+}
+
+func TestGatesCheckRun(t *testing.T) {
+	client := &Client{}
+	
+	projectScore := &scorer.ProjectRiskScore{
+		OverallScore: 4.0,
+		Gates: []scorer.GateResult{
+			{Name: "known-exploited", Condition: "kev", Action: scorer.GateFail, Count: 1, Passed: false, Matches: []string{"CVE-2021-44228"}},
+			{Name: "too-many-highs", Condition: "severity == 'HIGH'", Action: scorer.GateWarn, MaxCount: 5, Count: 2, Passed: true},
+		},
+	}
+	
+	checkRun := client.buildCheckRun(projectScore, 7.0)
+	if *checkRun.Conclusion != string(CheckRunConclusionFailure) {
+		t.Errorf("Expected a failed gate to fail the check run, got %s", *checkRun.Conclusion)
+	}
+	if title := *checkRun.Output.Title; !strings.Contains(title, "1 of 2 gates failed") {
+		t.Errorf("Expected the title to name the failed gates, got %q", title)
+	}
+	if summary := *checkRun.Output.Summary; !strings.Contains(summary, "**Gates**: 1/2 passed") {
+		t.Errorf("Expected the gates summary, got:\n%s", summary)
+	}
+	text := *checkRun.Output.Text
+	for _, want := range []string{
+		"## 🚦 Gates",
+		"| known-exploited | `kev` | 1 | 0 | ❌ Failed |",
+		"| too-many-highs | `severity == 'HIGH'` | 2 | 5 | ✅ Passed |",
+		"**known-exploited** matched: CVE-2021-44228",
+	} {
+		if !strings.Contains(text, want) {
+			t.Errorf("Expected text to contain %q, got:\n%s", want, text)
+		}
+	}
+	
+	// A failed warn gate leaves the conclusion alone
+	projectScore.Gates[0].Action = scorer.GateWarn
+	if conclusion := client.adjustForGates(CheckRunConclusionSuccess, projectScore); conclusion != CheckRunConclusionSuccess {
+		t.Errorf("Expected a warn gate not to fail the check run, got %s", conclusion)
+	}
+}
+
+func TestPolicyDecisionsCheckRun(t *testing.T) {
+	client := &Client{}
+	
+	projectScore := &scorer.ProjectRiskScore{
+		OverallScore: 3.0,
+		Decisions: []scorer.PolicyDecision{
+			{Policy: ".github/policy.yml", Rule: "old-advisories", Action: scorer.DecisionWarn, Message: "advisories older than a year"},
+			{Policy: ".github/policy.yml", Rule: "reachable-critical", Action: scorer.DecisionDeny, Message: "reachable critical findings: CVE-1"},
+		},
+	}
+	
+	checkRun := client.buildCheckRun(projectScore, 7.0)
+	if *checkRun.Conclusion != string(CheckRunConclusionFailure) {
+		t.Errorf("Expected a denial to fail the check run, got %s", *checkRun.Conclusion)
+	}
+	if conclusion := client.checkRunConclusion(projectScore, 7.0); conclusion != CheckRunConclusionFailure {
+		t.Errorf("Expected updated check runs to conclude like created ones, got %s", conclusion)
+	}
+	if title := *checkRun.Output.Title; !strings.Contains(title, "Denied by 1 policy rules") {
+		t.Errorf("Expected the title to name the denial, got %q", title)
+	}
+	if summary := *checkRun.Output.Summary; !strings.Contains(summary, "**Policy Decisions**: 1 deny, 1 warn") {
+		t.Errorf("Expected the decisions summary, got:\n%s", summary)
+	}
+	text := *checkRun.Output.Text
+	deny := strings.Index(text, "| ❌ Deny | reachable-critical | `.github/policy.yml` | reachable critical findings: CVE-1 |")
+	warn := strings.Index(text, "| ⚠️ Warn | old-advisories |")
+	if deny < 0 || warn < deny {
+		t.Errorf("Expected denials listed before warnings, got:\n%s", text)
+	}
+	
+	comment := client.generateCommentBody(projectScore)
+	if !strings.Contains(comment, "### 📜 Policy Decisions") {
+		t.Errorf("Expected the comment to list the decisions, got:\n%s", comment)
+	}
+	
+	// Warnings alone leave the conclusion alone
+	projectScore.Decisions = projectScore.Decisions[:1]
+	if conclusion := client.adjustForDecisions(CheckRunConclusionSuccess, projectScore); conclusion != CheckRunConclusionSuccess {
+		t.Errorf("Expected a warning not to fail the check run, got %s", conclusion)
+	}
+}
+
+func TestScoringMethodologyWeights(t *testing.T) {
+	client := &Client{}
+	weights := scorer.ScoringWeights{CVSS: 0.4, Popularity: 0.1, Dependency: 0.2, Context: 0.175, Maintenance: 0.125}
+	projectScore := &scorer.ProjectRiskScore{
+		OverallScore: 6.0,
+		Weights:      weights,
+		Summary:      scorer.ScoreSummary{TotalVulnerabilities: 1},
+		VulnerabilityScores: []scorer.RiskScore{
+			{Overall: 6.0, Vulnerability: scanner.Vulnerability{ID: "CVE-1", Package: "lodash"}},
+		},
+	}
+	
+	text := client.buildOutputText(projectScore)
+	for _, line := range []string{"**CVSS Score (40%)**", "**Dependency Graph (20%)**", "**Context (17.5%)**", "**Maintenance (12.5%)**"} {
+		if !strings.Contains(text, line) {
+			t.Errorf("Expected the methodology to show %s, got:\n%s", line, text)
+		}
+	}
+	
+	comment := client.generateCommentBody(projectScore)
+	if !strings.Contains(comment, "**Package Popularity** (10%)") || strings.Contains(comment, "50%") {
+		t.Errorf("Expected the comment to show the weights used, got:\n%s", comment)
+	}
+	
+	// Components weighted 0 are not listed
+	weights.Maintenance = 0
+	for _, factor := range scoringFactors(weights) {
+		if factor.name == "Maintenance" {
+			t.Errorf("Expected an unweighted maintenance component to be left out, got %+v", factor)
+		}
+	}
+}
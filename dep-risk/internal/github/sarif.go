@@ -220,6 +220,11 @@ func (c *Client) buildSARIFResults(projectScore *scorer.ProjectRiskScore) []map[
 			level = "warning"
 		}
 		
+		uri, line := c.getDependencyFile(vuln.Package), 1
+		if vuln.File != "" {
+			uri, line = vuln.File, vuln.Line
+		}
+		
 		result := map[string]interface{}{
 			"ruleId": vuln.ID,
 			"level":  level,
@@ -231,10 +236,10 @@ func (c *Client) buildSARIFResults(projectScore *scorer.ProjectRiskScore) []map[
 				{
 					"physicalLocation": map[string]interface{}{
 						"artifactLocation": map[string]interface{}{
-							"uri": c.getDependencyFile(vuln.Package),
+							"uri": uri,
 						},
 						"region": map[string]interface{}{
-							"startLine": 1,
+							"startLine": line,
 							"startColumn": 1,
 						},
 					},
@@ -247,10 +252,17 @@ func (c *Client) buildSARIFResults(projectScore *scorer.ProjectRiskScore) []map[
 				"package":             vuln.Package,
 				"version":             vuln.Version,
 				"is_direct":           vuln.IsDirect,
+				"fixed_versions":      vuln.FixedVersions,
+				"published":           vuln.Published,
 				"cvss_component":      score.CVSSComponent,
 				"popularity_component": score.PopularityComponent,
 				"dependency_component": score.DependencyComponent,
 				"context_component":   score.ContextComponent,
+				"maintenance_component": score.MaintenanceComponent,
+				"context_adjustments": explanations(score.ContextAdjustments),
+				"modifiers":           explanations(score.Modifiers),
+				"rules":               explanations(score.Rules),
+				"explanation":         score.Explanation,
 			},
 		}
 		
@@ -260,6 +272,15 @@ func (c *Client) buildSARIFResults(projectScore *scorer.ProjectRiskScore) []map[
 	return results
 }
 
+// explanations formats score adjustments for SARIF properties
+func explanations(adjustments []scorer.Adjustment) []string {
+	explanations := make([]string, len(adjustments))
+	for i, adjustment := range adjustments {
+		explanations[i] = adjustment.String()
+	}
+	return explanations
+}
+
 // getHelpUri returns a help URI for the vulnerability
 func (c *Client) getHelpUri(vuln scanner.Vulnerability) string {
 	// Try to find a relevant reference URL
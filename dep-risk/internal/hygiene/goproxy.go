@@ -0,0 +1,219 @@
+package hygiene
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"golang.org/x/mod/modfile"
+	"golang.org/x/mod/module"
+	"golang.org/x/mod/semver"
+
+	"github.com/dep-risk/dep-risk/internal/scanner"
+)
+
+// errNotFound reports that a proxy does not serve the requested module
+var errNotFound = errors.New("not found")
+
+// checkGoRetractions reports required module versions retracted by their authors
+func (c *Checker) checkGoRetractions() ([]scanner.Vulnerability, []scanner.Diagnostic) {
+	goModPath := filepath.Join(c.WorkingDir, "go.mod")
+	data, err := os.ReadFile(goModPath)
+	if err != nil {
+		// Not a Go project
+		return nil, nil
+	}
+
+	file, err := modfile.ParseLax(goModPath, data, nil)
+	if err != nil {
+		return nil, []scanner.Diagnostic{lookupDiagnostic("go.mod", err)}
+	}
+
+	var findings []scanner.Vulnerability
+	var diagnostics []scanner.Diagnostic
+
+	for i, req := range file.Require {
+		retractions, err := c.fetchRetractions(req.Mod.Path)
+		if err != nil {
+			// Private modules are not served by public proxies, so a missing
+			// module only skips that module
+			diagnostics = append(diagnostics, lookupDiagnostic("go.mod", fmt.Errorf("%s: %w", req.Mod.Path, err)))
+			if errors.Is(err, errNotFound) {
+				continue
+			}
+			// Any other error means the proxy is unreachable or failing; the
+			// remaining lookups would only wait for the same timeout
+			if remaining := len(file.Require) - i - 1; remaining > 0 {
+				diagnostics = append(diagnostics, lookupDiagnostic("go.mod", fmt.Errorf("skipped %d remaining modules after a proxy failure", remaining)))
+			}
+			break
+		}
+
+		for _, retract := range retractions {
+			if semver.Compare(req.Mod.Version, retract.Low) < 0 || semver.Compare(req.Mod.Version, retract.High) > 0 {
+				continue
+			}
+
+			summary := fmt.Sprintf("%s@%s has been retracted by the module author", req.Mod.Path, req.Mod.Version)
+			if retract.Rationale != "" {
+				summary += ": " + retract.Rationale
+			}
+
+			findings = append(findings, scanner.Vulnerability{
+				ID:          fmt.Sprintf("RETRACTED-%s@%s", req.Mod.Path, req.Mod.Version),
+				Package:     req.Mod.Path,
+				Version:     req.Mod.Version,
+				Severity:    hygieneSeverity("MEDIUM", retract.Rationale),
+				Summary:     summary,
+				Description: "The module author retracted this version in the go.mod of the latest release. Upgrade to a version that is not retracted.",
+				References:  []string{fmt.Sprintf("https://pkg.go.dev/%s?tab=versions", req.Mod.Path)},
+				IsDirect:    !req.Indirect,
+				Ecosystem:   "Go",
+				Class:       scanner.ClassHygiene,
+			})
+			break
+		}
+	}
+
+	return findings, diagnostics
+}
+
+// fetchRetractions reads the retract directives from the go.mod of the latest module version
+func (c *Checker) fetchRetractions(modulePath string) ([]*modfile.Retract, error) {
+	escapedPath, err := module.EscapePath(modulePath)
+	if err != nil {
+		return nil, err
+	}
+
+	latest, err := c.latestVersion(escapedPath)
+	if err != nil {
+		return nil, err
+	}
+	if latest == "" {
+		return nil, nil
+	}
+
+	escapedVersion, err := module.EscapeVersion(latest)
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := c.fetchFromProxy(escapedPath + "/@v/" + escapedVersion + ".mod")
+	if err != nil {
+		return nil, err
+	}
+
+	file, err := modfile.ParseLax(modulePath+"@"+latest+"/go.mod", data, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	return file.Retract, nil
+}
+
+// latestVersion returns the highest release version listed by the proxy, or
+// the highest prerelease when the module has no release, as go get does
+func (c *Checker) latestVersion(escapedPath string) (string, error) {
+	data, err := c.fetchFromProxy(escapedPath + "/@v/list")
+	if err != nil {
+		return "", err
+	}
+
+	var release, prerelease string
+	for _, version := range strings.Fields(string(data)) {
+		if !semver.IsValid(version) {
+			continue
+		}
+		latest := &release
+		if semver.Prerelease(version) != "" {
+			latest = &prerelease
+		}
+		if *latest == "" || semver.Compare(version, *latest) > 0 {
+			*latest = version
+		}
+	}
+	if release != "" {
+		return release, nil
+	}
+	return prerelease, nil
+}
+
+// fetchFromProxy fetches a proxy path from the first configured proxy that serves it
+func (c *Checker) fetchFromProxy(path string) ([]byte, error) {
+	var lastErr error = errNotFound
+
+	for _, proxy := range splitProxyList(c.Options.GoProxy) {
+		var data []byte
+		var err error
+		if strings.HasPrefix(proxy, "http://") || strings.HasPrefix(proxy, "https://") {
+			data, err = c.fetchHTTP(strings.TrimSuffix(proxy, "/") + "/" + path)
+		} else {
+			data, err = c.fetchFile(proxy, path)
+		}
+		if err == nil {
+			return data, nil
+		}
+		lastErr = err
+		if !errors.Is(err, errNotFound) {
+			break
+		}
+	}
+
+	return nil, lastErr
+}
+
+// fetchHTTP fetches a path from an HTTP module proxy
+func (c *Checker) fetchHTTP(rawURL string) ([]byte, error) {
+	resp, err := c.httpClient.Get(rawURL)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+		return io.ReadAll(resp.Body)
+	case http.StatusNotFound, http.StatusGone:
+		return nil, fmt.Errorf("%s: %w", rawURL, errNotFound)
+	default:
+		return nil, fmt.Errorf("%s returned status %d", rawURL, resp.StatusCode)
+	}
+}
+
+// fetchFile reads a path from a file-based module proxy
+func (c *Checker) fetchFile(proxy, path string) ([]byte, error) {
+	var root string
+	if strings.HasPrefix(proxy, "file://") {
+		parsed, err := url.Parse(proxy)
+		if err != nil {
+			return nil, err
+		}
+		root = parsed.Path
+	} else {
+		root = c.resolvePath(proxy)
+	}
+
+	data, err := os.ReadFile(filepath.Join(root, filepath.FromSlash(path)))
+	if os.IsNotExist(err) {
+		return nil, fmt.Errorf("%s: %w", path, errNotFound)
+	}
+	return data, err
+}
+
+// splitProxyList splits a GOPROXY value, dropping the entries that are not proxies
+func splitProxyList(goproxy string) []string {
+	var proxies []string
+	for _, entry := range strings.FieldsFunc(goproxy, func(r rune) bool { return r == ',' || r == '|' }) {
+		entry = strings.TrimSpace(entry)
+		if entry == "" || entry == "direct" || entry == "off" {
+			continue
+		}
+		proxies = append(proxies, entry)
+	}
+	return proxies
+}
@@ -0,0 +1,101 @@
+package hygiene
+
+import (
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/dep-risk/dep-risk/internal/scanner"
+)
+
+// DefaultGoProxy is used when neither the configuration nor GOPROXY name a proxy
+const DefaultGoProxy = "https://proxy.golang.org"
+
+// securityKeywords raise the severity of a retraction or deprecation message
+var securityKeywords = []string{"security", "vulnerab", "cve-", "ghsa-", "exploit", "malicious", "compromised"}
+
+// Options configures the hygiene checks
+type Options struct {
+	// GoProxy is a GOPROXY-style list of module proxies. Entries may be
+	// http(s) URLs, file:// URLs or local directories laid out like a proxy.
+	GoProxy string
+
+	// NPMRegistrySnapshot is a directory of npm registry metadata documents,
+	// one <package>.json file per package
+	NPMRegistrySnapshot string
+}
+
+// Checker detects retracted Go module versions and deprecated npm packages
+type Checker struct {
+	WorkingDir string
+	Options    Options
+
+	httpClient *http.Client
+}
+
+// NewChecker creates a new hygiene checker for a project directory
+func NewChecker(workingDir string, options Options) *Checker {
+	if options.GoProxy == "" {
+		options.GoProxy = os.Getenv("GOPROXY")
+	}
+	if options.GoProxy == "" {
+		options.GoProxy = DefaultGoProxy
+	}
+
+	return &Checker{
+		WorkingDir: workingDir,
+		Options:    options,
+		httpClient: &http.Client{Timeout: 15 * time.Second},
+	}
+}
+
+// Check runs all hygiene checks and returns findings along with diagnostics
+// for the lookups that could not be completed
+func (c *Checker) Check() ([]scanner.Vulnerability, []scanner.Diagnostic) {
+	var findings []scanner.Vulnerability
+	var diagnostics []scanner.Diagnostic
+
+	goFindings, goDiagnostics := c.checkGoRetractions()
+	findings = append(findings, goFindings...)
+	diagnostics = append(diagnostics, goDiagnostics...)
+
+	npmFindings, npmDiagnostics := c.checkNPMDeprecations()
+	findings = append(findings, npmFindings...)
+	diagnostics = append(diagnostics, npmDiagnostics...)
+
+	return findings, diagnostics
+}
+
+// resolvePath resolves a configured path relative to the working directory
+func (c *Checker) resolvePath(path string) string {
+	if path == "" || filepath.IsAbs(path) {
+		return path
+	}
+	return filepath.Join(c.WorkingDir, path)
+}
+
+// hygieneSeverity returns the base severity, raised to HIGH when the
+// author's message points at a security problem
+func hygieneSeverity(base, message string) string {
+	lower := strings.ToLower(message)
+	for _, keyword := range securityKeywords {
+		if strings.Contains(lower, keyword) {
+			return "HIGH"
+		}
+	}
+	return base
+}
+
+// lookupDiagnostic builds a diagnostic for a failed metadata lookup. Hygiene
+// checks are optional, so failures are warnings that leave the scan complete.
+func lookupDiagnostic(path string, err error) scanner.Diagnostic {
+	return scanner.Diagnostic{
+		Tool:    "dep-risk",
+		Kind:    scanner.DiagnosticWarning,
+		Message: fmt.Sprintf("hygiene check failed: %v", err),
+		Path:    path,
+	}
+}
@@ -0,0 +1,161 @@
+package hygiene
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/dep-risk/dep-risk/internal/scanner"
+)
+
+// writeFile creates a file and its parent directories for a test fixture
+func writeFile(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatalf("Failed to create directory: %v", err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatalf("Failed to write %s: %v", path, err)
+	}
+}
+
+func TestCheckGoRetractions(t *testing.T) {
+	projectDir := t.TempDir()
+	proxyDir := t.TempDir()
+
+	writeFile(t, filepath.Join(projectDir, "go.mod"), `module example.com/app
+
+go 1.21
+
+require (
+	github.com/Example/lib v1.2.0
+	example.com/stable v1.0.0 // indirect
+)`)
+
+	// Module paths with upper-case letters are escaped by the proxy protocol;
+	// retractions are read from the latest release, not a newer prerelease
+	writeFile(t, filepath.Join(proxyDir, "github.com/!example/lib/@v/list"), "v1.0.0\nv1.2.0\nv1.3.0\nv1.4.0-rc.1\n")
+	writeFile(t, filepath.Join(proxyDir, "github.com/!example/lib/@v/v1.3.0.mod"), `module github.com/Example/lib
+
+retract [v1.1.0, v1.2.5] // Security issue in request parsing
+`)
+	writeFile(t, filepath.Join(proxyDir, "example.com/stable/@v/list"), "v1.0.0\n")
+	writeFile(t, filepath.Join(proxyDir, "example.com/stable/@v/v1.0.0.mod"), "module example.com/stable\n")
+
+	checker := NewChecker(projectDir, Options{GoProxy: "file://" + proxyDir})
+	findings, diagnostics := checker.Check()
+
+	if len(diagnostics) != 0 {
+		t.Errorf("Expected no diagnostics, got %+v", diagnostics)
+	}
+	if len(findings) != 1 {
+		t.Fatalf("Expected 1 finding, got %d", len(findings))
+	}
+
+	finding := findings[0]
+	if finding.Package != "github.com/Example/lib" || finding.Version != "v1.2.0" {
+		t.Errorf("Unexpected finding package %s@%s", finding.Package, finding.Version)
+	}
+	if finding.Class != scanner.ClassHygiene {
+		t.Errorf("Expected class %s, got %s", scanner.ClassHygiene, finding.Class)
+	}
+	if finding.Severity != "HIGH" {
+		t.Errorf("Expected security rationale to raise severity to HIGH, got %s", finding.Severity)
+	}
+	if !finding.IsDirect {
+		t.Error("Expected required module to be direct")
+	}
+}
+
+func TestCheckGoRetractionsMissingModule(t *testing.T) {
+	projectDir := t.TempDir()
+	writeFile(t, filepath.Join(projectDir, "go.mod"), "module example.com/app\n\nrequire example.com/private v1.0.0\n")
+
+	checker := NewChecker(projectDir, Options{GoProxy: t.TempDir()})
+	findings, diagnostics := checker.Check()
+
+	if len(findings) != 0 {
+		t.Errorf("Expected no findings, got %d", len(findings))
+	}
+	if len(diagnostics) != 1 || diagnostics[0].Kind != scanner.DiagnosticWarning {
+		t.Errorf("Expected one warning diagnostic for the unknown module, got %+v", diagnostics)
+	}
+}
+
+func TestCheckGoRetractionsProxyFailure(t *testing.T) {
+	projectDir := t.TempDir()
+	writeFile(t, filepath.Join(projectDir, "go.mod"), "module example.com/app\n\nrequire (\n\texample.com/a v1.0.0\n\texample.com/b v1.0.0\n\texample.com/c v1.0.0\n)\n")
+
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer server.Close()
+
+	checker := NewChecker(projectDir, Options{GoProxy: server.URL})
+	_, diagnostics := checker.Check()
+
+	if requests != 1 {
+		t.Errorf("Expected lookups to stop after the first failure, got %d requests", requests)
+	}
+	if len(diagnostics) != 2 {
+		t.Fatalf("Expected a failure and a skipped diagnostic, got %+v", diagnostics)
+	}
+	if scanner.PartialScan(diagnostics) {
+		t.Errorf("Expected hygiene failures to leave the scan complete, got %+v", diagnostics)
+	}
+}
+
+func TestCheckNPMDeprecations(t *testing.T) {
+	projectDir := t.TempDir()
+	snapshotDir := t.TempDir()
+
+	writeFile(t, filepath.Join(projectDir, "package-lock.json"), `{
+		"lockfileVersion": 3,
+		"packages": {
+			"": {"dependencies": {"request": "^2.88.0"}},
+			"node_modules/request": {"version": "2.88.2"},
+			"node_modules/request/node_modules/uuid": {"version": "3.4.0"},
+			"node_modules/@scope/tool": {"version": "1.0.0"}
+		}
+	}`)
+	writeFile(t, filepath.Join(snapshotDir, "request.json"), `{
+		"versions": {"2.88.2": {"deprecated": "request has been deprecated"}}
+	}`)
+	writeFile(t, filepath.Join(snapshotDir, "uuid.json"), `{
+		"versions": {"3.4.0": {"deprecated": "Please upgrade, older versions use Math.random() which is a security risk"}}
+	}`)
+	writeFile(t, filepath.Join(snapshotDir, "@scope/tool.json"), `{"versions": {"1.0.0": {}}}`)
+
+	checker := NewChecker(projectDir, Options{NPMRegistrySnapshot: snapshotDir})
+	findings, diagnostics := checker.checkNPMDeprecations()
+
+	if len(diagnostics) != 0 {
+		t.Errorf("Expected no diagnostics, got %+v", diagnostics)
+	}
+	if len(findings) != 2 {
+		t.Fatalf("Expected 2 findings, got %d", len(findings))
+	}
+
+	byName := make(map[string]scanner.Vulnerability)
+	for _, finding := range findings {
+		byName[finding.Package] = finding
+	}
+
+	if request := byName["request"]; !request.IsDirect || request.Severity != "LOW" {
+		t.Errorf("Expected request to be a direct LOW finding, got direct=%v severity=%s", request.IsDirect, request.Severity)
+	}
+	if uuid := byName["uuid"]; uuid.IsDirect || uuid.Severity != "HIGH" {
+		t.Errorf("Expected uuid to be a transitive HIGH finding, got direct=%v severity=%s", uuid.IsDirect, uuid.Severity)
+	}
+}
+
+func TestSplitProxyList(t *testing.T) {
+	proxies := splitProxyList("https://proxy.example.com|file:///srv/proxy,direct")
+	if len(proxies) != 2 || proxies[0] != "https://proxy.example.com" || proxies[1] != "file:///srv/proxy" {
+		t.Errorf("Unexpected proxy list: %v", proxies)
+	}
+}
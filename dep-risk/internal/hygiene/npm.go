@@ -0,0 +1,188 @@
+package hygiene
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/dep-risk/dep-risk/internal/scanner"
+)
+
+// npmPackage is an installed package recorded in package-lock.json
+type npmPackage struct {
+	Name     string
+	Version  string
+	IsDirect bool
+}
+
+// npmLockfile is the subset of package-lock.json read by dep-risk
+type npmLockfile struct {
+	LockfileVersion int `json:"lockfileVersion"`
+	Packages        map[string]struct {
+		Version              string            `json:"version"`
+		Dependencies         map[string]string `json:"dependencies"`
+		DevDependencies      map[string]string `json:"devDependencies"`
+		OptionalDependencies map[string]string `json:"optionalDependencies"`
+		PeerDependencies     map[string]string `json:"peerDependencies"`
+	} `json:"packages"`
+	Dependencies map[string]npmLockDependency `json:"dependencies"`
+}
+
+// npmLockDependency is a lockfile v1 dependency entry
+type npmLockDependency struct {
+	Version      string                       `json:"version"`
+	Dependencies map[string]npmLockDependency `json:"dependencies"`
+}
+
+// npmPackument is the subset of npm registry metadata read by dep-risk
+type npmPackument struct {
+	Versions map[string]struct {
+		Deprecated string `json:"deprecated"`
+	} `json:"versions"`
+}
+
+// checkNPMDeprecations reports installed npm package versions marked deprecated
+// in the registry metadata snapshot
+func (c *Checker) checkNPMDeprecations() ([]scanner.Vulnerability, []scanner.Diagnostic) {
+	if c.Options.NPMRegistrySnapshot == "" {
+		return nil, nil
+	}
+
+	lockPath := filepath.Join(c.WorkingDir, "package-lock.json")
+	data, err := os.ReadFile(lockPath)
+	if err != nil {
+		// Not an npm project
+		return nil, nil
+	}
+
+	packages, err := parseNPMLockfile(data)
+	if err != nil {
+		return nil, []scanner.Diagnostic{lookupDiagnostic("package-lock.json", err)}
+	}
+
+	snapshotDir := c.resolvePath(c.Options.NPMRegistrySnapshot)
+	packuments := make(map[string]*npmPackument)
+
+	var findings []scanner.Vulnerability
+	var diagnostics []scanner.Diagnostic
+
+	for _, pkg := range packages {
+		packument, cached := packuments[pkg.Name]
+		if !cached {
+			packument, err = loadPackument(snapshotDir, pkg.Name)
+			if err != nil {
+				diagnostics = append(diagnostics, lookupDiagnostic("package-lock.json", err))
+			}
+			packuments[pkg.Name] = packument
+		}
+		if packument == nil {
+			continue
+		}
+
+		message := packument.Versions[pkg.Version].Deprecated
+		if message == "" {
+			continue
+		}
+
+		findings = append(findings, scanner.Vulnerability{
+			ID:          fmt.Sprintf("DEPRECATED-%s@%s", pkg.Name, pkg.Version),
+			Package:     pkg.Name,
+			Version:     pkg.Version,
+			Severity:    hygieneSeverity("LOW", message),
+			Summary:     fmt.Sprintf("%s@%s is deprecated: %s", pkg.Name, pkg.Version, message),
+			Description: "The package maintainer marked this version as deprecated in the npm registry. Deprecated packages no longer receive fixes.",
+			References:  []string{fmt.Sprintf("https://www.npmjs.com/package/%s/v/%s", pkg.Name, pkg.Version)},
+			IsDirect:    pkg.IsDirect,
+			Ecosystem:   "npm",
+			Class:       scanner.ClassHygiene,
+		})
+	}
+
+	return findings, diagnostics
+}
+
+// loadPackument reads the registry metadata snapshot for a package. Packages
+// missing from the snapshot return nil without an error.
+func loadPackument(snapshotDir, name string) (*npmPackument, error) {
+	data, err := os.ReadFile(filepath.Join(snapshotDir, filepath.FromSlash(name)+".json"))
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var packument npmPackument
+	if err := json.Unmarshal(data, &packument); err != nil {
+		return nil, fmt.Errorf("invalid registry metadata for %s: %w", name, err)
+	}
+	return &packument, nil
+}
+
+// parseNPMLockfile lists the installed packages of a package-lock.json,
+// supporting both the v1 dependency tree and the v2/v3 packages map
+func parseNPMLockfile(data []byte) ([]npmPackage, error) {
+	var lock npmLockfile
+	if err := json.Unmarshal(data, &lock); err != nil {
+		return nil, fmt.Errorf("failed to parse package-lock.json: %w", err)
+	}
+
+	seen := make(map[string]bool)
+	var packages []npmPackage
+	add := func(name, version string, direct bool) {
+		key := name + "@" + version
+		if name == "" || version == "" || seen[key] {
+			return
+		}
+		seen[key] = true
+		packages = append(packages, npmPackage{Name: name, Version: version, IsDirect: direct})
+	}
+
+	if len(lock.Packages) > 0 {
+		root := lock.Packages[""]
+		direct := make(map[string]bool)
+		for _, deps := range []map[string]string{root.Dependencies, root.DevDependencies, root.OptionalDependencies, root.PeerDependencies} {
+			for name := range deps {
+				direct[name] = true
+			}
+		}
+
+		paths := make([]string, 0, len(lock.Packages))
+		for path := range lock.Packages {
+			paths = append(paths, path)
+		}
+		sort.Strings(paths)
+
+		for _, path := range paths {
+			idx := strings.LastIndex(path, "node_modules/")
+			if idx < 0 {
+				continue
+			}
+			name := path[idx+len("node_modules/"):]
+			topLevel := idx == 0
+			add(name, lock.Packages[path].Version, topLevel && direct[name])
+		}
+		return packages, nil
+	}
+
+	// Lockfile v1 has no root entry; hoisted top-level entries are treated as direct
+	var walk func(deps map[string]npmLockDependency, topLevel bool)
+	walk = func(deps map[string]npmLockDependency, topLevel bool) {
+		names := make([]string, 0, len(deps))
+		for name := range deps {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+
+		for _, name := range names {
+			add(name, deps[name].Version, topLevel)
+			walk(deps[name].Dependencies, false)
+		}
+	}
+	walk(lock.Dependencies, true)
+
+	return packages, nil
+}
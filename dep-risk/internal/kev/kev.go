@@ -0,0 +1,100 @@
+package kev
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/dep-risk/dep-risk/internal/scanner"
+)
+
+// DefaultURL is the feed of the CISA Known Exploited Vulnerabilities catalog
+const DefaultURL = "https://www.cisa.gov/sites/default/files/feeds/known_exploited_vulnerabilities.json"
+
+// Entry is a vulnerability listed in the catalog
+type Entry struct {
+	CVEID                      string `json:"cveID"`
+	VendorProject              string `json:"vendorProject"`
+	Product                    string `json:"product"`
+	DateAdded                  string `json:"dateAdded"`
+	DueDate                    string `json:"dueDate"`
+	KnownRansomwareCampaignUse string `json:"knownRansomwareCampaignUse"`
+}
+
+// Catalog is a Known Exploited Vulnerabilities catalog in the CISA format
+type Catalog struct {
+	CatalogVersion  string  `json:"catalogVersion"`
+	DateReleased    string  `json:"dateReleased"`
+	Vulnerabilities []Entry `json:"vulnerabilities"`
+
+	index map[string]Entry
+}
+
+// Load reads a catalog from a local file
+func Load(path string) (*Catalog, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read KEV catalog: %w", err)
+	}
+	return Parse(data)
+}
+
+// Fetch downloads a catalog
+func Fetch(url string) (*Catalog, error) {
+	client := &http.Client{Timeout: 30 * time.Second}
+	resp, err := client.Get(url)
+	if err != nil {
+		return nil, fmt.Errorf("failed to download KEV catalog: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to download KEV catalog: GET %s returned status %d", url, resp.StatusCode)
+	}
+
+	data, err := io.ReadAll(io.LimitReader(resp.Body, 32<<20))
+	if err != nil {
+		return nil, fmt.Errorf("failed to download KEV catalog: %w", err)
+	}
+	return Parse(data)
+}
+
+// Parse decodes a catalog and indexes it by CVE ID
+func Parse(data []byte) (*Catalog, error) {
+	var catalog Catalog
+	if err := json.Unmarshal(data, &catalog); err != nil {
+		return nil, fmt.Errorf("failed to parse KEV catalog: %w", err)
+	}
+
+	catalog.index = make(map[string]Entry, len(catalog.Vulnerabilities))
+	for _, entry := range catalog.Vulnerabilities {
+		catalog.index[strings.ToUpper(entry.CVEID)] = entry
+	}
+	return &catalog, nil
+}
+
+// Lookup finds a finding in the catalog by its ID or one of its aliases
+func (c *Catalog) Lookup(vuln scanner.Vulnerability) (Entry, bool) {
+	for _, id := range append([]string{vuln.ID}, vuln.Aliases...) {
+		if entry, ok := c.index[strings.ToUpper(id)]; ok {
+			return entry, true
+		}
+	}
+	return Entry{}, false
+}
+
+// Annotate marks the findings listed in the catalog and returns how many
+// there are
+func (c *Catalog) Annotate(vulns []scanner.Vulnerability) int {
+	count := 0
+	for i := range vulns {
+		if _, ok := c.Lookup(vulns[i]); ok {
+			vulns[i].KEV = true
+			count++
+		}
+	}
+	return count
+}
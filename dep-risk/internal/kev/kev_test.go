@@ -0,0 +1,75 @@
+package kev
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/dep-risk/dep-risk/internal/scanner"
+)
+
+const testCatalog = `{
+  "catalogVersion": "2026.10.16",
+  "dateReleased": "2026-10-16T17:00:00.000Z",
+  "vulnerabilities": [
+    {"cveID": "CVE-2021-44228", "vendorProject": "Apache", "product": "Log4j2", "dateAdded": "2021-12-10", "dueDate": "2021-12-24", "knownRansomwareCampaignUse": "Known"},
+    {"cveID": "CVE-2022-22965", "vendorProject": "VMware", "product": "Spring Framework", "dateAdded": "2022-04-04", "dueDate": "2022-04-25", "knownRansomwareCampaignUse": "Unknown"}
+  ]
+}`
+
+func TestAnnotate(t *testing.T) {
+	catalog, err := Parse([]byte(testCatalog))
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+
+	vulns := []scanner.Vulnerability{
+		{ID: "CVE-2021-44228"},
+		{ID: "GHSA-36p3-wjmg-h94x", Aliases: []string{"cve-2022-22965"}},
+		{ID: "GHSA-xxxx-xxxx-xxxx"},
+	}
+	if count := catalog.Annotate(vulns); count != 2 {
+		t.Errorf("Expected 2 known exploited findings, got %d", count)
+	}
+	if !vulns[0].KEV || !vulns[1].KEV || vulns[2].KEV {
+		t.Errorf("Unexpected KEV flags: %v %v %v", vulns[0].KEV, vulns[1].KEV, vulns[2].KEV)
+	}
+
+	entry, ok := catalog.Lookup(vulns[1])
+	if !ok || entry.Product != "Spring Framework" {
+		t.Errorf("Expected lookup by alias, got %+v", entry)
+	}
+}
+
+func TestLoadAndFetch(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "kev.json")
+	if err := os.WriteFile(path, []byte(testCatalog), 0644); err != nil {
+		t.Fatalf("Failed to write catalog: %v", err)
+	}
+	catalog, err := Load(path)
+	if err != nil || len(catalog.Vulnerabilities) != 2 {
+		t.Fatalf("Load failed: %v", err)
+	}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/kev.json" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Write([]byte(testCatalog))
+	}))
+	defer server.Close()
+
+	catalog, err = Fetch(server.URL + "/kev.json")
+	if err != nil || catalog.CatalogVersion != "2026.10.16" {
+		t.Fatalf("Fetch failed: %v", err)
+	}
+	if _, err := Fetch(server.URL + "/missing.json"); err == nil {
+		t.Error("Expected an error for a missing catalog")
+	}
+	if _, err := Parse([]byte("not json")); err == nil {
+		t.Error("Expected an error for an invalid catalog")
+	}
+}
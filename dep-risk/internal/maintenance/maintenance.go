@@ -0,0 +1,189 @@
+package maintenance
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/dep-risk/dep-risk/internal/scorer"
+)
+
+// scorecardResult is the subset of an OpenSSF Scorecard JSON result read by dep-risk
+type scorecardResult struct {
+	Repo struct {
+		Name string `json:"name"`
+	} `json:"repo"`
+	Score  float64 `json:"score"`
+	Checks []struct {
+		Name  string  `json:"name"`
+		Score float64 `json:"score"`
+	} `json:"checks"`
+}
+
+// ReleaseMetadata describes the release cadence of a package
+type ReleaseMetadata struct {
+	Repository       string `json:"repository"`
+	Archived         bool   `json:"archived"`
+	LastRelease      string `json:"last_release"`
+	ReleasesLastYear int    `json:"releases_last_year"`
+}
+
+// Index serves maintenance health from imported Scorecard results and
+// release metadata
+type Index struct {
+	scorecards map[string]scorecardResult
+	releases   map[string]map[string]ReleaseMetadata
+}
+
+// Load reads Scorecard results (a JSON file or a directory of them) and a
+// release metadata file keyed by ecosystem and package. Either path may be empty.
+func Load(scorecardPath, releasePath string) (*Index, error) {
+	index := &Index{
+		scorecards: make(map[string]scorecardResult),
+		releases:   make(map[string]map[string]ReleaseMetadata),
+	}
+
+	if scorecardPath != "" {
+		if err := index.loadScorecards(scorecardPath); err != nil {
+			return nil, err
+		}
+	}
+
+	if releasePath != "" {
+		data, err := os.ReadFile(releasePath)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read release metadata: %w", err)
+		}
+		var raw map[string]map[string]ReleaseMetadata
+		if err := json.Unmarshal(data, &raw); err != nil {
+			return nil, fmt.Errorf("failed to parse release metadata: %w", err)
+		}
+		for ecosystem, packages := range raw {
+			for name, metadata := range packages {
+				if metadata.LastRelease != "" {
+					if _, err := time.Parse("2006-01-02", metadata.LastRelease); err != nil {
+						return nil, fmt.Errorf("invalid last_release %q for %s %s", metadata.LastRelease, ecosystem, name)
+					}
+				}
+			}
+			index.releases[strings.ToLower(ecosystem)] = packages
+		}
+	}
+
+	return index, nil
+}
+
+// loadScorecards imports Scorecard results from a file or directory
+func (i *Index) loadScorecards(path string) error {
+	info, err := os.Stat(path)
+	if err != nil {
+		return fmt.Errorf("failed to read Scorecard results: %w", err)
+	}
+
+	files := []string{path}
+	if info.IsDir() {
+		files, err = filepath.Glob(filepath.Join(path, "*.json"))
+		if err != nil {
+			return err
+		}
+	}
+
+	for _, file := range files {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			return fmt.Errorf("failed to read Scorecard results: %w", err)
+		}
+
+		// A file holds one result or an array of them
+		var results []scorecardResult
+		if strings.HasPrefix(strings.TrimSpace(string(data)), "[") {
+			err = json.Unmarshal(data, &results)
+		} else {
+			var result scorecardResult
+			err = json.Unmarshal(data, &result)
+			results = append(results, result)
+		}
+		if err != nil {
+			return fmt.Errorf("failed to parse Scorecard results %s: %w", filepath.Base(file), err)
+		}
+
+		for _, result := range results {
+			if result.Repo.Name != "" {
+				i.scorecards[normalizeRepository(result.Repo.Name)] = result
+			}
+		}
+	}
+
+	return nil
+}
+
+// Maintenance returns the maintenance health of a package
+func (i *Index) Maintenance(ecosystem, name string) (*scorer.MaintenanceInfo, error) {
+	metadata, hasMetadata := i.releases[strings.ToLower(ecosystem)][name]
+
+	repository := normalizeRepository(metadata.Repository)
+	if repository == "" {
+		repository = inferRepository(ecosystem, name)
+	}
+	scorecard, hasScorecard := i.scorecards[repository]
+
+	if !hasMetadata && !hasScorecard {
+		return nil, nil
+	}
+
+	info := &scorer.MaintenanceInfo{
+		Repository:       repository,
+		Archived:         metadata.Archived,
+		ReleasesLastYear: metadata.ReleasesLastYear,
+	}
+	if metadata.LastRelease != "" {
+		lastRelease, _ := time.Parse("2006-01-02", metadata.LastRelease)
+		info.LastRelease = &lastRelease
+	}
+
+	if hasScorecard {
+		// Scorecard reports -1 for checks it could not evaluate
+		if scorecard.Score >= 0 {
+			score := scorecard.Score
+			info.ScorecardScore = &score
+		}
+		for _, check := range scorecard.Checks {
+			if check.Name == "Maintained" && check.Score >= 0 {
+				score := check.Score
+				info.MaintainedScore = &score
+			}
+		}
+	}
+
+	return info, nil
+}
+
+// inferRepository derives the source repository from package names that embed it
+func inferRepository(ecosystem, name string) string {
+	switch {
+	case strings.EqualFold(ecosystem, "GitHub Actions"):
+		parts := strings.Split(name, "/")
+		if len(parts) >= 2 {
+			return normalizeRepository("github.com/" + parts[0] + "/" + parts[1])
+		}
+	case strings.HasPrefix(name, "github.com/"):
+		parts := strings.Split(name, "/")
+		if len(parts) >= 3 {
+			return normalizeRepository(strings.Join(parts[:3], "/"))
+		}
+	}
+	return ""
+}
+
+// normalizeRepository reduces repository URLs to host/owner/repo
+func normalizeRepository(repository string) string {
+	repository = strings.ToLower(strings.TrimSpace(repository))
+	repository = strings.TrimPrefix(repository, "git+")
+	for _, prefix := range []string{"https://", "http://", "git://", "ssh://git@"} {
+		repository = strings.TrimPrefix(repository, prefix)
+	}
+	return strings.TrimSuffix(strings.TrimSuffix(repository, "/"), ".git")
+}
@@ -0,0 +1,78 @@
+package maintenance
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// writeFile creates a file and its parent directories for a test fixture
+func writeFile(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatalf("Failed to create directory: %v", err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatalf("Failed to write %s: %v", path, err)
+	}
+}
+
+func TestLoad(t *testing.T) {
+	dir := t.TempDir()
+	scorecardDir := filepath.Join(dir, "scorecards")
+	writeFile(t, filepath.Join(scorecardDir, "request.json"), `{
+		"repo": {"name": "github.com/request/request"},
+		"score": 3.1,
+		"checks": [{"name": "Maintained", "score": 0}, {"name": "Fuzzing", "score": -1}]
+	}`)
+	writeFile(t, filepath.Join(scorecardDir, "more.json"), `[
+		{"repo": {"name": "github.com/gin-gonic/gin"}, "score": 6.5, "checks": [{"name": "Maintained", "score": -1}]},
+		{"repo": {"name": "github.com/actions/checkout"}, "score": 8.0, "checks": []}
+	]`)
+
+	releasePath := filepath.Join(dir, "releases.json")
+	writeFile(t, releasePath, `{
+		"npm": {
+			"request": {"repository": "git+https://github.com/request/request.git", "archived": true, "last_release": "2020-02-11"},
+			"left-pad": {"last_release": "2018-04-05"}
+		}
+	}`)
+
+	index, err := Load(scorecardDir, releasePath)
+	if err != nil {
+		t.Fatalf("Failed to load maintenance data: %v", err)
+	}
+
+	request, _ := index.Maintenance("npm", "request")
+	if request == nil || !request.Archived || request.ScorecardScore == nil || *request.ScorecardScore != 3.1 ||
+		request.MaintainedScore == nil || *request.MaintainedScore != 0 {
+		t.Errorf("Unexpected request maintenance: %+v", request)
+	}
+
+	leftPad, _ := index.Maintenance("npm", "left-pad")
+	if leftPad == nil || leftPad.LastRelease == nil || leftPad.ScorecardScore != nil {
+		t.Errorf("Expected release metadata without Scorecard for left-pad, got %+v", leftPad)
+	}
+
+	// Go modules and actions are matched to Scorecard results by repository
+	gin, _ := index.Maintenance("Go", "github.com/gin-gonic/gin/binding")
+	if gin == nil || gin.ScorecardScore == nil || *gin.ScorecardScore != 6.5 || gin.MaintainedScore != nil {
+		t.Errorf("Unexpected gin maintenance: %+v", gin)
+	}
+	if checkout, _ := index.Maintenance("GitHub Actions", "actions/checkout"); checkout == nil {
+		t.Error("Expected Scorecard result for actions/checkout")
+	}
+
+	if unknown, err := index.Maintenance("npm", "unknown"); unknown != nil || err != nil {
+		t.Errorf("Expected unknown package to return nil, got %+v, %v", unknown, err)
+	}
+}
+
+func TestLoadRejectsInvalidDates(t *testing.T) {
+	releasePath := filepath.Join(t.TempDir(), "releases.json")
+	writeFile(t, releasePath, `{"npm": {"lib": {"last_release": "last year"}}}`)
+
+	if _, err := Load("", releasePath); err == nil {
+		t.Error("Expected an error for an invalid release date")
+	}
+}
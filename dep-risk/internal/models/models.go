@@ -1,6 +1,7 @@
 package models
 
 import (
+	"encoding/json"
 	"time"
 )
 
@@ -43,6 +44,8 @@ type Scan struct {
 	MediumRiskCount      int       `json:"medium_risk_count"`
 	LowRiskCount         int       `json:"low_risk_count"`
 	ScanDuration         int       `json:"scan_duration"` // seconds
+	ModelVersion         string    `json:"model_version" gorm:"size:20"`
+	ConfigHash           string    `json:"config_hash" gorm:"size:64"`
 	CreatedAt            time.Time `json:"created_at"`
 	UpdatedAt            time.Time `json:"updated_at"`
 
@@ -63,6 +66,8 @@ type Vulnerability struct {
 	Severity       string    `json:"severity" gorm:"size:20"`
 	IsDirect       bool      `json:"is_direct"`
 	Description    string    `json:"description" gorm:"type:text"`
+	Explanation    json.RawMessage `json:"explanation,omitempty" gorm:"type:jsonb"`
+	Finding        json.RawMessage `json:"finding,omitempty" gorm:"type:jsonb"`
 	CreatedAt      time.Time `json:"created_at"`
 	UpdatedAt      time.Time `json:"updated_at"`
 
@@ -70,6 +75,19 @@ type Vulnerability struct {
 	Scan *Scan `json:"scan,omitempty" gorm:"foreignKey:ScanID"`
 }
 
+// Policy is an organization-wide base config that repository configs extend
+type Policy struct {
+	ID        uint      `json:"id" gorm:"primaryKey"`
+	OrgID     uint      `json:"org_id" gorm:"not null;uniqueIndex:idx_policies_org_name"`
+	Name      string    `json:"name" gorm:"size:100;not null;uniqueIndex:idx_policies_org_name"`
+	Content   string    `json:"content" gorm:"type:text"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+
+	// Relationships
+	Organization *Organization `json:"organization,omitempty" gorm:"foreignKey:OrgID"`
+}
+
 // DashboardData represents aggregated data for the dashboard
 type DashboardData struct {
 	Organization     *Organization          `json:"organization"`
@@ -124,6 +142,8 @@ type ScanResultPayload struct {
 	MediumRiskCount      int     `json:"medium_risk_count"`
 	LowRiskCount         int     `json:"low_risk_count"`
 	ScanDuration         int     `json:"scan_duration"`
+	ModelVersion         string  `json:"model_version"`
+	ConfigHash           string  `json:"config_hash"`
 }
 
 // VulnerabilityPayload represents vulnerability data in the request
@@ -136,6 +156,32 @@ type VulnerabilityPayload struct {
 	Severity       string  `json:"severity"`
 	IsDirect       bool    `json:"is_direct"`
 	Description    string  `json:"description"`
+	Explanation    json.RawMessage `json:"explanation,omitempty"`
+	Finding        json.RawMessage `json:"finding,omitempty"`
+}
+
+// RescoreResult compares a stored scan with its findings scored under a scoring model
+type RescoreResult struct {
+	ScanID             uint                    `json:"scan_id"`
+	StoredModelVersion string                  `json:"stored_model_version"`
+	StoredConfigHash   string                  `json:"stored_config_hash"`
+	StoredRiskScore    float64                 `json:"stored_risk_score"`
+	ModelVersion       string                  `json:"model_version"`
+	ConfigHash         string                  `json:"config_hash"`
+	RiskScore          float64                 `json:"risk_score"`
+	Delta              float64                 `json:"delta"`
+	Vulnerabilities    []RescoredVulnerability `json:"vulnerabilities"`
+}
+
+// RescoredVulnerability compares the stored and the new score of a finding
+type RescoredVulnerability struct {
+	ID              uint    `json:"id"`
+	CVEID           string  `json:"cve_id"`
+	PackageName     string  `json:"package_name"`
+	PackageVersion  string  `json:"package_version"`
+	StoredRiskScore float64 `json:"stored_risk_score"`
+	RiskScore       float64 `json:"risk_score"`
+	Delta           float64 `json:"delta"`
 }
 
 // APIResponse represents a standard API response
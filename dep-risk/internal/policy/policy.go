@@ -0,0 +1,263 @@
+// Package policy evaluates policy files against the scored report. Rules are
+// CEL expressions over the report as written to dep-risk-report.json, the run
+// and the current time, and each rule that matches returns a deny or warn
+// decision with a message.
+package policy
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/google/cel-go/cel"
+	"github.com/google/cel-go/common/types"
+	"github.com/google/cel-go/ext"
+	"gopkg.in/yaml.v3"
+
+	"github.com/dep-risk/dep-risk/internal/scorer"
+)
+
+// Rule is a rule of a policy file
+type Rule struct {
+	Name   string `yaml:"name"`
+	Action string `yaml:"action"`
+	// Condition is a CEL expression returning a bool; the rule decides when
+	// it is true
+	Condition string `yaml:"condition"`
+	// Message is a CEL expression returning a string, so messages can name
+	// the findings concerned; the rule name is used when it is empty
+	Message string `yaml:"message"`
+}
+
+// File is the format of a policy file
+type File struct {
+	Rules []Rule `yaml:"rules"`
+}
+
+// Run describes the run policies are evaluated for
+type Run struct {
+	Branch string
+	Event  string
+}
+
+// Policy is a compiled policy file
+type Policy struct {
+	Path  string
+	rules []rule
+}
+
+// rule is a compiled rule
+type rule struct {
+	Rule
+	condition cel.Program
+	message   cel.Program
+}
+
+// newEnv declares the variables and functions available to policies
+func newEnv() (*cel.Env, error) {
+	return cel.NewEnv(
+		cel.Variable("report", cel.MapType(cel.StringType, cel.DynType)),
+		cel.Variable("run", cel.MapType(cel.StringType, cel.StringType)),
+		cel.Variable("now", cel.TimestampType),
+		cel.CrossTypeNumericComparisons(true),
+		ext.Strings(),
+		ext.Lists(),
+	)
+}
+
+// Load reads and compiles a policy file
+func Load(path string) (*Policy, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read policy: %w", err)
+	}
+	policy, err := Parse(data, path)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return policy, nil
+}
+
+// Parse compiles a policy file. Conditions and messages are type-checked, so
+// mistakes surface before the scan runs.
+func Parse(data []byte, path string) (*Policy, error) {
+	var file File
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	decoder.KnownFields(true)
+	if err := decoder.Decode(&file); err != nil {
+		return nil, fmt.Errorf("failed to parse policy: %w", err)
+	}
+	if len(file.Rules) == 0 {
+		return nil, fmt.Errorf("policy has no rules")
+	}
+
+	env, err := newEnv()
+	if err != nil {
+		return nil, err
+	}
+
+	policy := &Policy{Path: path}
+	names := make(map[string]bool)
+	for i, spec := range file.Rules {
+		if strings.TrimSpace(spec.Name) == "" {
+			return nil, fmt.Errorf("rules[%d]: name is required", i)
+		}
+		if names[spec.Name] {
+			return nil, fmt.Errorf("rule %s: duplicate name", spec.Name)
+		}
+		names[spec.Name] = true
+		if spec.Action != scorer.DecisionDeny && spec.Action != scorer.DecisionWarn {
+			return nil, fmt.Errorf("rule %s: action must be %s or %s", spec.Name, scorer.DecisionDeny, scorer.DecisionWarn)
+		}
+
+		compiled := rule{Rule: spec}
+		compiled.condition, err = compile(env, spec.Condition, cel.BoolType)
+		if err != nil {
+			return nil, fmt.Errorf("rule %s: invalid condition: %w", spec.Name, err)
+		}
+		if spec.Message != "" {
+			compiled.message, err = compile(env, spec.Message, cel.StringType)
+			if err != nil {
+				return nil, fmt.Errorf("rule %s: invalid message: %w", spec.Name, err)
+			}
+		}
+		policy.rules = append(policy.rules, compiled)
+	}
+	return policy, nil
+}
+
+// compile checks that an expression returns the wanted type
+func compile(env *cel.Env, source string, want *cel.Type) (cel.Program, error) {
+	if strings.TrimSpace(source) == "" {
+		return nil, fmt.Errorf("expression is required")
+	}
+	ast, issues := env.Compile(source)
+	if issues != nil && issues.Err() != nil {
+		return nil, issues.Err()
+	}
+	// Values read from the report are dynamic, so they are checked at evaluation
+	if got := ast.OutputType(); !got.IsExactType(want) && !got.IsExactType(cel.DynType) {
+		return nil, fmt.Errorf("expression must return a %s, got %s", want, got)
+	}
+	return env.Program(ast)
+}
+
+// Evaluate returns the decisions of the rules whose condition holds for the
+// policy input. A rule that fails to evaluate, for example by reading a field
+// the report does not have, denies: a broken policy must not pass silently.
+func (p *Policy) Evaluate(input map[string]interface{}) []scorer.PolicyDecision {
+	var decisions []scorer.PolicyDecision
+	for _, rule := range p.rules {
+		decision := scorer.PolicyDecision{Policy: p.Path, Rule: rule.Name, Action: rule.Action, Message: rule.Name}
+
+		matched, err := evalBool(rule.condition, input)
+		if err != nil {
+			decision.Action = scorer.DecisionDeny
+			decision.Message = fmt.Sprintf("condition could not be evaluated: %v", err)
+			decisions = append(decisions, decision)
+			continue
+		}
+		if !matched {
+			continue
+		}
+
+		if rule.message != nil {
+			message, err := evalString(rule.message, input)
+			if err != nil {
+				message = fmt.Sprintf("%s (message could not be evaluated: %v)", rule.Name, err)
+			}
+			decision.Message = message
+		}
+		decisions = append(decisions, decision)
+	}
+	return decisions
+}
+
+// reportLists are the lists of the report that policies can iterate over
+var reportLists = []string{"vulnerability_scores", "diagnostics", "expired_ignores", "ignored_findings", "unjustified_ignores", "gates", "decisions"}
+
+// Input converts a report to the variables of policy expressions. The report
+// goes through JSON, so policies see the fields of dep-risk-report.json.
+func Input(report *scorer.ProjectRiskScore, run Run, now time.Time) (map[string]interface{}, error) {
+	data, err := json.Marshal(report)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode report: %w", err)
+	}
+	var decoded map[string]interface{}
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		return nil, fmt.Errorf("failed to encode report: %w", err)
+	}
+	// Lists are empty rather than null or missing, so macros such as exists
+	// work on a clean report
+	for _, key := range reportLists {
+		if decoded[key] == nil {
+			decoded[key] = []interface{}{}
+		}
+	}
+	// Every finding has a package with a license list, so license rules need
+	// no has() guard
+	for _, item := range decoded["vulnerability_scores"].([]interface{}) {
+		score, ok := item.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		pkg, ok := score["package"].(map[string]interface{})
+		if !ok {
+			pkg = map[string]interface{}{}
+			score["package"] = pkg
+		}
+		if pkg["licenses"] == nil {
+			pkg["licenses"] = []interface{}{}
+		}
+	}
+
+	return map[string]interface{}{
+		"report": decoded,
+		"run":    map[string]string{"branch": run.Branch, "event": run.Event},
+		"now":    now,
+	}, nil
+}
+
+// evalBool evaluates a condition
+func evalBool(program cel.Program, input map[string]interface{}) (bool, error) {
+	value, _, err := program.Eval(input)
+	if err != nil {
+		return false, err
+	}
+	matched, ok := value.(types.Bool)
+	if !ok {
+		return false, fmt.Errorf("condition returned %s, not a bool", value.Type())
+	}
+	return bool(matched), nil
+}
+
+// evalString evaluates a message
+func evalString(program cel.Program, input map[string]interface{}) (string, error) {
+	value, _, err := program.Eval(input)
+	if err != nil {
+		return "", err
+	}
+	message, ok := value.(types.String)
+	if !ok {
+		return "", fmt.Errorf("message returned %s, not a string", value.Type())
+	}
+	return string(message), nil
+}
+
+// EvaluateAll evaluates policies in order against a report and collects
+// their decisions
+func EvaluateAll(policies []*Policy, report *scorer.ProjectRiskScore, run Run, now time.Time) ([]scorer.PolicyDecision, error) {
+	input, err := Input(report, run, now)
+	if err != nil {
+		return nil, err
+	}
+
+	var decisions []scorer.PolicyDecision
+	for _, policy := range policies {
+		decisions = append(decisions, policy.Evaluate(input)...)
+	}
+	return decisions, nil
+}
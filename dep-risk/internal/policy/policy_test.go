@@ -0,0 +1,148 @@
+package policy
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/dep-risk/dep-risk/internal/scanner"
+	"github.com/dep-risk/dep-risk/internal/scorer"
+)
+
+const testPolicy = `
+rules:
+  - name: reachable-critical
+    action: deny
+    condition: |
+      report.vulnerability_scores.exists(s,
+        s.vulnerability.severity == "CRITICAL" && s.vulnerability.reachability == "reachable")
+    message: |
+      "reachable critical findings: " + report.vulnerability_scores
+        .filter(s, s.vulnerability.severity == "CRITICAL" && s.vulnerability.reachability == "reachable")
+        .map(s, s.vulnerability.id).join(", ")
+  - name: old-advisories
+    action: warn
+    condition: |
+      report.vulnerability_scores.exists(s, has(s.vulnerability.published) &&
+        now - timestamp(s.vulnerability.published) > duration("8760h"))
+  - name: high-score-on-main
+    action: deny
+    condition: run.branch == "main" && report.overall_score >= 9
+  - name: young-copyleft
+    action: warn
+    condition: |
+      report.vulnerability_scores.exists(s, s.package.licenses.exists(l, l.contains("GPL")) &&
+        has(s.package.age_months) && s.package.age_months < 6)
+    message: |
+      "young copyleft packages: " + report.vulnerability_scores
+        .filter(s, s.package.licenses.exists(l, l.contains("GPL")))
+        .map(s, s.vulnerability.package).join(", ")
+`
+
+func testReport() *scorer.ProjectRiskScore {
+	published := time.Date(2024, 1, 10, 0, 0, 0, 0, time.UTC)
+	return &scorer.ProjectRiskScore{
+		OverallScore: 8.5,
+		VulnerabilityScores: []scorer.RiskScore{
+			{Overall: 8.5, Vulnerability: scanner.Vulnerability{ID: "CVE-1", Severity: "CRITICAL", Reachability: "reachable", Published: &published}},
+			{Overall: 6.0, Vulnerability: scanner.Vulnerability{ID: "CVE-2", Package: "left-pad", Severity: "CRITICAL", Reachability: "unreachable"},
+				Package: scorer.PackageInfo{Licenses: []string{"GPL-3.0-only"}, AgeMonths: 3}},
+			{Overall: 7.0, Vulnerability: scanner.Vulnerability{ID: "CVE-3", Severity: "CRITICAL", Reachability: "reachable"}},
+		},
+	}
+}
+
+func TestEvaluate(t *testing.T) {
+	policy, err := Parse([]byte(testPolicy), "policy.yml")
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+
+	now := time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC)
+	decisions, err := EvaluateAll([]*Policy{policy}, testReport(), Run{Branch: "main", Event: "push"}, now)
+	if err != nil {
+		t.Fatalf("EvaluateAll failed: %v", err)
+	}
+	expected := []scorer.PolicyDecision{
+		{Policy: "policy.yml", Rule: "reachable-critical", Action: "deny", Message: "reachable critical findings: CVE-1, CVE-3"},
+		{Policy: "policy.yml", Rule: "old-advisories", Action: "warn", Message: "old-advisories"},
+		{Policy: "policy.yml", Rule: "young-copyleft", Action: "warn", Message: "young copyleft packages: left-pad"},
+	}
+	if !reflect.DeepEqual(decisions, expected) {
+		t.Errorf("Expected %+v, got %+v", expected, decisions)
+	}
+
+	// Numbers from the report compare with integer literals
+	report := testReport()
+	report.OverallScore = 9.2
+	decisions, _ = EvaluateAll([]*Policy{policy}, report, Run{Branch: "main"}, now)
+	if len(decisions) != 4 || decisions[2].Rule != "high-score-on-main" {
+		t.Errorf("Expected the score rule to deny on main, got %+v", decisions)
+	}
+}
+
+func TestEvaluateEmptyReport(t *testing.T) {
+	policy, err := Parse([]byte(testPolicy+`
+  - name: failed-gates
+    action: deny
+    condition: report.gates.exists(g, !g.passed) || size(report.diagnostics) > 0
+`), "policy.yml")
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+
+	decisions, err := EvaluateAll([]*Policy{policy}, &scorer.ProjectRiskScore{}, Run{Branch: "main"}, time.Now())
+	if err != nil {
+		t.Fatalf("EvaluateAll failed: %v", err)
+	}
+	if len(decisions) != 0 {
+		t.Errorf("Expected a clean report to pass, got %+v", decisions)
+	}
+}
+
+func TestEvaluateErrorsDeny(t *testing.T) {
+	policy, err := Parse([]byte("rules:\n  - name: missing\n    action: warn\n    condition: report.no_such_field > 1\n"), "policy.yml")
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+	input, err := Input(testReport(), Run{}, time.Now())
+	if err != nil {
+		t.Fatal(err)
+	}
+	decisions := policy.Evaluate(input)
+	if len(decisions) != 1 || decisions[0].Action != "deny" || !strings.Contains(decisions[0].Message, "could not be evaluated") {
+		t.Errorf("Expected a failing rule to deny, got %+v", decisions)
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	tests := []struct {
+		policy string
+		errMsg string
+	}{
+		{"rules: []\n", "policy has no rules"},
+		{"rule:\n  - name: a\n", "field rule not found"},
+		{"rules:\n  - action: deny\n    condition: 'true'\n", "rules[0]: name is required"},
+		{"rules:\n  - name: a\n    action: block\n    condition: 'true'\n", "action must be deny or warn"},
+		{"rules:\n  - name: a\n    action: deny\n    condition: 'true'\n  - name: a\n    action: warn\n    condition: 'true'\n", "rule a: duplicate name"},
+		{"rules:\n  - name: a\n    action: deny\n", "rule a: invalid condition: expression is required"},
+		{"rules:\n  - name: a\n    action: deny\n    condition: 'report.overall_score >'\n", "rule a: invalid condition"},
+		{"rules:\n  - name: a\n    action: deny\n    condition: '1 + 1'\n", "must return a bool"},
+		{"rules:\n  - name: a\n    action: deny\n    condition: 'true'\n    message: 'size(report)'\n", "rule a: invalid message"},
+		{"rules:\n  - name: a\n    action: deny\n    condition: 'findings > 1'\n", "undeclared reference"},
+	}
+	for _, tt := range tests {
+		_, err := Parse([]byte(tt.policy), "policy.yml")
+		if err == nil || !strings.Contains(err.Error(), tt.errMsg) {
+			t.Errorf("Expected error containing %q for %q, got %v", tt.errMsg, tt.policy, err)
+		}
+	}
+
+	if _, err := Load(filepath.Join(t.TempDir(), "missing.yml")); err == nil || !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("Expected a missing file error, got %v", err)
+	}
+}
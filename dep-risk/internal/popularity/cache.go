@@ -0,0 +1,119 @@
+package popularity
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/dep-risk/dep-risk/internal/scorer"
+)
+
+// cacheEntry is a lookup result persisted in the cache directory
+type cacheEntry struct {
+	FetchedAt  time.Time                 `json:"fetched_at"`
+	Popularity *scorer.PackagePopularity `json:"popularity"`
+}
+
+// Cache memoizes lookups of another provider in memory and, when a
+// directory is set, on disk for the configured TTL. Keys include the identity
+// of the provider, so providers sharing a directory do not read each other's
+// lookups.
+type Cache struct {
+	provider scorer.PopularityProvider
+	identity string
+	dir      string
+	ttl      time.Duration
+
+	mu      sync.Mutex
+	entries map[string]*scorer.PackagePopularity
+}
+
+// NewCache wraps a provider with a lookup cache; identity names the provider
+// and its source, such as the API it queries
+func NewCache(provider scorer.PopularityProvider, identity, dir string, ttl time.Duration) *Cache {
+	return &Cache{
+		provider: provider,
+		identity: identity,
+		dir:      dir,
+		ttl:      ttl,
+		entries:  make(map[string]*scorer.PackagePopularity),
+	}
+}
+
+// Popularity returns a cached lookup or queries the wrapped provider.
+// Failed lookups are not cached.
+func (c *Cache) Popularity(ecosystem, name, version string) (*scorer.PackagePopularity, error) {
+	key := c.identity + "\x00" + ecosystem + "\x00" + name + "\x00" + version
+
+	c.mu.Lock()
+	popularity, ok := c.entries[key]
+	c.mu.Unlock()
+	if ok {
+		return popularity, nil
+	}
+
+	if popularity, ok := c.readDisk(key); ok {
+		c.store(key, popularity)
+		return popularity, nil
+	}
+
+	popularity, err := c.provider.Popularity(ecosystem, name, version)
+	if err != nil {
+		return nil, err
+	}
+
+	c.store(key, popularity)
+	c.writeDisk(key, popularity)
+	return popularity, nil
+}
+
+// store records a lookup in memory
+func (c *Cache) store(key string, popularity *scorer.PackagePopularity) {
+	c.mu.Lock()
+	c.entries[key] = popularity
+	c.mu.Unlock()
+}
+
+// diskPath returns the cache file of a lookup key
+func (c *Cache) diskPath(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return filepath.Join(c.dir, hex.EncodeToString(sum[:])+".json")
+}
+
+// readDisk loads an unexpired lookup from the cache directory
+func (c *Cache) readDisk(key string) (*scorer.PackagePopularity, bool) {
+	if c.dir == "" {
+		return nil, false
+	}
+
+	data, err := os.ReadFile(c.diskPath(key))
+	if err != nil {
+		return nil, false
+	}
+
+	var entry cacheEntry
+	if err := json.Unmarshal(data, &entry); err != nil || time.Since(entry.FetchedAt) > c.ttl {
+		return nil, false
+	}
+	return entry.Popularity, true
+}
+
+// writeDisk persists a lookup; failures only cost a repeated lookup next run
+func (c *Cache) writeDisk(key string, popularity *scorer.PackagePopularity) {
+	if c.dir == "" {
+		return
+	}
+
+	data, err := json.Marshal(cacheEntry{FetchedAt: time.Now(), Popularity: popularity})
+	if err != nil {
+		return
+	}
+	if err := os.MkdirAll(c.dir, 0755); err != nil {
+		return
+	}
+	os.WriteFile(c.diskPath(key), data, 0644)
+}
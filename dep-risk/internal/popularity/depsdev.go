@@ -0,0 +1,124 @@
+package popularity
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/dep-risk/dep-risk/internal/scorer"
+)
+
+// errNotFound reports that the API does not know a package or project
+var errNotFound = errors.New("not found")
+
+// depsDevSystems maps OSV ecosystems to deps.dev package systems
+var depsDevSystems = map[string]string{
+	"go":        "GO",
+	"npm":       "NPM",
+	"pypi":      "PYPI",
+	"maven":     "MAVEN",
+	"crates.io": "CARGO",
+	"nuget":     "NUGET",
+	"rubygems":  "RUBYGEMS",
+}
+
+// DepsDevProvider reads dependent counts, repository stars, licenses and
+// publish dates from the deps.dev API or a local service implementing the
+// same endpoints
+type DepsDevProvider struct {
+	BaseURL    string
+	httpClient *http.Client
+}
+
+// NewDepsDevProvider creates a provider for a deps.dev-compatible API
+func NewDepsDevProvider(baseURL string) *DepsDevProvider {
+	return &DepsDevProvider{
+		BaseURL:    strings.TrimSuffix(baseURL, "/"),
+		httpClient: &http.Client{Timeout: 15 * time.Second},
+	}
+}
+
+// Popularity looks up a package version's dependents, source repository
+// stars, licenses and publish date
+func (p *DepsDevProvider) Popularity(ecosystem, name, version string) (*scorer.PackagePopularity, error) {
+	system, ok := depsDevSystems[strings.ToLower(ecosystem)]
+	if !ok || version == "" {
+		return nil, nil
+	}
+	if system == "GO" && !strings.HasPrefix(version, "v") {
+		version = "v" + version
+	}
+
+	versionPath := fmt.Sprintf("/systems/%s/packages/%s/versions/%s",
+		system, url.PathEscape(name), url.PathEscape(version))
+
+	var dependents struct {
+		DependentCount int `json:"dependentCount"`
+	}
+	err := p.get("/v3alpha"+versionPath+":dependents", &dependents)
+	if errors.Is(err, errNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	popularity := &scorer.PackagePopularity{Dependents: dependents.DependentCount}
+
+	var versionInfo struct {
+		PublishedAt     *time.Time `json:"publishedAt"`
+		Licenses        []string   `json:"licenses"`
+		RelatedProjects []struct {
+			ProjectKey struct {
+				ID string `json:"id"`
+			} `json:"projectKey"`
+			RelationType string `json:"relationType"`
+		} `json:"relatedProjects"`
+	}
+	if err := p.get("/v3"+versionPath, &versionInfo); err != nil && !errors.Is(err, errNotFound) {
+		return nil, err
+	}
+	popularity.PublishedAt = versionInfo.PublishedAt
+	popularity.Licenses = versionInfo.Licenses
+
+	for _, related := range versionInfo.RelatedProjects {
+		if related.RelationType != "SOURCE_REPO" {
+			continue
+		}
+		var project struct {
+			StarsCount int `json:"starsCount"`
+		}
+		if err := p.get("/v3/projects/"+url.PathEscape(related.ProjectKey.ID), &project); err != nil && !errors.Is(err, errNotFound) {
+			return nil, err
+		}
+		popularity.GitHubStars = project.StarsCount
+		break
+	}
+
+	return popularity, nil
+}
+
+// get fetches an API path and decodes the JSON response
+func (p *DepsDevProvider) get(path string, target interface{}) error {
+	resp, err := p.httpClient.Get(p.BaseURL + path)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+		if err := json.NewDecoder(resp.Body).Decode(target); err != nil {
+			return fmt.Errorf("failed to decode %s: %w", path, err)
+		}
+		return nil
+	case http.StatusNotFound:
+		return fmt.Errorf("%s: %w", path, errNotFound)
+	default:
+		return fmt.Errorf("%s returned status %d", path, resp.StatusCode)
+	}
+}
@@ -0,0 +1,57 @@
+package popularity
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/dep-risk/dep-risk/internal/scorer"
+)
+
+// Provider names accepted in configuration
+const (
+	ProviderBuiltin  = "builtin"
+	ProviderSnapshot = "snapshot"
+	ProviderDepsDev  = "depsdev"
+)
+
+// DefaultDepsDevURL is the public deps.dev API
+const DefaultDepsDevURL = "https://api.deps.dev"
+
+// Options selects and configures a popularity provider
+type Options struct {
+	Provider string
+
+	// SnapshotPath is the snapshot file read by the snapshot provider
+	SnapshotPath string
+
+	// BaseURL is the deps.dev-compatible API used by the depsdev provider
+	BaseURL string
+
+	// CacheDir persists lookups between runs; empty keeps them in memory only
+	CacheDir string
+	CacheTTL time.Duration
+}
+
+// NewProvider creates the popularity provider selected by the options
+func NewProvider(options Options) (scorer.PopularityProvider, error) {
+	switch options.Provider {
+	case "", ProviderBuiltin:
+		return scorer.BuiltinPopularity{}, nil
+	case ProviderSnapshot:
+		snapshot, err := LoadSnapshot(options.SnapshotPath)
+		if err != nil {
+			return nil, err
+		}
+		// The snapshot is read from disk already and changes with the file,
+		// so its lookups are only kept in memory
+		return NewCache(snapshot, ProviderSnapshot+" "+options.SnapshotPath, "", options.CacheTTL), nil
+	case ProviderDepsDev:
+		baseURL := options.BaseURL
+		if baseURL == "" {
+			baseURL = DefaultDepsDevURL
+		}
+		return NewCache(NewDepsDevProvider(baseURL), ProviderDepsDev+" "+baseURL, options.CacheDir, options.CacheTTL), nil
+	default:
+		return nil, fmt.Errorf("unknown popularity provider %q", options.Provider)
+	}
+}
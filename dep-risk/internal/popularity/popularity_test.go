@@ -0,0 +1,130 @@
+package popularity
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/dep-risk/dep-risk/internal/scorer"
+)
+
+func TestSnapshot(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "popularity.json")
+	data := `{"npm": {"left-pad": {"downloads_per_month": 1200, "github_stars": 1100, "dependents": 40}}}`
+	if err := os.WriteFile(path, []byte(data), 0644); err != nil {
+		t.Fatalf("Failed to write snapshot: %v", err)
+	}
+
+	snapshot, err := LoadSnapshot(path)
+	if err != nil {
+		t.Fatalf("Failed to load snapshot: %v", err)
+	}
+
+	popularity, err := snapshot.Popularity("NPM", "left-pad", "1.3.0")
+	if err != nil || popularity == nil || popularity.DownloadsPerMonth != 1200 || popularity.Dependents != 40 {
+		t.Errorf("Unexpected snapshot lookup: %+v, %v", popularity, err)
+	}
+
+	if popularity, err := snapshot.Popularity("npm", "unknown", "1.0.0"); popularity != nil || err != nil {
+		t.Errorf("Expected unknown package to return nil, got %+v, %v", popularity, err)
+	}
+}
+
+func TestDepsDevProvider(t *testing.T) {
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		switch r.URL.EscapedPath() {
+		case "/v3alpha/systems/GO/packages/golang.org%2Fx%2Fnet/versions/v0.17.0:dependents":
+			w.Write([]byte(`{"dependentCount": 52000}`))
+		case "/v3/systems/GO/packages/golang.org%2Fx%2Fnet/versions/v0.17.0":
+			w.Write([]byte(`{"publishedAt": "2023-10-11T00:00:00Z", "licenses": ["BSD-3-Clause"], "relatedProjects": [{"projectKey": {"id": "github.com/golang/net"}, "relationType": "SOURCE_REPO"}]}`))
+		case "/v3/projects/github.com%2Fgolang%2Fnet":
+			w.Write([]byte(`{"starsCount": 2700}`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	provider := NewCache(NewDepsDevProvider(server.URL), "depsdev", "", time.Hour)
+
+	popularity, err := provider.Popularity("Go", "golang.org/x/net", "0.17.0")
+	if err != nil {
+		t.Fatalf("Lookup failed: %v", err)
+	}
+	if popularity == nil || popularity.Dependents != 52000 || popularity.GitHubStars != 2700 {
+		t.Errorf("Unexpected popularity: %+v", popularity)
+	}
+	if popularity != nil && (len(popularity.Licenses) != 1 || popularity.Licenses[0] != "BSD-3-Clause" || popularity.PublishedAt == nil || popularity.PublishedAt.Year() != 2023) {
+		t.Errorf("Expected the version's license and publish date, got %+v", popularity)
+	}
+
+	// The second lookup is served from the cache
+	if _, err := provider.Popularity("Go", "golang.org/x/net", "0.17.0"); err != nil || requests != 3 {
+		t.Errorf("Expected cached lookup without requests, got %d requests, err %v", requests, err)
+	}
+
+	if popularity, err := provider.Popularity("npm", "missing", "1.0.0"); popularity != nil || err != nil {
+		t.Errorf("Expected unknown package to return nil, got %+v, %v", popularity, err)
+	}
+}
+
+// failingProvider fails every lookup
+type failingProvider struct{ calls int }
+
+func (p *failingProvider) Popularity(ecosystem, name, version string) (*scorer.PackagePopularity, error) {
+	p.calls++
+	return nil, errors.New("unavailable")
+}
+
+func TestCacheDisk(t *testing.T) {
+	dir := t.TempDir()
+	first := NewCache(&stubProvider{}, "stub", dir, time.Hour)
+	if _, err := first.Popularity("npm", "express", "4.18.2"); err != nil {
+		t.Fatalf("Lookup failed: %v", err)
+	}
+
+	// A new cache over the same directory must not hit the provider
+	failing := &failingProvider{}
+	second := NewCache(failing, "stub", dir, time.Hour)
+	popularity, err := second.Popularity("npm", "express", "4.18.2")
+	if err != nil || popularity == nil || popularity.DownloadsPerMonth != 100 || failing.calls != 0 {
+		t.Errorf("Expected disk cache hit, got %+v, %v after %d calls", popularity, err, failing.calls)
+	}
+
+	// Expired entries are looked up again
+	expired := NewCache(failing, "stub", dir, 0)
+	if _, err := expired.Popularity("npm", "express", "4.18.2"); err == nil || failing.calls != 1 {
+		t.Errorf("Expected expired entry to query the provider, got err %v after %d calls", err, failing.calls)
+	}
+
+	// Another provider over the same directory does not read the entries
+	other := &failingProvider{}
+	if _, err := NewCache(other, "other", dir, time.Hour).Popularity("npm", "express", "4.18.2"); err == nil || other.calls != 1 {
+		t.Errorf("Expected another provider to miss the cache, got err %v after %d calls", err, other.calls)
+	}
+}
+
+// stubProvider returns fixed metrics
+type stubProvider struct{}
+
+func (stubProvider) Popularity(ecosystem, name, version string) (*scorer.PackagePopularity, error) {
+	return &scorer.PackagePopularity{DownloadsPerMonth: 100}, nil
+}
+
+func TestNewProvider(t *testing.T) {
+	if _, err := NewProvider(Options{Provider: "unknown"}); err == nil {
+		t.Error("Expected an error for an unknown provider")
+	}
+	if _, err := NewProvider(Options{Provider: ProviderSnapshot, SnapshotPath: "missing.json"}); err == nil {
+		t.Error("Expected an error for a missing snapshot")
+	}
+	if provider, err := NewProvider(Options{}); err != nil || provider == nil {
+		t.Errorf("Expected builtin provider by default, got %v, %v", provider, err)
+	}
+}
@@ -0,0 +1,48 @@
+package popularity
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/dep-risk/dep-risk/internal/scorer"
+)
+
+// Snapshot serves popularity metrics from a local file keyed by ecosystem and
+// package name:
+//
+//	{"npm": {"lodash": {"downloads_per_month": 50000000, "github_stars": 59000, "dependents": 180000}}}
+type Snapshot struct {
+	ecosystems map[string]map[string]scorer.PackagePopularity
+}
+
+// LoadSnapshot reads a popularity snapshot file
+func LoadSnapshot(path string) (*Snapshot, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read popularity snapshot: %w", err)
+	}
+
+	var raw map[string]map[string]scorer.PackagePopularity
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return nil, fmt.Errorf("failed to parse popularity snapshot: %w", err)
+	}
+
+	// Ecosystem names differ in case between tools (Go, go, npm, NPM)
+	ecosystems := make(map[string]map[string]scorer.PackagePopularity, len(raw))
+	for ecosystem, packages := range raw {
+		ecosystems[strings.ToLower(ecosystem)] = packages
+	}
+
+	return &Snapshot{ecosystems: ecosystems}, nil
+}
+
+// Popularity returns the snapshot metrics of a package
+func (s *Snapshot) Popularity(ecosystem, name, version string) (*scorer.PackagePopularity, error) {
+	popularity, ok := s.ecosystems[strings.ToLower(ecosystem)][name]
+	if !ok {
+		return nil, nil
+	}
+	return &popularity, nil
+}
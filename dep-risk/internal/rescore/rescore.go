@@ -0,0 +1,218 @@
+// Package rescore compares how alternative configurations score the findings
+// of an existing report
+package rescore
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/dep-risk/dep-risk/internal/scorer"
+)
+
+// Levels of a finding relative to the thresholds of a scenario
+const (
+	LevelFail    = "fail"
+	LevelWarn    = "warn"
+	LevelPass    = "pass"
+	LevelIgnored = "ignored"
+)
+
+// Thresholds are the fail and warn thresholds of a configuration
+type Thresholds struct {
+	Fail float64 `json:"fail"`
+	Warn float64 `json:"warn"`
+}
+
+// Level classifies a score against the thresholds
+func (t Thresholds) Level(score float64) string {
+	switch {
+	case score >= t.Fail:
+		return LevelFail
+	case score >= t.Warn:
+		return LevelWarn
+	default:
+		return LevelPass
+	}
+}
+
+// Status classifies a project score like the scan status
+func (t Thresholds) Status(score float64) string {
+	switch t.Level(score) {
+	case LevelFail:
+		return "failure"
+	case LevelWarn:
+		return "warning"
+	default:
+		return "success"
+	}
+}
+
+// Finding is a scored finding of a scenario
+type Finding struct {
+	Key     string  `json:"key"`
+	ID      string  `json:"id"`
+	Package string  `json:"package"`
+	Version string  `json:"version"`
+	Score   float64 `json:"score"`
+	Rank    int     `json:"rank"`
+	Level   string  `json:"level"`
+}
+
+// Scenario is a report scored under one configuration
+type Scenario struct {
+	Name         string     `json:"name"`
+	ModelVersion string     `json:"model_version"`
+	ConfigHash   string     `json:"config_hash"`
+	Thresholds   Thresholds `json:"thresholds"`
+	ProjectScore float64    `json:"project_score"`
+	Status       string     `json:"status"`
+	Aggregation  string     `json:"aggregation"`
+	HighRisk     int        `json:"high_risk"`
+	MediumRisk   int        `json:"medium_risk"`
+	LowRisk      int        `json:"low_risk"`
+	Ignored      int        `json:"ignored"`
+
+	findings map[string]Finding
+}
+
+// NewScenario summarises a project score under a configuration's thresholds.
+// Findings are ranked by score, highest first.
+func NewScenario(name string, projectScore *scorer.ProjectRiskScore, thresholds Thresholds, ignored int) Scenario {
+	scenario := Scenario{
+		Name:         name,
+		ModelVersion: projectScore.ModelVersion,
+		ConfigHash:   projectScore.ConfigHash,
+		Thresholds:   thresholds,
+		ProjectScore: projectScore.OverallScore,
+		Status:       thresholds.Status(projectScore.OverallScore),
+		Aggregation:  projectScore.Aggregation.String(),
+		HighRisk:     projectScore.Summary.HighRiskCount,
+		MediumRisk:   projectScore.Summary.MediumRiskCount,
+		LowRisk:      projectScore.Summary.LowRiskCount,
+		Ignored:      ignored,
+		findings:     make(map[string]Finding),
+	}
+
+	var findings []Finding
+	for _, score := range projectScore.VulnerabilityScores {
+		vuln := score.Vulnerability
+		findings = append(findings, Finding{
+			Key:     FindingKey(score),
+			ID:      vuln.ID,
+			Package: vuln.Package,
+			Version: vuln.Version,
+			Score:   score.Overall,
+			Level:   thresholds.Level(score.Overall),
+		})
+	}
+	sort.SliceStable(findings, func(i, j int) bool {
+		if findings[i].Score != findings[j].Score {
+			return findings[i].Score > findings[j].Score
+		}
+		return findings[i].Key < findings[j].Key
+	})
+	for i, finding := range findings {
+		finding.Rank = i + 1
+		scenario.findings[finding.Key] = finding
+	}
+
+	return scenario
+}
+
+// FindingKey identifies a finding across scenarios
+func FindingKey(score scorer.RiskScore) string {
+	vuln := score.Vulnerability
+	key := fmt.Sprintf("%s %s@%s", vuln.ID, vuln.Package, vuln.Version)
+	if vuln.File != "" {
+		key += fmt.Sprintf(" %s:%d", vuln.File, vuln.Line)
+	}
+	return key
+}
+
+// Crossing is a finding whose level differs from the baseline
+type Crossing struct {
+	Key       string  `json:"key"`
+	From      float64 `json:"from"`
+	To        float64 `json:"to"`
+	FromLevel string  `json:"from_level"`
+	ToLevel   string  `json:"to_level"`
+}
+
+// RankChange is a finding whose rank differs from the baseline
+type RankChange struct {
+	Key      string `json:"key"`
+	FromRank int    `json:"from_rank"`
+	ToRank   int    `json:"to_rank"`
+}
+
+// Alternative is a scenario compared with the baseline
+type Alternative struct {
+	Scenario
+	Crossings   []Crossing   `json:"crossings"`
+	RankChanges []RankChange `json:"rank_changes"`
+}
+
+// Comparison compares alternative scenarios with a baseline
+type Comparison struct {
+	Baseline     Scenario      `json:"baseline"`
+	Alternatives []Alternative `json:"alternatives"`
+}
+
+// Compare compares each alternative with the baseline. A finding missing from
+// one side was ignored by that configuration.
+func Compare(baseline Scenario, alternatives []Scenario) *Comparison {
+	comparison := &Comparison{Baseline: baseline}
+	for _, scenario := range alternatives {
+		alternative := Alternative{Scenario: scenario}
+
+		for _, key := range unionKeys(baseline.findings, scenario.findings) {
+			before, inBaseline := baseline.findings[key]
+			after, inScenario := scenario.findings[key]
+			if !inBaseline {
+				before.Level = LevelIgnored
+			}
+			if !inScenario {
+				after.Level = LevelIgnored
+			}
+
+			if before.Level != after.Level {
+				alternative.Crossings = append(alternative.Crossings, Crossing{
+					Key:       key,
+					From:      before.Score,
+					To:        after.Score,
+					FromLevel: before.Level,
+					ToLevel:   after.Level,
+				})
+			}
+			if inBaseline && inScenario && before.Rank != after.Rank {
+				alternative.RankChanges = append(alternative.RankChanges, RankChange{
+					Key:      key,
+					FromRank: before.Rank,
+					ToRank:   after.Rank,
+				})
+			}
+		}
+
+		sort.SliceStable(alternative.RankChanges, func(i, j int) bool {
+			return alternative.RankChanges[i].ToRank < alternative.RankChanges[j].ToRank
+		})
+		comparison.Alternatives = append(comparison.Alternatives, alternative)
+	}
+	return comparison
+}
+
+// unionKeys returns the finding keys of both scenarios in a stable order
+func unionKeys(a, b map[string]Finding) []string {
+	seen := make(map[string]bool)
+	var keys []string
+	for _, findings := range []map[string]Finding{a, b} {
+		for key := range findings {
+			if !seen[key] {
+				seen[key] = true
+				keys = append(keys, key)
+			}
+		}
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,77 @@
+package rescore
+
+import (
+	"testing"
+
+	"github.com/dep-risk/dep-risk/internal/scanner"
+	"github.com/dep-risk/dep-risk/internal/scorer"
+)
+
+func projectScore(overall float64, scores map[string]float64) *scorer.ProjectRiskScore {
+	project := &scorer.ProjectRiskScore{OverallScore: overall, ModelVersion: scorer.LatestModelVersion}
+	for id, score := range scores {
+		project.VulnerabilityScores = append(project.VulnerabilityScores, scorer.RiskScore{
+			Overall:       score,
+			Vulnerability: scanner.Vulnerability{ID: id, Package: "lib", Version: "1.0.0"},
+		})
+	}
+	return project
+}
+
+func TestCompare(t *testing.T) {
+	thresholds := Thresholds{Fail: 7, Warn: 4}
+	baseline := NewScenario("report", projectScore(7.5, map[string]float64{
+		"CVE-A": 7.5,
+		"CVE-B": 6.0,
+		"CVE-C": 3.0,
+		"CVE-D": 2.0,
+	}), thresholds, 0)
+	if baseline.Status != "failure" {
+		t.Errorf("Expected baseline failure, got %s", baseline.Status)
+	}
+
+	alternative := NewScenario("strict.yml", projectScore(8.0, map[string]float64{
+		"CVE-A": 6.5,
+		"CVE-B": 8.0,
+		"CVE-C": 3.5,
+	}), thresholds, 1)
+
+	comparison := Compare(baseline, []Scenario{alternative})
+	if len(comparison.Alternatives) != 1 {
+		t.Fatalf("Expected 1 alternative, got %d", len(comparison.Alternatives))
+	}
+	result := comparison.Alternatives[0]
+
+	crossings := make(map[string]Crossing)
+	for _, crossing := range result.Crossings {
+		crossings[crossing.Key] = crossing
+	}
+	if len(crossings) != 3 {
+		t.Errorf("Expected 3 crossings, got %+v", result.Crossings)
+	}
+	if c := crossings["CVE-A lib@1.0.0"]; c.FromLevel != LevelFail || c.ToLevel != LevelWarn {
+		t.Errorf("Expected CVE-A to drop below the fail threshold, got %+v", c)
+	}
+	if c := crossings["CVE-B lib@1.0.0"]; c.FromLevel != LevelWarn || c.ToLevel != LevelFail {
+		t.Errorf("Expected CVE-B to cross the fail threshold, got %+v", c)
+	}
+	if c := crossings["CVE-D lib@1.0.0"]; c.ToLevel != LevelIgnored {
+		t.Errorf("Expected CVE-D to be ignored, got %+v", c)
+	}
+
+	if len(result.RankChanges) != 2 {
+		t.Fatalf("Expected 2 rank changes, got %+v", result.RankChanges)
+	}
+	if change := result.RankChanges[0]; change.Key != "CVE-B lib@1.0.0" || change.FromRank != 2 || change.ToRank != 1 {
+		t.Errorf("Expected CVE-B to move from #2 to #1, got %+v", change)
+	}
+}
+
+func TestThresholds(t *testing.T) {
+	thresholds := Thresholds{Fail: 7, Warn: 4}
+	for score, want := range map[float64]string{7: LevelFail, 6.9: LevelWarn, 4: LevelWarn, 3.9: LevelPass} {
+		if got := thresholds.Level(score); got != want {
+			t.Errorf("Level(%.1f) = %s, want %s", score, got, want)
+		}
+	}
+}
@@ -0,0 +1,132 @@
+package scanner
+
+import (
+	"bufio"
+	"bytes"
+	"regexp"
+	"strings"
+)
+
+// DiagnosticKind classifies a warning reported by an external scanning tool
+type DiagnosticKind string
+
+const (
+	DiagnosticUnsupportedLockfile DiagnosticKind = "unsupported_lockfile"
+	DiagnosticSkippedPackage      DiagnosticKind = "skipped_package"
+	DiagnosticNetworkFailure      DiagnosticKind = "network_failure"
+	DiagnosticLookupFailure       DiagnosticKind = "lookup_failure"
+	DiagnosticWarning             DiagnosticKind = "warning"
+)
+
+// Diagnostic represents a structured warning emitted by syft or osv-scanner
+type Diagnostic struct {
+	Tool    string         `json:"tool"`
+	Kind    DiagnosticKind `json:"kind"`
+	Message string         `json:"message"`
+	Path    string         `json:"path,omitempty"`
+}
+
+// IncompleteScan reports whether the diagnostic means some dependencies were not scanned
+func (d Diagnostic) IncompleteScan() bool {
+	return d.Kind != DiagnosticWarning
+}
+
+// PartialScan reports whether any of the diagnostics means some dependencies
+// were not scanned
+func PartialScan(diagnostics []Diagnostic) bool {
+	for _, diagnostic := range diagnostics {
+		if diagnostic.IncompleteScan() {
+			return true
+		}
+	}
+	return false
+}
+
+// diagnosticPatterns maps lower-cased message fragments to diagnostic kinds.
+// Patterns are checked in order, so more specific kinds come first.
+var diagnosticPatterns = []struct {
+	kind      DiagnosticKind
+	fragments []string
+}{
+	{DiagnosticNetworkFailure, []string{
+		"dial tcp", "no such host", "connection refused", "connection reset",
+		"i/o timeout", "deadline exceeded", "tls handshake", "failed to query",
+		"service unavailable", "too many requests", "network is unreachable",
+	}},
+	{DiagnosticUnsupportedLockfile, []string{
+		"attempted to scan lockfile but failed", "could not determine extractor",
+		"not a supported lockfile", "unsupported lockfile", "unknown lockfile",
+		"no extractor", "failed to parse lockfile", "no package sources found",
+	}},
+	{DiagnosticSkippedPackage, []string{
+		"ignored invalid package", "skipping package", "skipped package",
+		"has been filtered out", "could not be resolved", "unable to resolve",
+		"failed to resolve",
+	}},
+	{DiagnosticWarning, []string{"warn", "error", "failed"}},
+}
+
+// informationalPrefixes are progress messages that carry no diagnostic value
+var informationalPrefixes = []string{
+	"scanning dir", "scanned ", "scanning ", "starting ", "loaded ", "filtered ",
+}
+
+// pathPattern matches file paths mentioned in tool messages
+var pathPattern = regexp.MustCompile(`((?:\.{0,2}/)?[\w.@+-]+(?:/[\w.@+-]+)*\.(?:lock|json|toml|txt|mod|sum|xml|gradle|yaml|yml|csproj|cfg))\b`)
+
+// ansiPattern matches terminal escape sequences used by progress output
+var ansiPattern = regexp.MustCompile(`\x1b\[[0-9;]*[A-Za-z]`)
+
+// parseDiagnostics classifies the stderr output of a tool into diagnostics
+func parseDiagnostics(tool string, stderr []byte) []Diagnostic {
+	var diagnostics []Diagnostic
+	seen := make(map[string]bool)
+
+	lines := bufio.NewScanner(bytes.NewReader(stderr))
+	lines.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for lines.Scan() {
+		line := strings.TrimSpace(ansiPattern.ReplaceAllString(lines.Text(), ""))
+		if line == "" || seen[line] {
+			continue
+		}
+
+		kind, ok := classifyDiagnostic(line)
+		if !ok {
+			continue
+		}
+		seen[line] = true
+
+		diagnostic := Diagnostic{
+			Tool:    tool,
+			Kind:    kind,
+			Message: line,
+		}
+		if match := pathPattern.FindStringSubmatch(line); match != nil {
+			diagnostic.Path = match[1]
+		}
+		diagnostics = append(diagnostics, diagnostic)
+	}
+
+	return diagnostics
+}
+
+// classifyDiagnostic determines the diagnostic kind of a single message line
+func classifyDiagnostic(line string) (DiagnosticKind, bool) {
+	lower := strings.ToLower(line)
+
+	for _, prefix := range informationalPrefixes {
+		if strings.HasPrefix(lower, prefix) {
+			return "", false
+		}
+	}
+
+	for _, pattern := range diagnosticPatterns {
+		for _, fragment := range pattern.fragments {
+			if strings.Contains(lower, fragment) {
+				return pattern.kind, true
+			}
+		}
+	}
+
+	return "", false
+}
@@ -0,0 +1,482 @@
+package scanner
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"golang.org/x/mod/modfile"
+)
+
+// Dependency types recorded by lockfiles and SBOMs
+const (
+	DependencyProduction  = "production"
+	DependencyDevelopment = "development"
+	DependencyOptional    = "optional"
+	DependencyPeer        = "peer"
+)
+
+// DependencyInfo describes where a package sits in the project's dependency graph
+type DependencyInfo struct {
+	IsDirect bool `json:"is_direct"`
+	// Depth is the shortest distance from the project root: 1 for direct
+	// dependencies, 0 when unknown
+	Depth int    `json:"depth"`
+	Type  string `json:"type,omitempty"`
+	// TransitiveDependents counts the packages that depend on this one directly or indirectly
+	TransitiveDependents int  `json:"transitive_dependents"`
+	Cyclic               bool `json:"cyclic"`
+}
+
+// typeExposure ranks dependency types by how likely they ship to production
+var typeExposure = map[string]int{
+	DependencyProduction:  4,
+	DependencyPeer:        3,
+	DependencyOptional:    2,
+	DependencyDevelopment: 1,
+}
+
+// graphRoot is the node ID of the project itself
+const graphRoot = ""
+
+// graphNode is a package in the dependency graph
+type graphNode struct {
+	name    string
+	version string
+	depType string
+	// depthHint is used when the node is not reachable from the root, e.g. an
+	// indirect Go requirement whose parent is not recorded in go.mod
+	depthHint int
+	deps      []string
+}
+
+// DependencyGraph is a package dependency graph rooted at the project
+type DependencyGraph struct {
+	nodes map[string]*graphNode
+
+	depths  map[string]int
+	cyclic  map[string]bool
+	reverse map[string][]string
+}
+
+// newDependencyGraph creates a graph holding only the project root
+func newDependencyGraph() *DependencyGraph {
+	return &DependencyGraph{
+		nodes: map[string]*graphNode{graphRoot: {}},
+	}
+}
+
+// addNode adds a package node; an existing node keeps its data
+func (g *DependencyGraph) addNode(id, name, version, depType string) {
+	if _, exists := g.nodes[id]; exists {
+		return
+	}
+	g.nodes[id] = &graphNode{name: name, version: version, depType: depType}
+	g.depths = nil
+}
+
+// addEdge records that from depends on to
+func (g *DependencyGraph) addEdge(from, to string) {
+	node, ok := g.nodes[from]
+	if !ok {
+		return
+	}
+	for _, dep := range node.deps {
+		if dep == to {
+			return
+		}
+	}
+	node.deps = append(node.deps, to)
+	g.depths = nil
+}
+
+// merge copies another graph into this one, joining the two project roots
+func (g *DependencyGraph) merge(other *DependencyGraph) {
+	for id, node := range other.nodes {
+		if id == graphRoot {
+			continue
+		}
+		copied := *node
+		copied.deps = append([]string(nil), node.deps...)
+		g.nodes[id] = &copied
+	}
+	for _, dep := range other.nodes[graphRoot].deps {
+		g.addEdge(graphRoot, dep)
+	}
+	g.depths = nil
+}
+
+// isEmpty reports whether the graph has no packages
+func (g *DependencyGraph) isEmpty() bool {
+	return len(g.nodes) <= 1
+}
+
+// Size returns the number of packages in the graph, not counting the root
+func (g *DependencyGraph) Size() int {
+	return len(g.nodes) - 1
+}
+
+// analyze computes depths, reverse edges and cycle membership
+func (g *DependencyGraph) analyze() {
+	g.depths = map[string]int{graphRoot: 0}
+	g.reverse = make(map[string][]string)
+	for id, node := range g.nodes {
+		for _, dep := range node.deps {
+			g.reverse[dep] = append(g.reverse[dep], id)
+		}
+	}
+
+	queue := []string{graphRoot}
+	for len(queue) > 0 {
+		id := queue[0]
+		queue = queue[1:]
+		for _, dep := range g.nodes[id].deps {
+			if _, seen := g.depths[dep]; seen {
+				continue
+			}
+			if _, exists := g.nodes[dep]; !exists {
+				continue
+			}
+			g.depths[dep] = g.depths[id] + 1
+			queue = append(queue, dep)
+		}
+	}
+
+	g.cyclic = g.findCycles()
+}
+
+// findCycles marks the nodes of every strongly connected component that
+// contains a cycle, using Tarjan's algorithm
+func (g *DependencyGraph) findCycles() map[string]bool {
+	index := 0
+	indices := make(map[string]int)
+	lowlink := make(map[string]int)
+	onStack := make(map[string]bool)
+	var stack []string
+	cyclic := make(map[string]bool)
+
+	ids := make([]string, 0, len(g.nodes))
+	for id := range g.nodes {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+
+	var connect func(id string)
+	connect = func(id string) {
+		indices[id] = index
+		lowlink[id] = index
+		index++
+		stack = append(stack, id)
+		onStack[id] = true
+
+		selfLoop := false
+		for _, dep := range g.nodes[id].deps {
+			if dep == id {
+				selfLoop = true
+			}
+			if _, exists := g.nodes[dep]; !exists {
+				continue
+			}
+			if _, visited := indices[dep]; !visited {
+				connect(dep)
+				lowlink[id] = min(lowlink[id], lowlink[dep])
+			} else if onStack[dep] {
+				lowlink[id] = min(lowlink[id], indices[dep])
+			}
+		}
+
+		if lowlink[id] != indices[id] {
+			return
+		}
+		var component []string
+		for {
+			top := stack[len(stack)-1]
+			stack = stack[:len(stack)-1]
+			onStack[top] = false
+			component = append(component, top)
+			if top == id {
+				break
+			}
+		}
+		if len(component) > 1 || selfLoop {
+			for _, member := range component {
+				cyclic[member] = true
+			}
+		}
+	}
+
+	for _, id := range ids {
+		if _, visited := indices[id]; !visited {
+			connect(id)
+		}
+	}
+	return cyclic
+}
+
+// dependents counts the packages that reach a node, excluding the project root
+func (g *DependencyGraph) dependents(id string) int {
+	seen := map[string]bool{id: true}
+	queue := []string{id}
+	count := 0
+	for len(queue) > 0 {
+		current := queue[0]
+		queue = queue[1:]
+		for _, parent := range g.reverse[current] {
+			if seen[parent] {
+				continue
+			}
+			seen[parent] = true
+			queue = append(queue, parent)
+			if parent != graphRoot {
+				count++
+			}
+		}
+	}
+	return count
+}
+
+// Info describes a package in the graph. When several nodes match, e.g. two
+// installed copies of an npm package, the most exposed one is reported.
+// An empty version matches every version.
+func (g *DependencyGraph) Info(name, version string) (DependencyInfo, bool) {
+	if g.depths == nil {
+		g.analyze()
+	}
+
+	var info DependencyInfo
+	found := false
+	for id, node := range g.nodes {
+		if id == graphRoot || node.name != name || !sameVersion(node.version, version) {
+			continue
+		}
+
+		depth, reachable := g.depths[id]
+		if !reachable {
+			depth = node.depthHint
+		}
+
+		if !found || (depth > 0 && (info.Depth == 0 || depth < info.Depth)) {
+			info.Depth = depth
+		}
+		if !found || typeExposure[node.depType] > typeExposure[info.Type] {
+			info.Type = node.depType
+		}
+		if dependents := g.dependents(id); dependents > info.TransitiveDependents {
+			info.TransitiveDependents = dependents
+		}
+		info.Cyclic = info.Cyclic || g.cyclic[id]
+		found = true
+	}
+
+	info.IsDirect = info.Depth == 1
+	return info, found
+}
+
+// sameVersion compares versions, ignoring the v prefix Go tools disagree on.
+// An empty version matches any version.
+func sameVersion(a, b string) bool {
+	return a == "" || b == "" || strings.TrimPrefix(a, "v") == strings.TrimPrefix(b, "v")
+}
+
+// LoadDependencyGraph builds the dependency graph of a project from the
+// lockfiles and manifests in its root directory
+func LoadDependencyGraph(workingDir string) *DependencyGraph {
+	graph := newDependencyGraph()
+
+	if data, err := os.ReadFile(filepath.Join(workingDir, "package-lock.json")); err == nil {
+		manifest, _ := os.ReadFile(filepath.Join(workingDir, "package.json"))
+		if npmGraph, err := parseNPMGraph(data, manifest); err == nil {
+			graph.merge(npmGraph)
+		}
+	}
+
+	if data, err := os.ReadFile(filepath.Join(workingDir, "go.mod")); err == nil {
+		if goGraph, err := parseGoModGraph(data); err == nil {
+			graph.merge(goGraph)
+		}
+	}
+
+	return graph
+}
+
+// parseGoModGraph builds a graph from go.mod. Indirect requirements are
+// placed at depth 2 because go.mod does not record which module needs them.
+func parseGoModGraph(data []byte) (*DependencyGraph, error) {
+	file, err := modfile.ParseLax("go.mod", data, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	graph := newDependencyGraph()
+	for _, req := range file.Require {
+		graph.addNode(req.Mod.Path, req.Mod.Path, req.Mod.Version, DependencyProduction)
+		if req.Indirect {
+			graph.nodes[req.Mod.Path].depthHint = 2
+			continue
+		}
+		graph.addEdge(graphRoot, req.Mod.Path)
+	}
+	return graph, nil
+}
+
+// npmLockEntry is a package entry of package-lock.json
+type npmLockEntry struct {
+	Version              string            `json:"version"`
+	Dev                  bool              `json:"dev"`
+	Optional             bool              `json:"optional"`
+	DevOptional          bool              `json:"devOptional"`
+	Peer                 bool              `json:"peer"`
+	Requires             map[string]string `json:"requires"`
+	Dependencies         map[string]string `json:"-"`
+	DevDependencies      map[string]string `json:"devDependencies"`
+	OptionalDependencies map[string]string `json:"optionalDependencies"`
+	PeerDependencies     map[string]string `json:"peerDependencies"`
+}
+
+// npmManifest is the subset of package.json naming the root dependencies
+type npmManifest struct {
+	Dependencies         map[string]string `json:"dependencies"`
+	DevDependencies      map[string]string `json:"devDependencies"`
+	OptionalDependencies map[string]string `json:"optionalDependencies"`
+	PeerDependencies     map[string]string `json:"peerDependencies"`
+}
+
+// depType classifies a lockfile entry
+func (e npmLockEntry) depType() string {
+	switch {
+	case e.Dev || e.DevOptional:
+		return DependencyDevelopment
+	case e.Peer:
+		return DependencyPeer
+	case e.Optional:
+		return DependencyOptional
+	default:
+		return DependencyProduction
+	}
+}
+
+// parseNPMGraph builds a graph from package-lock.json. Lockfile v2/v3 record
+// every installed path in "packages"; v1 nests entries under "dependencies"
+// and the root dependencies come from package.json.
+func parseNPMGraph(data, manifest []byte) (*DependencyGraph, error) {
+	var lock struct {
+		Packages     map[string]json.RawMessage `json:"packages"`
+		Dependencies map[string]json.RawMessage `json:"dependencies"`
+	}
+	if err := json.Unmarshal(data, &lock); err != nil {
+		return nil, err
+	}
+
+	entries := make(map[string]npmLockEntry)
+	rootDeps := make(map[string]bool)
+
+	if len(lock.Packages) > 0 {
+		for path, raw := range lock.Packages {
+			var entry npmLockEntry
+			var deps struct {
+				Dependencies map[string]string `json:"dependencies"`
+			}
+			if err := json.Unmarshal(raw, &entry); err != nil {
+				return nil, err
+			}
+			json.Unmarshal(raw, &deps)
+			entry.Dependencies = deps.Dependencies
+			entries[path] = entry
+		}
+
+		root := entries[""]
+		for _, deps := range []map[string]string{root.Dependencies, root.DevDependencies, root.OptionalDependencies, root.PeerDependencies} {
+			for name := range deps {
+				rootDeps[name] = true
+			}
+		}
+		delete(entries, "")
+	} else {
+		if err := flattenNPMv1(lock.Dependencies, "", entries); err != nil {
+			return nil, err
+		}
+		var pkg npmManifest
+		if json.Unmarshal(manifest, &pkg) == nil {
+			for _, deps := range []map[string]string{pkg.Dependencies, pkg.DevDependencies, pkg.OptionalDependencies, pkg.PeerDependencies} {
+				for name := range deps {
+					rootDeps[name] = true
+				}
+			}
+		}
+	}
+
+	graph := newDependencyGraph()
+	for path, entry := range entries {
+		idx := strings.LastIndex(path, "node_modules/")
+		if idx < 0 {
+			// Workspace and link entries are not installed packages
+			continue
+		}
+		graph.addNode(path, path[idx+len("node_modules/"):], entry.Version, entry.depType())
+	}
+
+	for name := range rootDeps {
+		if target := resolveNPMPath(entries, "", name); target != "" {
+			graph.addEdge(graphRoot, target)
+		}
+	}
+
+	for path, entry := range entries {
+		for _, deps := range []map[string]string{entry.Dependencies, entry.Requires, entry.OptionalDependencies, entry.PeerDependencies} {
+			for name := range deps {
+				if target := resolveNPMPath(entries, path, name); target != "" {
+					graph.addEdge(path, target)
+				}
+			}
+		}
+	}
+
+	return graph, nil
+}
+
+// flattenNPMv1 converts the nested v1 dependency tree into v2-style install paths
+func flattenNPMv1(deps map[string]json.RawMessage, prefix string, entries map[string]npmLockEntry) error {
+	for name, raw := range deps {
+		var entry npmLockEntry
+		var nested struct {
+			Dependencies map[string]json.RawMessage `json:"dependencies"`
+		}
+		if err := json.Unmarshal(raw, &entry); err != nil {
+			return err
+		}
+		json.Unmarshal(raw, &nested)
+
+		path := prefix + "node_modules/" + name
+		entries[path] = entry
+		if err := flattenNPMv1(nested.Dependencies, path+"/", entries); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// resolveNPMPath finds the install path a package at from resolves name to,
+// following Node's lookup through parent node_modules directories
+func resolveNPMPath(entries map[string]npmLockEntry, from, name string) string {
+	dir := from
+	for {
+		candidate := "node_modules/" + name
+		if dir != "" {
+			candidate = dir + "/" + candidate
+		}
+		if _, ok := entries[candidate]; ok {
+			return candidate
+		}
+		if dir == "" {
+			return ""
+		}
+		idx := strings.LastIndex(dir, "/node_modules/")
+		if idx < 0 {
+			dir = ""
+		} else {
+			dir = dir[:idx]
+		}
+	}
+}
@@ -0,0 +1,348 @@
+package scanner
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// errNoOSVOutput reports an osv-scanner run that printed no JSON report
+var errNoOSVOutput = errors.New("no osv-scanner output")
+
+// osvPackage is a package entry of an osv-scanner result
+type osvPackage struct {
+	Package struct {
+		Name      string `json:"name"`
+		Version   string `json:"version"`
+		Ecosystem string `json:"ecosystem"`
+	} `json:"package"`
+	Vulnerabilities []struct {
+		ID       string   `json:"id"`
+		Aliases  []string `json:"aliases"`
+		Summary  string   `json:"summary"`
+		Details  string   `json:"details"`
+		Severity []struct {
+			Type  string `json:"type"`
+			Score string `json:"score"`
+		} `json:"severity"`
+		References []struct {
+			Type string `json:"type"`
+			URL  string `json:"url"`
+		} `json:"references"`
+		Groups []struct {
+			MaxSeverity string `json:"max_severity"`
+		} `json:"groups"`
+		Published string        `json:"published"`
+		Modified  string        `json:"modified"`
+		Affected  []osvAffected `json:"affected"`
+	} `json:"vulnerabilities"`
+	Groups []struct {
+		IDs                  []string `json:"ids"`
+		ExperimentalAnalysis map[string]struct {
+			Called bool `json:"called"`
+		} `json:"experimentalAnalysis"`
+	} `json:"groups"`
+	// Licenses are reported when osv-scanner runs with license scanning
+	Licenses []string `json:"licenses"`
+}
+
+// osvAffected is an affected entry of an OSV advisory
+type osvAffected struct {
+	Package struct {
+		Name      string `json:"name"`
+		Ecosystem string `json:"ecosystem"`
+	} `json:"package"`
+	Ranges []struct {
+		Type   string `json:"type"`
+		Events []struct {
+			Introduced   string `json:"introduced,omitempty"`
+			Fixed        string `json:"fixed,omitempty"`
+			LastAffected string `json:"last_affected,omitempty"`
+		} `json:"events"`
+	} `json:"ranges"`
+}
+
+// parseOSVOutput parses the JSON output from osv-scanner
+func (s *Scanner) parseOSVOutput(output []byte) ([]Vulnerability, error) {
+	return s.decodeOSV(bytes.NewReader(output))
+}
+
+// decodeOSV decodes an osv-scanner JSON report as a stream. Only one package
+// entry is held in memory at a time, so large monorepo reports do not have to
+// be materialised as a whole. Empty input returns errNoOSVOutput.
+func (s *Scanner) decodeOSV(r io.Reader) ([]Vulnerability, error) {
+	// Keep the start of the report for error messages
+	head := &headBuffer{limit: 500}
+	decoder := json.NewDecoder(io.TeeReader(r, head))
+
+	fail := func(err error) ([]Vulnerability, error) {
+		return nil, fmt.Errorf("failed to parse OSV output: %w\nFirst 500 chars of output: %s", err, head.String())
+	}
+
+	var vulnerabilities []Vulnerability
+	token, err := decoder.Token()
+	if err == io.EOF {
+		return nil, errNoOSVOutput
+	}
+	if err != nil {
+		return fail(err)
+	}
+	if token != json.Delim('{') {
+		return fail(fmt.Errorf("expected a JSON object"))
+	}
+
+	err = decodeObject(decoder, func(key string) error {
+		if key != "results" {
+			return skipValue(decoder)
+		}
+		return decodeArray(decoder, func() error {
+			first := len(vulnerabilities)
+			var source string
+			err := decodeObject(decoder, func(key string) error {
+				switch key {
+				case "source":
+					var value struct {
+						Path string `json:"path"`
+					}
+					if err := decoder.Decode(&value); err != nil {
+						return err
+					}
+					source = s.relativePath(value.Path)
+					return nil
+				case "packages":
+					return decodeArray(decoder, func() error {
+						var pkg osvPackage
+						if err := decoder.Decode(&pkg); err != nil {
+							return err
+						}
+						vulnerabilities = append(vulnerabilities, s.packageVulnerabilities(pkg)...)
+						return nil
+					})
+				default:
+					return skipValue(decoder)
+				}
+			}, true)
+
+			// The source may follow the packages of its result
+			for i := first; i < len(vulnerabilities); i++ {
+				vulnerabilities[i].Source = source
+			}
+			return err
+		})
+	}, false)
+	if err != nil {
+		return fail(err)
+	}
+
+	return vulnerabilities, nil
+}
+
+// packageVulnerabilities converts the advisories of a package entry
+func (s *Scanner) packageVulnerabilities(pkg osvPackage) []Vulnerability {
+	var vulnerabilities []Vulnerability
+	for _, vuln := range pkg.Vulnerabilities {
+		dependency := s.dependencyInfo(pkg.Package.Name, pkg.Package.Version)
+		v := Vulnerability{
+			ID:          vuln.ID,
+			Aliases:     vuln.Aliases,
+			Package:     pkg.Package.Name,
+			Version:     pkg.Package.Version,
+			Summary:     vuln.Summary,
+			Description: vuln.Details,
+			IsDirect:    dependency.IsDirect,
+			Ecosystem:   pkg.Package.Ecosystem,
+			Class:       ClassVulnerability,
+			Dependency:  dependency,
+			Licenses:    s.packageLicenses(pkg),
+		}
+
+		// Extract CVSS score and severity
+		for _, sev := range vuln.Severity {
+			if sev.Type == "CVSS_V3" {
+				if score, err := s.parseCVSSScore(sev.Score); err == nil {
+					v.CVSS = score
+					v.Severity = s.cvssToSeverity(score)
+				}
+			}
+		}
+
+		// If no CVSS found, try to use max_severity from groups
+		if v.CVSS == 0.0 && len(vuln.Groups) > 0 && vuln.Groups[0].MaxSeverity != "" {
+			if score, err := s.parseMaxSeverity(vuln.Groups[0].MaxSeverity); err == nil {
+				v.CVSS = score
+				v.Severity = s.cvssToSeverity(score)
+			}
+		}
+
+		// Call analysis marks whether the vulnerable code is called
+		for _, group := range pkg.Groups {
+			if analysis, ok := group.ExperimentalAnalysis[vuln.ID]; ok {
+				v.Reachability = ReachabilityUnreachable
+				if analysis.Called {
+					v.Reachability = ReachabilityReachable
+				}
+			}
+		}
+
+		// Advisory dates and fix events
+		v.Published = parseOSVTime(vuln.Published)
+		v.Modified = parseOSVTime(vuln.Modified)
+		v.FixedVersions = fixedVersions(vuln.Affected, pkg.Package.Name, pkg.Package.Version)
+
+		// Extract references
+		for _, ref := range vuln.References {
+			v.References = append(v.References, ref.URL)
+		}
+
+		vulnerabilities = append(vulnerabilities, v)
+	}
+	return vulnerabilities
+}
+
+// relativePath returns a scanned file path relative to the working
+// directory, with forward slashes
+func (s *Scanner) relativePath(path string) string {
+	if path == "" {
+		return ""
+	}
+	if s.WorkingDir != "" && filepath.IsAbs(path) {
+		if relative, err := filepath.Rel(s.WorkingDir, path); err == nil && !strings.HasPrefix(relative, "..") {
+			path = relative
+		}
+	}
+	return filepath.ToSlash(path)
+}
+
+// decodeObject calls field for each key of a JSON object, which must decode
+// or skip the value. With open set, the opening brace is read first.
+func decodeObject(decoder *json.Decoder, field func(key string) error, open bool) error {
+	if open {
+		if err := expectDelim(decoder, '{'); err != nil {
+			return err
+		}
+	}
+	for decoder.More() {
+		token, err := decoder.Token()
+		if err != nil {
+			return err
+		}
+		key, ok := token.(string)
+		if !ok {
+			return fmt.Errorf("expected an object key, got %v", token)
+		}
+		if err := field(key); err != nil {
+			return err
+		}
+	}
+	_, err := decoder.Token()
+	return err
+}
+
+// decodeArray calls element for each element of a JSON array. A null value
+// is treated as an empty array.
+func decodeArray(decoder *json.Decoder, element func() error) error {
+	token, err := decoder.Token()
+	if err != nil {
+		return err
+	}
+	if token == nil {
+		return nil
+	}
+	if token != json.Delim('[') {
+		return fmt.Errorf("expected an array, got %v", token)
+	}
+	for decoder.More() {
+		if err := element(); err != nil {
+			return err
+		}
+	}
+	_, err = decoder.Token()
+	return err
+}
+
+// expectDelim reads a delimiter token
+func expectDelim(decoder *json.Decoder, delim json.Delim) error {
+	token, err := decoder.Token()
+	if err != nil {
+		return err
+	}
+	if token != delim {
+		return fmt.Errorf("expected %v, got %v", delim, token)
+	}
+	return nil
+}
+
+// skipValue discards the next JSON value
+func skipValue(decoder *json.Decoder) error {
+	var discard json.RawMessage
+	return decoder.Decode(&discard)
+}
+
+// headBuffer keeps the first bytes written to it
+type headBuffer struct {
+	limit int
+	data  []byte
+}
+
+func (h *headBuffer) Write(p []byte) (int, error) {
+	if remaining := h.limit - len(h.data); remaining > 0 {
+		if len(p) < remaining {
+			remaining = len(p)
+		}
+		h.data = append(h.data, p[:remaining]...)
+	}
+	return len(p), nil
+}
+
+func (h *headBuffer) String() string {
+	if len(h.data) == h.limit {
+		return string(h.data) + "..."
+	}
+	return string(h.data)
+}
+
+// fixedVersions collects the fix events of the affected entries for a
+// package that are newer than the installed version. Entries of other
+// packages fix those packages, so they are not used.
+func fixedVersions(affected []osvAffected, packageName, installed string) []string {
+	var versions []string
+	seen := make(map[string]bool)
+	for _, entry := range affected {
+		if !strings.EqualFold(entry.Package.Name, packageName) {
+			continue
+		}
+		for _, r := range entry.Ranges {
+			for _, event := range r.Events {
+				if event.Fixed == "" || seen[event.Fixed] {
+					continue
+				}
+				// A fix at or below the installed version is for another
+				// affected range than the one installed
+				if installed != "" && CompareVersions(event.Fixed, installed) <= 0 {
+					continue
+				}
+				seen[event.Fixed] = true
+				versions = append(versions, event.Fixed)
+			}
+		}
+	}
+	return versions
+}
+
+// parseOSVTime parses an OSV RFC 3339 timestamp, returning nil when it is
+// missing or malformed
+func parseOSVTime(value string) *time.Time {
+	if value == "" {
+		return nil
+	}
+	parsed, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return nil
+	}
+	return &parsed
+}
@@ -0,0 +1,164 @@
+package scanner
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// SBOMPackage represents a single package entry from an SBOM
+type SBOMPackage struct {
+	ID       string   `json:"id"`
+	Name     string   `json:"name"`
+	Version  string   `json:"version"`
+	Licenses []string `json:"licenses,omitempty"`
+}
+
+// SBOM holds the package inventory and direct dependencies recorded in a syft SBOM
+type SBOM struct {
+	Packages []SBOMPackage
+	roots    map[string]bool
+	direct   map[string]bool
+	graph    *DependencyGraph
+}
+
+// spdxDocument is the subset of an SPDX 2.x JSON document read by dep-risk
+type spdxDocument struct {
+	SPDXVersion       string   `json:"spdxVersion"`
+	DocumentDescribes []string `json:"documentDescribes"`
+	Packages          []struct {
+		SPDXID      string `json:"SPDXID"`
+		Name        string `json:"name"`
+		VersionInfo string `json:"versionInfo"`
+		// License fields hold SPDX expressions, NOASSERTION or NONE
+		LicenseDeclared  string `json:"licenseDeclared"`
+		LicenseConcluded string `json:"licenseConcluded"`
+	} `json:"packages"`
+	Relationships []struct {
+		Element string `json:"spdxElementId"`
+		Type    string `json:"relationshipType"`
+		Related string `json:"relatedSpdxElement"`
+	} `json:"relationships"`
+}
+
+// LoadSBOM reads a syft SBOM in SPDX JSON format
+func LoadSBOM(path string) (*SBOM, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read SBOM: %w", err)
+	}
+	return parseSBOM(data)
+}
+
+// parseSBOM parses an SPDX JSON document and resolves the direct dependencies
+// of the described root packages
+func parseSBOM(data []byte) (*SBOM, error) {
+	var doc spdxDocument
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return nil, fmt.Errorf("failed to parse SBOM: %w", err)
+	}
+	if doc.SPDXVersion == "" {
+		return nil, fmt.Errorf("unsupported SBOM format: only SPDX JSON is supported")
+	}
+
+	sbom := &SBOM{direct: make(map[string]bool), graph: newDependencyGraph()}
+	names := make(map[string]string)
+	for _, pkg := range doc.Packages {
+		names[pkg.SPDXID] = pkg.Name
+		sbom.graph.addNode(pkg.SPDXID, pkg.Name, pkg.VersionInfo, "")
+		sbom.Packages = append(sbom.Packages, SBOMPackage{
+			ID:       pkg.SPDXID,
+			Name:     pkg.Name,
+			Version:  pkg.VersionInfo,
+			Licenses: spdxLicenses(pkg.LicenseDeclared, pkg.LicenseConcluded),
+		})
+	}
+
+	roots := make(map[string]bool)
+	sbom.roots = roots
+	for _, id := range doc.DocumentDescribes {
+		roots[id] = true
+	}
+	for _, rel := range doc.Relationships {
+		if rel.Type == "DESCRIBES" {
+			roots[rel.Related] = true
+		}
+	}
+
+	// Root packages are the project itself
+	nodeID := func(id string) string {
+		if roots[id] {
+			return graphRoot
+		}
+		return id
+	}
+
+	for _, rel := range doc.Relationships {
+		switch rel.Type {
+		case "DEPENDS_ON":
+			if roots[rel.Element] {
+				sbom.direct[names[rel.Related]] = true
+			}
+			sbom.graph.addEdge(nodeID(rel.Element), nodeID(rel.Related))
+		case "DEPENDENCY_OF", "DEV_DEPENDENCY_OF", "OPTIONAL_DEPENDENCY_OF":
+			if roots[rel.Related] {
+				sbom.direct[names[rel.Element]] = true
+			}
+			sbom.graph.addEdge(nodeID(rel.Related), nodeID(rel.Element))
+			if node, ok := sbom.graph.nodes[rel.Element]; ok {
+				switch rel.Type {
+				case "DEV_DEPENDENCY_OF":
+					node.depType = DependencyDevelopment
+				case "OPTIONAL_DEPENDENCY_OF":
+					node.depType = DependencyOptional
+				}
+			}
+		}
+	}
+
+	return sbom, nil
+}
+
+// spdxLicenses returns the declared license expression of a package, or the
+// concluded one when the package declares none
+func spdxLicenses(declared, concluded string) []string {
+	for _, expression := range []string{declared, concluded} {
+		if expression != "" && expression != "NOASSERTION" && expression != "NONE" {
+			return []string{expression}
+		}
+	}
+	return nil
+}
+
+// Licenses returns the licenses of a package version. Versions are compared
+// without a leading v, as syft records Go module versions with one.
+func (s *SBOM) Licenses(name, version string) []string {
+	for _, pkg := range s.Packages {
+		if pkg.Name == name && strings.TrimPrefix(pkg.Version, "v") == strings.TrimPrefix(version, "v") {
+			return pkg.Licenses
+		}
+	}
+	return nil
+}
+
+// DependencyCount returns the number of packages other than the described roots
+func (s *SBOM) DependencyCount() int {
+	count := 0
+	for _, pkg := range s.Packages {
+		if !s.roots[pkg.ID] {
+			count++
+		}
+	}
+	return count
+}
+
+// IsDirect reports whether a package is a direct dependency of the SBOM root.
+// The second return value is false when the SBOM records no dependency
+// relationships and direct dependencies cannot be determined from it.
+func (s *SBOM) IsDirect(packageName string) (bool, bool) {
+	if len(s.direct) == 0 {
+		return false, false
+	}
+	return s.direct[packageName], true
+}
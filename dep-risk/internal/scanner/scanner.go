@@ -1,18 +1,23 @@
 package scanner
 
 import (
-	"encoding/json"
+	"bufio"
+	"bytes"
+	"errors"
 	"fmt"
+	"io"
 	"os"
 	"os/exec"
 	"path/filepath"
 	"strconv"
 	"strings"
+	"time"
 )
 
 // Vulnerability represents a single vulnerability found by the scanner
 type Vulnerability struct {
 	ID          string  `json:"id"`
+	Aliases     []string `json:"aliases,omitempty"`
 	Package     string  `json:"package"`
 	Version     string  `json:"version"`
 	CVSS        float64 `json:"cvss"`
@@ -21,6 +26,74 @@ type Vulnerability struct {
 	Description string  `json:"description"`
 	References  []string `json:"references"`
 	IsDirect    bool    `json:"is_direct"`
+	Ecosystem   string  `json:"ecosystem,omitempty"`
+	Class       string  `json:"class,omitempty"`
+	File        string  `json:"file,omitempty"`
+	Source      string  `json:"source,omitempty"`
+	Line        int     `json:"line,omitempty"`
+	Dependency  *DependencyInfo `json:"dependency,omitempty"`
+	Reachability string `json:"reachability,omitempty"`
+	Published   *time.Time `json:"published,omitempty"`
+	Modified    *time.Time `json:"modified,omitempty"`
+	FixedVersions []string `json:"fixed_versions,omitempty"`
+	KEV         bool    `json:"kev,omitempty"`
+	// Licenses are the SPDX license expressions of the package version
+	Licenses    []string `json:"licenses,omitempty"`
+}
+
+// FixAvailable reports whether the advisory names a version fixing the
+// vulnerability. The installed version is affected, so the fix is not applied.
+func (v Vulnerability) FixAvailable() bool {
+	return len(v.FixedVersions) > 0
+}
+
+// DaysSinceDisclosure returns the whole days between publication of the
+// advisory and now, and false when the publication date is unknown
+func (v Vulnerability) DaysSinceDisclosure(now time.Time) (int, bool) {
+	if v.Published == nil {
+		return 0, false
+	}
+	days := int(now.Sub(*v.Published).Hours() / 24)
+	if days < 0 {
+		days = 0
+	}
+	return days, true
+}
+
+// Reachability values from osv-scanner call analysis
+const (
+	ReachabilityReachable   = "reachable"
+	ReachabilityUnreachable = "unreachable"
+	ReachabilityUnknown     = "unknown"
+)
+
+// Finding classes distinguish advisories from other dependency risks
+const (
+	ClassVulnerability = "vulnerability"
+	ClassHygiene       = "hygiene"
+	ClassEOL           = "eol"
+	ClassWorkflow      = "workflow"
+)
+
+// FindingClass returns the finding class, treating unset classes as vulnerabilities
+func (v Vulnerability) FindingClass() string {
+	if v.Class == "" {
+		return ClassVulnerability
+	}
+	return v.Class
+}
+
+// DependencyInfo returns the dependency graph position of the finding,
+// derived from IsDirect when the scanner did not record one
+func (v Vulnerability) DependencyInfo() DependencyInfo {
+	if v.Dependency != nil {
+		return *v.Dependency
+	}
+	info := DependencyInfo{IsDirect: v.IsDirect}
+	if v.IsDirect {
+		info.Depth = 1
+	}
+	return info
 }
 
 // ScanResult represents the complete scan results
@@ -30,6 +103,29 @@ type ScanResult struct {
 	HighRiskCount   int            `json:"high_risk_count"`
 	MediumRiskCount int            `json:"medium_risk_count"`
 	LowRiskCount    int            `json:"low_risk_count"`
+	Diagnostics     []Diagnostic   `json:"diagnostics,omitempty"`
+	DependencyCount int            `json:"dependency_count,omitempty"`
+}
+
+// AddFindings appends findings from additional detectors and updates the counts
+func (r *ScanResult) AddFindings(findings []Vulnerability) {
+	for _, finding := range findings {
+		r.Vulnerabilities = append(r.Vulnerabilities, finding)
+		r.TotalCount++
+		switch finding.Severity {
+		case "CRITICAL", "HIGH":
+			r.HighRiskCount++
+		case "MEDIUM":
+			r.MediumRiskCount++
+		case "LOW":
+			r.LowRiskCount++
+		}
+	}
+}
+
+// IsPartial reports whether tool diagnostics indicate that some dependencies were not scanned
+func (r *ScanResult) IsPartial() bool {
+	return PartialScan(r.Diagnostics)
 }
 
 // Scanner handles vulnerability scanning operations
@@ -37,6 +133,12 @@ type Scanner struct {
 	SyftPath      string
 	OSVScannerPath string
 	WorkingDir    string
+
+	sbom  *SBOM
+	graph *DependencyGraph
+	// replaying is set by ReplayProject: the working directory is not the
+	// scanned tree, so its manifests say nothing about the findings
+	replaying bool
 }
 
 // NewScanner creates a new scanner instance
@@ -51,168 +153,167 @@ func NewScanner(workingDir string) *Scanner {
 // ScanProject scans the project for vulnerabilities
 func (s *Scanner) ScanProject() (*ScanResult, error) {
 	// Step 1: Generate SBOM using syft
-	sbomPath, err := s.generateSBOM()
+	sbomPath, syftDiagnostics, err := s.generateSBOM()
 	if err != nil {
 		return nil, fmt.Errorf("failed to generate SBOM: %w", err)
 	}
 	defer os.Remove(sbomPath)
 
+	s.graph = LoadDependencyGraph(s.WorkingDir)
+	// The SBOM is parsed once; it also settles direct status for packages
+	// the lockfiles do not place
+	var sbomDiagnostics []Diagnostic
+	if s.sbom, err = LoadSBOM(sbomPath); err != nil {
+		sbomDiagnostics = append(sbomDiagnostics, Diagnostic{
+			Tool:    "syft",
+			Kind:    DiagnosticLookupFailure,
+			Message: fmt.Sprintf("%v; dependency placement and counts fall back to the lockfiles", err),
+		})
+	}
+
 	// Step 2: Scan SBOM with osv-scanner
-	vulnerabilities, err := s.scanWithOSV(sbomPath)
+	vulnerabilities, osvDiagnostics, err := s.scanWithOSV(sbomPath)
 	if err != nil {
 		return nil, fmt.Errorf("failed to scan with OSV: %w", err)
 	}
 
 	// Step 3: Process and categorize results
 	result := s.processResults(vulnerabilities)
+	result.Diagnostics = append(append(syftDiagnostics, sbomDiagnostics...), osvDiagnostics...)
+	result.DependencyCount = s.dependencyCount()
 	
 	return result, nil
 }
 
+// ReplayProject runs the scan pipeline from a recorded osv-scanner JSON report
+// and an optional syft SBOM instead of invoking the external tools
+func (s *Scanner) ReplayProject(osvPath, sbomPath string) (*ScanResult, error) {
+	s.replaying = true
+	s.sbom = nil
+	if sbomPath != "" {
+		sbom, err := LoadSBOM(sbomPath)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load replay SBOM: %w", err)
+		}
+		s.sbom = sbom
+	}
+
+	// Only the recorded SBOM describes the scanned tree; without it the
+	// place of each package is unknown
+	if s.sbom != nil && !s.sbom.graph.isEmpty() {
+		s.graph = s.sbom.graph
+	} else {
+		s.graph = newDependencyGraph()
+	}
+
+	output, err := os.Open(osvPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read recorded OSV output: %w", err)
+	}
+	defer output.Close()
+
+	vulnerabilities, err := s.decodeOSV(bufio.NewReader(output))
+	if err != nil {
+		return nil, fmt.Errorf("failed to replay OSV output: %w", err)
+	}
+
+	result := s.processResults(vulnerabilities)
+	result.DependencyCount = s.dependencyCount()
+	return result, nil
+}
+
+// dependencyCount counts the packages of the SBOM, or of the dependency graph
+// when there is no SBOM or it lists no packages
+func (s *Scanner) dependencyCount() int {
+	if s.sbom != nil && s.sbom.DependencyCount() > 0 {
+		return s.sbom.DependencyCount()
+	}
+	return s.graph.Size()
+}
+
 // generateSBOM creates a Software Bill of Materials using syft
-func (s *Scanner) generateSBOM() (string, error) {
+func (s *Scanner) generateSBOM() (string, []Diagnostic, error) {
 	// Use /tmp for SBOM file to avoid permission issues
 	sbomPath := filepath.Join("/tmp", "sbom.json")
 	
 	cmd := exec.Command(s.SyftPath, s.WorkingDir, "-o", "spdx-json="+sbomPath)
 	cmd.Dir = s.WorkingDir
 	
-	output, err := cmd.CombinedOutput()
-	if err != nil {
-		return "", fmt.Errorf("syft command failed: %w, output: %s", err, string(output))
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	
+	if err := cmd.Run(); err != nil {
+		return "", nil, fmt.Errorf("syft command failed: %w, stderr: %s", err, stderr.String())
 	}
 	
-	return sbomPath, nil
+	return sbomPath, parseDiagnostics("syft", stderr.Bytes()), nil
 }
 
 // scanWithOSV scans the project directory directly with osv-scanner
-func (s *Scanner) scanWithOSV(sbomPath string) ([]Vulnerability, error) {
+func (s *Scanner) scanWithOSV(sbomPath string) ([]Vulnerability, []Diagnostic, error) {
 	// Use direct directory scan instead of SBOM for better compatibility
 	cmd := exec.Command(s.OSVScannerPath, "--format", "json", s.WorkingDir)
 	cmd.Dir = s.WorkingDir
 	
-	output, err := cmd.CombinedOutput()
+	// Keep the JSON report on stdout apart from warnings on stderr, and
+	// decode it while osv-scanner writes it
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	stdout, err := cmd.StdoutPipe()
 	if err != nil {
-		// osv-scanner returns non-zero exit code when vulnerabilities are found
-		// We need to check if it's a real error or just vulnerabilities found
-		if len(output) == 0 {
-			return nil, fmt.Errorf("osv-scanner command failed: %w", err)
-		}
+		return nil, nil, fmt.Errorf("failed to start osv-scanner: %w", err)
 	}
-	
-	// Filter out non-JSON output (warnings, etc.)
-	jsonOutput := s.extractJSONFromOutput(output)
-	return s.parseOSVOutput(jsonOutput)
-}
-
-// parseOSVOutput parses the JSON output from osv-scanner
-func (s *Scanner) parseOSVOutput(output []byte) ([]Vulnerability, error) {
-	var osvResult struct {
-		Results []struct {
-			Source struct {
-				Path string `json:"path"`
-				Type string `json:"type"`
-			} `json:"source"`
-			Packages []struct {
-				Package struct {
-					Name      string `json:"name"`
-					Version   string `json:"version"`
-					Ecosystem string `json:"ecosystem"`
-				} `json:"package"`
-				Vulnerabilities []struct {
-					ID       string `json:"id"`
-					Summary  string `json:"summary"`
-					Details  string `json:"details"`
-					Severity []struct {
-						Type  string  `json:"type"`
-						Score string  `json:"score"`
-					} `json:"severity"`
-					References []struct {
-						Type string `json:"type"`
-						URL  string `json:"url"`
-					} `json:"references"`
-					Groups []struct {
-						MaxSeverity string `json:"max_severity"`
-					} `json:"groups"`
-				} `json:"vulnerabilities"`
-			} `json:"packages"`
-		} `json:"results"`
+	if err := cmd.Start(); err != nil {
+		return nil, nil, fmt.Errorf("failed to start osv-scanner: %w", err)
 	}
 	
-	if err := json.Unmarshal(output, &osvResult); err != nil {
-		// Debug: print first 500 chars of output for troubleshooting
-		debugOutput := string(output)
-		if len(debugOutput) > 500 {
-			debugOutput = debugOutput[:500] + "..."
+	vulnerabilities, decodeErr := s.decodeOSV(bufio.NewReader(stdout))
+	// Drain the rest so osv-scanner can exit
+	io.Copy(io.Discard, stdout)
+	err = cmd.Wait()
+	diagnostics := parseDiagnostics("osv-scanner", stderr.Bytes())
+	
+	if errors.Is(decodeErr, errNoOSVOutput) {
+		// osv-scanner returns non-zero exit code when vulnerabilities are found,
+		// so only a failure without a report is a real error
+		if err != nil {
+			return nil, diagnostics, fmt.Errorf("osv-scanner command failed: %w, stderr: %s", err, stderr.String())
 		}
-		return nil, fmt.Errorf("failed to parse OSV output: %w\nFirst 500 chars of output: %s", err, debugOutput)
+		// No report means osv-scanner found nothing to scan; the diagnostics say why
+		return nil, diagnostics, nil
 	}
-	
-	var vulnerabilities []Vulnerability
-	
-	for _, result := range osvResult.Results {
-		for _, pkg := range result.Packages {
-			for _, vuln := range pkg.Vulnerabilities {
-				v := Vulnerability{
-					ID:          vuln.ID,
-					Package:     pkg.Package.Name,
-					Version:     pkg.Package.Version,
-					Summary:     vuln.Summary,
-					Description: vuln.Details,
-					IsDirect:    s.isDirect(pkg.Package.Name),
-				}
-				
-				// Extract CVSS score and severity
-				for _, sev := range vuln.Severity {
-					if sev.Type == "CVSS_V3" {
-						if score, err := s.parseCVSSScore(sev.Score); err == nil {
-							v.CVSS = score
-							v.Severity = s.cvssToSeverity(score)
-						}
-					}
-				}
-				
-				// If no CVSS found, try to use max_severity from groups
-				if v.CVSS == 0.0 && len(vuln.Groups) > 0 && vuln.Groups[0].MaxSeverity != "" {
-					if score, err := s.parseMaxSeverity(vuln.Groups[0].MaxSeverity); err == nil {
-						v.CVSS = score
-						v.Severity = s.cvssToSeverity(score)
-					}
-				}
-				
-				// Extract references
-				for _, ref := range vuln.References {
-					v.References = append(v.References, ref.URL)
-				}
-				
-				vulnerabilities = append(vulnerabilities, v)
-			}
+	if decodeErr != nil {
+		return nil, diagnostics, decodeErr
+	}
+	return vulnerabilities, diagnostics, nil
+}
+
+// dependencyInfo locates a package in the dependency graph, falling back to
+// the direct/transitive heuristic for packages the graph does not place
+func (s *Scanner) dependencyInfo(name, version string) *DependencyInfo {
+	if s.graph != nil {
+		if info, found := s.graph.Info(name, version); found && info.Depth > 0 {
+			return &info
 		}
 	}
-	
-	return vulnerabilities, nil
+
+	info := DependencyInfo{IsDirect: s.isDirect(name)}
+	if info.IsDirect {
+		info.Depth = 1
+	}
+	return &info
 }
 
-// extractJSONFromOutput filters out non-JSON content from osv-scanner output
-func (s *Scanner) extractJSONFromOutput(output []byte) []byte {
-	outputStr := string(output)
-	
-	// Find the start of JSON output (first '{' character)
-	jsonStart := strings.Index(outputStr, "{")
-	if jsonStart == -1 {
-		return []byte("{\"results\":[]}")
+// packageLicenses returns the licenses osv-scanner reported for a package,
+// or those recorded in the SBOM
+func (s *Scanner) packageLicenses(pkg osvPackage) []string {
+	if len(pkg.Licenses) > 0 {
+		return pkg.Licenses
 	}
-	
-	// Extract everything from the first '{' to the end
-	jsonPart := outputStr[jsonStart:]
-	
-	// Find the last '}' to ensure we have complete JSON
-	jsonEnd := strings.LastIndex(jsonPart, "}")
-	if jsonEnd == -1 {
-		return []byte("{\"results\":[]}")
+	if s.sbom != nil {
+		return s.sbom.Licenses(pkg.Package.Name, pkg.Package.Version)
 	}
-	
-	return []byte(jsonPart[:jsonEnd+1])
+	return nil
 }
 
 // isDirect determines if a package is a direct dependency
@@ -221,6 +322,16 @@ func (s *Scanner) isDirect(packageName string) bool {
 	// In a real implementation, we would parse go.mod, package.json, etc.
 	// to determine direct vs transitive dependencies
 	
+	// Prefer the dependency relationships recorded in a replayed SBOM
+	if s.sbom != nil {
+		if direct, known := s.sbom.IsDirect(packageName); known {
+			return direct
+		}
+	}
+	if s.replaying {
+		return false
+	}
+
 	// Check for go.mod
 	goModPath := filepath.Join(s.WorkingDir, "go.mod")
 	if content, err := os.ReadFile(goModPath); err == nil {
@@ -269,22 +380,8 @@ func (s *Scanner) cvssToSeverity(score float64) string {
 
 // processResults categorizes and counts vulnerabilities
 func (s *Scanner) processResults(vulnerabilities []Vulnerability) *ScanResult {
-	result := &ScanResult{
-		Vulnerabilities: vulnerabilities,
-		TotalCount:      len(vulnerabilities),
-	}
-	
-	for _, vuln := range vulnerabilities {
-		switch vuln.Severity {
-		case "CRITICAL", "HIGH":
-			result.HighRiskCount++
-		case "MEDIUM":
-			result.MediumRiskCount++
-		case "LOW":
-			result.LowRiskCount++
-		}
-	}
-	
+	result := &ScanResult{}
+	result.AddFindings(vulnerabilities)
 	return result
 }
 
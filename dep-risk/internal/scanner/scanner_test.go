@@ -1,9 +1,15 @@
 package scanner
 
 import (
+	"bytes"
 	"encoding/json"
+	"errors"
+	"fmt"
 	"os"
 	"path/filepath"
+	"reflect"
+	"runtime"
+	"strings"
 	"testing"
 )
 
@@ -63,8 +69,30 @@ func TestParseOSVOutput(t *testing.T) {
 									{
 										"max_severity": "4.3"
 									}
+								],
+								"published": "2023-05-11T18:30:05Z",
+								"modified": "2023-11-07T05:02:27Z",
+								"affected": [
+									{
+										"package": {"name": "github.com/gin-gonic/gin", "ecosystem": "Go"},
+										"ranges": [
+											{"type": "SEMVER", "events": [{"introduced": "0"}, {"fixed": "1.9.1"}]}
+										]
+									},
+									{
+										"package": {"name": "github.com/other/module", "ecosystem": "Go"},
+										"ranges": [
+											{"type": "SEMVER", "events": [{"introduced": "0"}, {"fixed": "2.0.0"}]}
+										]
+									}
 								]
 							}
+						],
+						"groups": [
+							{
+								"ids": ["GHSA-2c4m-59x9-fr2g"],
+								"experimentalAnalysis": {"GHSA-2c4m-59x9-fr2g": {"called": false}}
+							}
 						]
 					}
 				]
@@ -97,36 +125,98 @@ func TestParseOSVOutput(t *testing.T) {
 	if vuln.Summary != "Test vulnerability" {
 		t.Errorf("Expected Summary 'Test vulnerability', got %s", vuln.Summary)
 	}
+	
+	if vuln.Reachability != ReachabilityUnreachable {
+		t.Errorf("Expected reachability from call analysis, got %q", vuln.Reachability)
+	}
+	
+	if vuln.Published == nil || vuln.Published.Format("2006-01-02") != "2023-05-11" || vuln.Modified == nil {
+		t.Errorf("Expected advisory dates, got published %v and modified %v", vuln.Published, vuln.Modified)
+	}
+	
+	if !vuln.FixAvailable() || len(vuln.FixedVersions) != 1 || vuln.FixedVersions[0] != "1.9.1" {
+		t.Errorf("Expected the fix for the scanned package only, got %v", vuln.FixedVersions)
+	}
+	
+	if days, ok := vuln.DaysSinceDisclosure(vuln.Published.AddDate(0, 0, 30)); !ok || days != 30 {
+		t.Errorf("Expected 30 days since disclosure, got %d", days)
+	}
 }
 
-func TestExtractJSONFromOutput(t *testing.T) {
-	scanner := NewScanner("/tmp")
+func TestFixedVersions(t *testing.T) {
+	var affected []osvAffected
+	if err := json.Unmarshal([]byte(`[
+		{"package": {"name": "lodash"}, "ranges": [{"type": "SEMVER", "events": [
+			{"introduced": "0"}, {"fixed": "3.10.2"}, {"introduced": "4.0.0"}, {"fixed": "4.17.21"}
+		]}]},
+		{"package": {"name": "lodash-es"}, "ranges": [{"type": "SEMVER", "events": [{"introduced": "0"}, {"fixed": "4.17.22"}]}]}
+	]`), &affected); err != nil {
+		t.Fatal(err)
+	}
 	
-	// Test with mixed output (warnings + JSON)
-	mixedOutput := `Scanning dir .
+	tests := []struct {
+		name      string
+		installed string
+		want      []string
+	}{
+		{"lodash", "4.17.20", []string{"4.17.21"}},
+		{"lodash", "3.10.1", []string{"3.10.2", "4.17.21"}},
+		{"lodash", "", []string{"3.10.2", "4.17.21"}},
+		// Fixes of other packages in the advisory do not apply
+		{"underscore", "1.0.0", nil},
+	}
+	for _, tt := range tests {
+		if got := fixedVersions(affected, tt.name, tt.installed); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("fixedVersions(%s@%s) = %v, want %v", tt.name, tt.installed, got, tt.want)
+		}
+	}
+}
+
+func TestParseDiagnostics(t *testing.T) {
+	stderr := `Scanning dir /test
 Scanned /test/go.mod file and found 4 packages
-{
-  "results": [
-    {
-      "source": {
-        "path": "/test/go.mod",
-        "type": "lockfile"
-      }
-    }
-  ]
-}`
+Attempted to scan lockfile but failed: /test/poetry.lock
+Ignored invalid package {"name": "broken", "version": ""}
+failed to query OSV API: dial tcp: lookup api.osv.dev: no such host
+Warning: experimental feature {call-analysis} enabled
+Warning: experimental feature {call-analysis} enabled`
 	
-	jsonOutput := scanner.extractJSONFromOutput([]byte(mixedOutput))
+	diagnostics := parseDiagnostics("osv-scanner", []byte(stderr))
 	
-	// Verify it's valid JSON
-	var result map[string]interface{}
-	if err := json.Unmarshal(jsonOutput, &result); err != nil {
-		t.Fatalf("Extracted output is not valid JSON: %v", err)
+	expected := []struct {
+		kind DiagnosticKind
+		path string
+	}{
+		{DiagnosticUnsupportedLockfile, "/test/poetry.lock"},
+		{DiagnosticSkippedPackage, ""},
+		{DiagnosticNetworkFailure, ""},
+		{DiagnosticWarning, ""},
 	}
 	
-	// Verify structure
-	if results, ok := result["results"].([]interface{}); !ok || len(results) == 0 {
-		t.Error("Expected results array in extracted JSON")
+	if len(diagnostics) != len(expected) {
+		t.Fatalf("Expected %d diagnostics, got %d: %+v", len(expected), len(diagnostics), diagnostics)
+	}
+	
+	for i, exp := range expected {
+		if diagnostics[i].Kind != exp.kind {
+			t.Errorf("Diagnostic %d: expected kind %s, got %s", i, exp.kind, diagnostics[i].Kind)
+		}
+		if diagnostics[i].Path != exp.path {
+			t.Errorf("Diagnostic %d: expected path %q, got %q", i, exp.path, diagnostics[i].Path)
+		}
+		if diagnostics[i].Tool != "osv-scanner" {
+			t.Errorf("Diagnostic %d: expected tool osv-scanner, got %s", i, diagnostics[i].Tool)
+		}
+	}
+	
+	result := &ScanResult{Diagnostics: diagnostics}
+	if !result.IsPartial() {
+		t.Error("Expected scan with unsupported lockfile to be partial")
+	}
+	
+	result = &ScanResult{Diagnostics: diagnostics[3:]}
+	if result.IsPartial() {
+		t.Error("Expected scan with only generic warnings to be complete")
 	}
 }
 
@@ -280,4 +370,365 @@ func TestProcessResults(t *testing.T) {
 	if result.LowRiskCount != 1 {
 		t.Errorf("Expected LowRiskCount 1, got %d", result.LowRiskCount)
 	}
-}
\ No newline at end of file
+}
+
+func TestParseSBOM(t *testing.T) {
+	sbomContent := `{
+		"spdxVersion": "SPDX-2.3",
+		"documentDescribes": ["SPDXRef-root"],
+		"packages": [
+			{"SPDXID": "SPDXRef-root", "name": "test-project"},
+			{"SPDXID": "SPDXRef-gin", "name": "github.com/gin-gonic/gin", "versionInfo": "v1.9.0", "licenseDeclared": "MIT"},
+			{"SPDXID": "SPDXRef-json", "name": "github.com/goccy/go-json", "versionInfo": "v0.10.2", "licenseDeclared": "NOASSERTION", "licenseConcluded": "MIT"}
+		],
+		"relationships": [
+			{"spdxElementId": "SPDXRef-root", "relationshipType": "DEPENDS_ON", "relatedSpdxElement": "SPDXRef-gin"},
+			{"spdxElementId": "SPDXRef-gin", "relationshipType": "DEPENDS_ON", "relatedSpdxElement": "SPDXRef-json"}
+		]
+	}`
+	
+	sbom, err := parseSBOM([]byte(sbomContent))
+	if err != nil {
+		t.Fatalf("parseSBOM failed: %v", err)
+	}
+	
+	if len(sbom.Packages) != 3 {
+		t.Errorf("Expected 3 packages, got %d", len(sbom.Packages))
+	}
+	
+	if direct, known := sbom.IsDirect("github.com/gin-gonic/gin"); !known || !direct {
+		t.Error("Expected gin to be a direct dependency")
+	}
+	
+	if direct, known := sbom.IsDirect("github.com/goccy/go-json"); !known || direct {
+		t.Error("Expected go-json to be a transitive dependency")
+	}
+	
+	if licenses := sbom.Licenses("github.com/gin-gonic/gin", "1.9.0"); !reflect.DeepEqual(licenses, []string{"MIT"}) {
+		t.Errorf("Expected the declared license of gin, got %v", licenses)
+	}
+	
+	if licenses := sbom.Licenses("github.com/goccy/go-json", "v0.10.2"); !reflect.DeepEqual(licenses, []string{"MIT"}) {
+		t.Errorf("Expected the concluded license without a declared one, got %v", licenses)
+	}
+	
+	if _, err := parseSBOM([]byte(`{"bomFormat": "CycloneDX"}`)); err == nil {
+		t.Error("Expected error for non-SPDX SBOM")
+	}
+}
+
+func TestReplayProject(t *testing.T) {
+	tempDir, err := os.MkdirTemp("", "scanner_replay")
+	if err != nil {
+		t.Fatalf("Failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(tempDir)
+	
+	osvContent := `{
+		"results": [{
+			"source": {"path": "/customer/go.mod", "type": "lockfile"},
+			"packages": [{
+				"package": {"name": "github.com/gin-gonic/gin", "version": "1.9.0", "ecosystem": "Go"},
+				"vulnerabilities": [{
+					"id": "GHSA-2c4m-59x9-fr2g",
+					"summary": "Test vulnerability",
+					"groups": [{"max_severity": "7.5"}]
+				}]
+			}]
+		}]
+	}`
+	sbomContent := `{
+		"spdxVersion": "SPDX-2.3",
+		"documentDescribes": ["SPDXRef-root"],
+		"packages": [
+			{"SPDXID": "SPDXRef-root", "name": "customer-project"},
+			{"SPDXID": "SPDXRef-gin", "name": "github.com/gin-gonic/gin", "versionInfo": "v1.9.0"}
+		],
+		"relationships": [
+			{"spdxElementId": "SPDXRef-root", "relationshipType": "DEPENDS_ON", "relatedSpdxElement": "SPDXRef-gin"}
+		]
+	}`
+	
+	osvPath := filepath.Join(tempDir, "osv.json")
+	sbomPath := filepath.Join(tempDir, "sbom.json")
+	if err := os.WriteFile(osvPath, []byte(osvContent), 0644); err != nil {
+		t.Fatalf("Failed to write OSV fixture: %v", err)
+	}
+	if err := os.WriteFile(sbomPath, []byte(sbomContent), 0644); err != nil {
+		t.Fatalf("Failed to write SBOM fixture: %v", err)
+	}
+	
+	// The working directory has no manifests, so direct detection must come from the SBOM
+	scanner := NewScanner(tempDir)
+	result, err := scanner.ReplayProject(osvPath, sbomPath)
+	if err != nil {
+		t.Fatalf("ReplayProject failed: %v", err)
+	}
+	
+	if result.TotalCount != 1 {
+		t.Fatalf("Expected 1 vulnerability, got %d", result.TotalCount)
+	}
+	
+	vuln := result.Vulnerabilities[0]
+	if vuln.CVSS != 7.5 || vuln.Severity != "HIGH" {
+		t.Errorf("Expected CVSS 7.5 (HIGH), got %f (%s)", vuln.CVSS, vuln.Severity)
+	}
+	
+	if !vuln.IsDirect {
+		t.Error("Expected gin to be marked direct from the replayed SBOM")
+	}
+	
+	if vuln.Dependency == nil || vuln.Dependency.Depth != 1 {
+		t.Errorf("Expected dependency depth 1 from the replayed SBOM, got %+v", vuln.Dependency)
+	}
+	
+	if result.HighRiskCount != 1 {
+		t.Errorf("Expected HighRiskCount 1, got %d", result.HighRiskCount)
+	}
+	
+	if result.DependencyCount != 1 {
+		t.Errorf("Expected the SBOM root to be excluded from the dependency count, got %d", result.DependencyCount)
+	}
+	
+	// Without an SBOM the local manifests do not describe the replayed tree
+	goMod := "module example.com/local\n\nrequire github.com/gin-gonic/gin v1.9.0\n"
+	if err := os.WriteFile(filepath.Join(tempDir, "go.mod"), []byte(goMod), 0644); err != nil {
+		t.Fatalf("Failed to write go.mod: %v", err)
+	}
+	result, err = NewScanner(tempDir).ReplayProject(osvPath, "")
+	if err != nil {
+		t.Fatalf("ReplayProject without SBOM failed: %v", err)
+	}
+	if vuln := result.Vulnerabilities[0]; vuln.IsDirect || vuln.Dependency == nil || vuln.Dependency.Depth != 0 {
+		t.Errorf("Expected unknown direct status without an SBOM, got direct %v, %+v", vuln.IsDirect, vuln.Dependency)
+	}
+	
+	if _, err := scanner.ReplayProject(filepath.Join(tempDir, "missing.json"), ""); err == nil {
+		t.Error("Expected error for missing OSV report")
+	}
+}
+
+func TestScanProjectUnreadableSBOM(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("fake tools are shell scripts")
+	}
+	toolDir := t.TempDir()
+	
+	// syft writes an SBOM dep-risk cannot parse; osv-scanner finds nothing
+	syft := "#!/bin/sh\nfor arg in \"$@\"; do case \"$arg\" in spdx-json=*) echo 'not json' > \"${arg#spdx-json=}\";; esac; done\n"
+	osv := "#!/bin/sh\necho '{\"results\": []}'\n"
+	if err := os.WriteFile(filepath.Join(toolDir, "syft"), []byte(syft), 0755); err != nil {
+		t.Fatalf("Failed to write fake syft: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(toolDir, "osv-scanner"), []byte(osv), 0755); err != nil {
+		t.Fatalf("Failed to write fake osv-scanner: %v", err)
+	}
+	
+	scanner := NewScanner(t.TempDir())
+	scanner.SyftPath = filepath.Join(toolDir, "syft")
+	scanner.OSVScannerPath = filepath.Join(toolDir, "osv-scanner")
+	result, err := scanner.ScanProject()
+	if err != nil {
+		t.Fatalf("ScanProject failed: %v", err)
+	}
+	if len(result.Diagnostics) != 1 || result.Diagnostics[0].Kind != DiagnosticLookupFailure || !result.IsPartial() {
+		t.Errorf("Expected the unreadable SBOM to degrade the scan, got %+v", result.Diagnostics)
+	}
+}
+
+func TestParseNPMGraph(t *testing.T) {
+	lockContent := `{
+		"lockfileVersion": 3,
+		"packages": {
+			"": {"dependencies": {"express": "^4.18.0"}, "devDependencies": {"jest": "^29.0.0"}},
+			"node_modules/express": {"version": "4.18.2", "dependencies": {"debug": "2.6.9", "body-parser": "1.20.1"}},
+			"node_modules/body-parser": {"version": "1.20.1", "dependencies": {"debug": "2.6.9", "raw-body": "2.5.1"}},
+			"node_modules/raw-body": {"version": "2.5.1", "dependencies": {"body-parser": "1.20.1"}},
+			"node_modules/debug": {"version": "2.6.9"},
+			"node_modules/body-parser/node_modules/debug": {"version": "4.3.4"},
+			"node_modules/jest": {"version": "29.7.0", "dev": true, "dependencies": {"debug": "2.6.9"}}
+		}
+	}`
+	
+	graph, err := parseNPMGraph([]byte(lockContent), nil)
+	if err != nil {
+		t.Fatalf("parseNPMGraph failed: %v", err)
+	}
+	
+	tests := []struct {
+		name     string
+		version  string
+		expected DependencyInfo
+	}{
+		{"express", "4.18.2", DependencyInfo{IsDirect: true, Depth: 1, Type: DependencyProduction}},
+		{"jest", "", DependencyInfo{IsDirect: true, Depth: 1, Type: DependencyDevelopment}},
+		// debug@2.6.9 is hoisted; body-parser resolves its own copy instead
+		{"debug", "2.6.9", DependencyInfo{Depth: 2, Type: DependencyProduction, TransitiveDependents: 2}},
+		{"debug", "4.3.4", DependencyInfo{Depth: 3, Type: DependencyProduction, TransitiveDependents: 3, Cyclic: false}},
+		{"raw-body", "", DependencyInfo{Depth: 3, Type: DependencyProduction, TransitiveDependents: 2, Cyclic: true}},
+	}
+	
+	for _, tt := range tests {
+		info, found := graph.Info(tt.name, tt.version)
+		if !found {
+			t.Errorf("Expected %s@%s in the graph", tt.name, tt.version)
+			continue
+		}
+		if info != tt.expected {
+			t.Errorf("%s@%s: expected %+v, got %+v", tt.name, tt.version, tt.expected, info)
+		}
+	}
+}
+
+func TestLoadDependencyGraphGoMod(t *testing.T) {
+	tempDir := t.TempDir()
+	goMod := `module example.com/app
+
+go 1.21
+
+require (
+	github.com/gin-gonic/gin v1.9.0
+	github.com/goccy/go-json v0.10.2 // indirect
+)
+`
+	if err := os.WriteFile(filepath.Join(tempDir, "go.mod"), []byte(goMod), 0644); err != nil {
+		t.Fatalf("Failed to write go.mod: %v", err)
+	}
+	
+	graph := LoadDependencyGraph(tempDir)
+	if info, _ := graph.Info("github.com/gin-gonic/gin", "1.9.0"); !info.IsDirect || info.Depth != 1 {
+		t.Errorf("Expected gin to be direct, got %+v", info)
+	}
+	if info, _ := graph.Info("github.com/goccy/go-json", "v0.10.2"); info.IsDirect || info.Depth != 2 {
+		t.Errorf("Expected indirect requirement at depth 2, got %+v", info)
+	}
+}
+
+func TestDecodeOSVStream(t *testing.T) {
+	scanner := NewScanner(t.TempDir())
+
+	output := `{
+		"experimental_config": {"licenses": {"summary": false}},
+		"results": [
+			{"source": {"path": "/a/package-lock.json"}, "packages": null},
+			{
+				"packages": [
+					{"package": {"name": "lodash", "version": "4.17.20", "ecosystem": "npm"},
+					 "vulnerabilities": [{"id": "GHSA-1", "aliases": ["CVE-2021-23337"]}, {"id": "GHSA-2"}]},
+					{"package": {"name": "debug", "version": "2.6.8", "ecosystem": "npm"},
+					 "vulnerabilities": [{"id": "GHSA-3", "unknown": [1, 2, {"nested": true}]}]}
+				],
+				"source": {"path": "/b/package-lock.json"}
+			}
+		]
+	}`
+	vulnerabilities, err := scanner.decodeOSV(strings.NewReader(output))
+	if err != nil {
+		t.Fatalf("decodeOSV failed: %v", err)
+	}
+	var ids []string
+	for _, vuln := range vulnerabilities {
+		ids = append(ids, vuln.ID)
+	}
+	if strings.Join(ids, ",") != "GHSA-1,GHSA-2,GHSA-3" {
+		t.Errorf("Expected findings in report order, got %v", ids)
+	}
+	if aliases := vulnerabilities[0].Aliases; len(aliases) != 1 || aliases[0] != "CVE-2021-23337" {
+		t.Errorf("Expected advisory aliases, got %v", aliases)
+	}
+	if source := vulnerabilities[2].Source; source != "/b/package-lock.json" {
+		t.Errorf("Expected the source of the result to follow its packages, got %q", source)
+	}
+
+	if _, err := scanner.decodeOSV(strings.NewReader("  \n")); !errors.Is(err, errNoOSVOutput) {
+		t.Errorf("Expected empty output to be reported as such, got %v", err)
+	}
+
+	_, err = scanner.decodeOSV(strings.NewReader(`{"results": [{"packages": [{"package": 42}]}]}`))
+	if err == nil || !strings.Contains(err.Error(), "First 500 chars") {
+		t.Errorf("Expected a parse error with the start of the output, got %v", err)
+	}
+}
+
+// syntheticOSVReport builds an osv-scanner report with the given number of findings
+func syntheticOSVReport(findings int) []byte {
+	var report bytes.Buffer
+	report.WriteString(`{"results": [{"source": {"path": "/repo/package-lock.json", "type": "lockfile"}, "packages": [`)
+	for i := 0; i < findings; i++ {
+		if i > 0 {
+			report.WriteString(",")
+		}
+		fmt.Fprintf(&report, `{"package": {"name": "pkg-%d", "version": "1.0.%d", "ecosystem": "npm"}, "vulnerabilities": [{
+			"id": "GHSA-%05d", "summary": "Synthetic vulnerability", "details": "%s",
+			"severity": [{"type": "CVSS_V3", "score": "CVSS:3.1/AV:N/AC:L/PR:N/UI:N/S:U/C:H/I:H/A:H"}],
+			"references": [{"type": "ADVISORY", "url": "https://example.com/GHSA-%05d"}],
+			"published": "2023-01-01T00:00:00Z",
+			"affected": [{"package": {"name": "pkg-%d", "ecosystem": "npm"}, "ranges": [{"type": "SEMVER", "events": [{"introduced": "0"}, {"fixed": "1.1.0"}]}]}]
+		}]}`, i, i%100, i, strings.Repeat("details ", 20), i, i)
+	}
+	report.WriteString(`]}]}`)
+	return report.Bytes()
+}
+
+// BenchmarkDecodeOSV compares streaming decoding with unmarshalling the
+// whole report into one nested value, as osv-scanner output used to be
+// parsed. peak-MB is the heap held at the end of decoding, when the nested
+// value and the converted findings are both alive.
+func BenchmarkDecodeOSV(b *testing.B) {
+	report := syntheticOSVReport(50000)
+	scanner := NewScanner(b.TempDir())
+
+	stream := func() ([]Vulnerability, interface{}) {
+		vulnerabilities, err := scanner.decodeOSV(bytes.NewReader(report))
+		if err != nil {
+			b.Fatal(err)
+		}
+		return vulnerabilities, nil
+	}
+	unmarshal := func() ([]Vulnerability, interface{}) {
+		var whole struct {
+			Results []struct {
+				Packages []osvPackage `json:"packages"`
+			} `json:"results"`
+		}
+		if err := json.Unmarshal(report, &whole); err != nil {
+			b.Fatal(err)
+		}
+		var vulnerabilities []Vulnerability
+		for _, result := range whole.Results {
+			for _, pkg := range result.Packages {
+				vulnerabilities = append(vulnerabilities, scanner.packageVulnerabilities(pkg)...)
+			}
+		}
+		return vulnerabilities, &whole
+	}
+
+	for _, bench := range []struct {
+		name   string
+		decode func() ([]Vulnerability, interface{})
+	}{
+		{"stream", stream},
+		{"unmarshal", unmarshal},
+	} {
+		b.Run(bench.name, func(b *testing.B) {
+			b.SetBytes(int64(len(report)))
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				if vulnerabilities, _ := bench.decode(); len(vulnerabilities) != 50000 {
+					b.Fatalf("Expected 50000 findings, got %d", len(vulnerabilities))
+				}
+			}
+			b.StopTimer()
+
+			var before, after runtime.MemStats
+			runtime.GC()
+			runtime.ReadMemStats(&before)
+			vulnerabilities, intermediate := bench.decode()
+			runtime.GC()
+			runtime.ReadMemStats(&after)
+			runtime.KeepAlive(vulnerabilities)
+			runtime.KeepAlive(intermediate)
+			b.ReportMetric(float64(after.HeapAlloc-before.HeapAlloc)/(1<<20), "peak-MB")
+		})
+	}
+}
@@ -0,0 +1,60 @@
+package scanner
+
+import (
+	"strconv"
+	"strings"
+)
+
+// CompareVersions compares dotted versions segment by segment, numerically
+// where both segments are numbers. A pre-release (`-rc.1`) sorts before the
+// release; build metadata is ignored.
+func CompareVersions(a, b string) int {
+	a, b = strings.TrimPrefix(a, "v"), strings.TrimPrefix(b, "v")
+	a, _, _ = strings.Cut(a, "+")
+	b, _, _ = strings.Cut(b, "+")
+	aRelease, aPre, aHasPre := strings.Cut(a, "-")
+	bRelease, bPre, bHasPre := strings.Cut(b, "-")
+
+	if cmp := compareSegments(strings.Split(aRelease, "."), strings.Split(bRelease, ".")); cmp != 0 {
+		return cmp
+	}
+	switch {
+	case aHasPre && !bHasPre:
+		return -1
+	case !aHasPre && bHasPre:
+		return 1
+	case aHasPre && bHasPre:
+		return compareSegments(strings.Split(aPre, "."), strings.Split(bPre, "."))
+	}
+	return 0
+}
+
+// compareSegments compares version segments, treating missing ones as zero
+func compareSegments(a, b []string) int {
+	for i := 0; i < len(a) || i < len(b); i++ {
+		x, y := "0", "0"
+		if i < len(a) {
+			x = a[i]
+		}
+		if i < len(b) {
+			y = b[i]
+		}
+		xn, xErr := strconv.Atoi(x)
+		yn, yErr := strconv.Atoi(y)
+		switch {
+		case xErr == nil && yErr == nil:
+			if xn != yn {
+				if xn < yn {
+					return -1
+				}
+				return 1
+			}
+		case x != y:
+			if x < y {
+				return -1
+			}
+			return 1
+		}
+	}
+	return 0
+}
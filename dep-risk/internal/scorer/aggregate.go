@@ -0,0 +1,130 @@
+package scorer
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+)
+
+// Aggregation strategies combine finding scores into the project score
+const (
+	AggregateMax             = "max"
+	AggregateWeightedTopN    = "weighted_top_n"
+	AggregateProbabilisticOR = "probabilistic_or"
+	AggregateDensity         = "density"
+)
+
+// AggregationStrategies lists the supported aggregation strategies
+var AggregationStrategies = []string{AggregateMax, AggregateWeightedTopN, AggregateProbabilisticOR, AggregateDensity}
+
+// AggregationParams selects and configures the project score aggregation:
+//
+//	max:              the highest finding score
+//	weighted_top_n:   sum of the TopN highest scores, the i-th weighted Decay^i
+//	probabilistic_or: 10 * (1 - ∏(1 - score/10))
+//	density:          sum of SeverityWeights per dependency, times DensityScale
+//
+// All strategies are capped at 10.
+type AggregationParams struct {
+	Strategy        string             `json:"strategy" yaml:"strategy"`
+	TopN            int                `json:"top_n" yaml:"top_n"`
+	Decay           float64            `json:"decay" yaml:"decay"`
+	DensityScale    float64            `json:"density_scale" yaml:"density_scale"`
+	SeverityWeights map[string]float64 `json:"severity_weights" yaml:"severity_weights"`
+}
+
+// DefaultAggregationParams returns the max strategy with defaults for the others
+func DefaultAggregationParams() AggregationParams {
+	return AggregationParams{
+		Strategy:     AggregateMax,
+		TopN:         5,
+		Decay:        0.5,
+		DensityScale: 50,
+		SeverityWeights: map[string]float64{
+			"CRITICAL": 10,
+			"HIGH":     7,
+			"MEDIUM":   4,
+			"LOW":      1,
+		},
+	}
+}
+
+// Aggregation records which strategy produced the project score
+type Aggregation struct {
+	Strategy string `json:"strategy"`
+	Detail   string `json:"detail,omitempty"`
+}
+
+// String formats the aggregation for reports
+func (a Aggregation) String() string {
+	if a.Detail == "" {
+		return a.Strategy
+	}
+	return fmt.Sprintf("%s (%s)", a.Strategy, a.Detail)
+}
+
+// AggregateScores combines the scores of a subset of findings the way the
+// project score is combined
+func (s *Scorer) AggregateScores(scores []RiskScore, dependencyCount int) float64 {
+	score, _ := s.aggregate(scores, dependencyCount)
+	return score
+}
+
+// aggregate combines finding scores into the project score
+func (s *Scorer) aggregate(scores []RiskScore, dependencyCount int) (float64, Aggregation) {
+	params := s.Aggregation
+	if params.Strategy == "" {
+		params.Strategy = AggregateMax
+	}
+	aggregation := Aggregation{Strategy: params.Strategy}
+	if len(scores) == 0 {
+		return 0, aggregation
+	}
+
+	overall := make([]float64, len(scores))
+	for i, score := range scores {
+		overall[i] = score.Overall
+	}
+	sort.Sort(sort.Reverse(sort.Float64Slice(overall)))
+
+	var result float64
+	switch params.Strategy {
+	case AggregateWeightedTopN:
+		n := params.TopN
+		if n <= 0 || n > len(overall) {
+			n = len(overall)
+		}
+		for i := 0; i < n; i++ {
+			result += overall[i] * math.Pow(params.Decay, float64(i))
+		}
+		aggregation.Detail = fmt.Sprintf("top %d with decay %.2f", n, params.Decay)
+
+	case AggregateProbabilisticOR:
+		safe := 1.0
+		for _, score := range overall {
+			safe *= 1 - math.Max(0, math.Min(1, score/10))
+		}
+		result = 10 * (1 - safe)
+		aggregation.Detail = fmt.Sprintf("%d findings", len(overall))
+
+	case AggregateDensity:
+		if dependencyCount <= 0 {
+			// Without a dependency count the density is meaningless
+			aggregation = Aggregation{Strategy: AggregateMax, Detail: "density requested but the dependency count is unknown"}
+			result = overall[0]
+			break
+		}
+		var weighted float64
+		for _, score := range scores {
+			weighted += params.SeverityWeights[strings.ToUpper(score.Vulnerability.Severity)]
+		}
+		result = weighted / float64(dependencyCount) * params.DensityScale
+		aggregation.Detail = fmt.Sprintf("%d findings across %d dependencies", len(scores), dependencyCount)
+
+	default:
+		result = overall[0]
+	}
+
+	return math.Max(0, math.Min(10, result)), aggregation
+}
@@ -0,0 +1,83 @@
+package scorer
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Explanation is a node of the tree explaining how a score was computed.
+// Children are the inputs and steps that produced Value, in order.
+type Explanation struct {
+	Factor    string                 `json:"factor"`
+	Value     float64                `json:"value"`
+	Weight    *float64               `json:"weight,omitempty"`
+	Operation string                 `json:"operation,omitempty"`
+	Reason    string                 `json:"reason,omitempty"`
+	Inputs    map[string]interface{} `json:"inputs,omitempty"`
+	Children  []Explanation          `json:"children,omitempty"`
+}
+
+// Summary formats the node without its children, e.g.
+// "cvss: 7.5 × 0.50 (CVSS base score; cvss=7.5)"
+func (e Explanation) Summary() string {
+	var text strings.Builder
+	text.WriteString(e.Factor)
+	text.WriteString(": ")
+
+	switch e.Operation {
+	case AdjustAdd:
+		fmt.Fprintf(&text, "%+.2f", e.Value)
+	case AdjustMultiply:
+		fmt.Fprintf(&text, "×%.2f", e.Value)
+	case AdjustSet:
+		fmt.Fprintf(&text, "=%.2f", e.Value)
+	default:
+		fmt.Fprintf(&text, "%.2f", e.Value)
+	}
+	if e.Weight != nil {
+		fmt.Fprintf(&text, " × %.2f", *e.Weight)
+	}
+
+	var details []string
+	if e.Reason != "" {
+		details = append(details, e.Reason)
+	}
+	if len(e.Inputs) > 0 {
+		keys := make([]string, 0, len(e.Inputs))
+		for key := range e.Inputs {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		inputs := make([]string, len(keys))
+		for i, key := range keys {
+			inputs[i] = fmt.Sprintf("%s=%v", key, e.Inputs[key])
+		}
+		details = append(details, strings.Join(inputs, ", "))
+	}
+	if len(details) > 0 {
+		fmt.Fprintf(&text, " (%s)", strings.Join(details, "; "))
+	}
+
+	return text.String()
+}
+
+// explainAdjustments converts score adjustments into explanation nodes
+func explainAdjustments(adjustments []Adjustment) []Explanation {
+	var nodes []Explanation
+	for _, adjustment := range adjustments {
+		nodes = append(nodes, Explanation{
+			Factor:    adjustment.Factor,
+			Value:     adjustment.Value,
+			Operation: adjustment.Operation,
+			Reason:    adjustment.Reason,
+		})
+	}
+	return nodes
+}
+
+// weighted attaches a component weight to an explanation
+func weighted(node Explanation, weight float64) Explanation {
+	node.Weight = &weight
+	return node
+}
@@ -0,0 +1,145 @@
+package scorer
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/dep-risk/dep-risk/internal/expr"
+)
+
+// Gate actions
+const (
+	GateFail = "fail"
+	GateWarn = "warn"
+)
+
+// maxGateMatches bounds the finding IDs recorded for a gate
+const maxGateMatches = 20
+
+// GateSpec is a gate as written in the config: the run fails or warns when
+// more than MaxCount findings match the condition
+type GateSpec struct {
+	Name      string `json:"name" yaml:"name"`
+	Condition string `json:"condition" yaml:"condition"`
+	MaxCount  int    `json:"max_count,omitempty" yaml:"max_count"`
+	Action    string `json:"action,omitempty" yaml:"action"`
+}
+
+// Gate is a compiled gate
+type Gate struct {
+	Spec      GateSpec
+	condition *expr.Program
+}
+
+// GateResult is the outcome of a gate for a run
+type GateResult struct {
+	Name      string   `json:"name"`
+	Condition string   `json:"condition"`
+	Action    string   `json:"action"`
+	MaxCount  int      `json:"max_count"`
+	Count     int      `json:"count"`
+	Passed    bool     `json:"passed"`
+	Matches   []string `json:"matches,omitempty"`
+	// Error explains why a gate failed without being evaluated
+	Error string `json:"error,omitempty"`
+}
+
+// GateFields declares the fields gate conditions can reference: those of
+// rule conditions and the final score of the finding
+var GateFields = func() expr.Env {
+	env := expr.Env{"score": expr.TypeNumber}
+	for name, typ := range RuleFields {
+		env[name] = typ
+	}
+	return env
+}()
+
+// CompileGates parses and type-checks gates
+func CompileGates(specs []GateSpec) ([]Gate, error) {
+	gates := make([]Gate, 0, len(specs))
+	names := make(map[string]bool)
+	for i, spec := range specs {
+		if strings.TrimSpace(spec.Name) == "" {
+			return nil, fmt.Errorf("gate #%d: name is required", i+1)
+		}
+		if names[spec.Name] {
+			return nil, fmt.Errorf("gate %s: duplicate name", spec.Name)
+		}
+		names[spec.Name] = true
+
+		if spec.Action == "" {
+			spec.Action = GateFail
+		}
+		if spec.Action != GateFail && spec.Action != GateWarn {
+			return nil, fmt.Errorf("gate %s: action must be %s or %s", spec.Name, GateFail, GateWarn)
+		}
+		if spec.MaxCount < 0 {
+			return nil, fmt.Errorf("gate %s: max_count cannot be negative", spec.Name)
+		}
+
+		condition, err := expr.Compile(spec.Condition, GateFields)
+		if err != nil {
+			return nil, fmt.Errorf("gate %s: invalid condition %q: %w", spec.Name, spec.Condition, err)
+		}
+		gates = append(gates, Gate{Spec: spec, condition: condition})
+	}
+	return gates, nil
+}
+
+// References reports whether the condition of a gate reads a field
+func (g Gate) References(field string) bool {
+	return g.condition.References(field)
+}
+
+// EvaluateGates counts the scored findings matching each gate
+func (s *Scorer) EvaluateGates(gates []Gate, scores []RiskScore) []GateResult {
+	results := make([]GateResult, 0, len(gates))
+	for _, gate := range gates {
+		result := GateResult{
+			Name:      gate.Spec.Name,
+			Condition: gate.Spec.Condition,
+			Action:    gate.Spec.Action,
+			MaxCount:  gate.Spec.MaxCount,
+		}
+		for _, score := range scores {
+			values := s.ruleValues(score.Vulnerability, score.Dependency)
+			values["score"] = score.Overall
+			if !gate.condition.Eval(values) {
+				continue
+			}
+			result.Count++
+			if len(result.Matches) < maxGateMatches {
+				result.Matches = append(result.Matches, score.Vulnerability.ID)
+			}
+		}
+		result.Passed = result.Count <= result.MaxCount
+		results = append(results, result)
+	}
+	return results
+}
+
+// FailGatesReading fails the gates whose condition reads a field that is
+// unknown for the run, such as kev when the catalog could not be loaded, so
+// they cannot pass on missing data. Results are in the order of the gates.
+func FailGatesReading(gates []Gate, results []GateResult, field, reason string) int {
+	count := 0
+	for i, gate := range gates {
+		if i < len(results) && gate.References(field) {
+			results[i].Passed = false
+			results[i].Error = reason
+			count++
+		}
+	}
+	return count
+}
+
+// GatesFailed counts the failed gates with an action
+func GatesFailed(results []GateResult, action string) int {
+	count := 0
+	for _, result := range results {
+		if !result.Passed && result.Action == action {
+			count++
+		}
+	}
+	return count
+}
@@ -0,0 +1,127 @@
+package scorer
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"sort"
+
+	"github.com/dep-risk/dep-risk/internal/scanner"
+)
+
+// Model is a named, versioned set of scoring defaults. Reports record the
+// model version so scores from different models are not compared blindly.
+// Released models are frozen: changing a default means adding a model.
+type Model struct {
+	Version     string           `json:"version"`
+	Name        string           `json:"name"`
+	Description string           `json:"description"`
+	Weights     ScoringWeights   `json:"weights"`
+	Dependency  DependencyParams `json:"dependency"`
+}
+
+// LatestModelVersion is the model used unless a config selects another
+const LatestModelVersion = "v2"
+
+// scoringModels are the released scoring models
+var scoringModels = map[string]Model{
+	"v1": {
+		Version:     "v1",
+		Name:        "classic",
+		Description: "Fixed direct/transitive dependency scores, transitive dependencies rated higher",
+		Weights:     ScoringWeights{CVSS: 0.5, Popularity: 0.2, Dependency: 0.15, Context: 0.15, Maintenance: 0, Hygiene: 0.6},
+		Dependency:  DependencyParams{DirectScore: 2.0, TransitiveScore: 6.0},
+	},
+	"v2": {
+		Version:     "v2",
+		Name:        "graph-aware",
+		Description: "Dependency component from graph depth, type, dependents and cycles",
+		Weights:     ScoringWeights{CVSS: 0.5, Popularity: 0.2, Dependency: 0.15, Context: 0.15, Maintenance: 0, Hygiene: 0.6},
+		Dependency: DependencyParams{
+			DirectScore:        6.0,
+			TransitiveScore:    6.0,
+			DepthDecay:         1.5,
+			MinTransitiveScore: 1.0,
+			TypeModifiers: map[string]float64{
+				scanner.DependencyProduction:  1.0,
+				scanner.DependencyDevelopment: 0.3,
+				scanner.DependencyOptional:    0.5,
+				scanner.DependencyPeer:        0.8,
+			},
+			DependentsThreshold: 10,
+			DependentsBonus:     1.0,
+			CyclicBonus:         2.0,
+		},
+	},
+}
+
+// Models returns the released scoring models ordered by version
+func Models() []Model {
+	models := make([]Model, 0, len(scoringModels))
+	for _, model := range scoringModels {
+		models = append(models, model)
+	}
+	sort.Slice(models, func(i, j int) bool { return models[i].Version < models[j].Version })
+	return models
+}
+
+// LookupModel returns a released scoring model by version
+func LookupModel(version string) (Model, error) {
+	model, ok := scoringModels[version]
+	if !ok {
+		versions := make([]string, 0, len(scoringModels))
+		for _, model := range Models() {
+			versions = append(versions, model.Version)
+		}
+		return Model{}, fmt.Errorf("unknown scoring model %q (available: %v)", version, versions)
+	}
+
+	// Copy the maps so callers cannot change a released model
+	modifiers := make(map[string]float64, len(model.Dependency.TypeModifiers))
+	for depType, modifier := range model.Dependency.TypeModifiers {
+		modifiers[depType] = modifier
+	}
+	model.Dependency.TypeModifiers = modifiers
+	return model, nil
+}
+
+// NewScorerForModel creates a scorer with the defaults of a scoring model
+func NewScorerForModel(version string) (*Scorer, error) {
+	model, err := LookupModel(version)
+	if err != nil {
+		return nil, err
+	}
+
+	scorer := NewScorerWithWeights(model.Weights)
+	scorer.ModelVersion = model.Version
+	scorer.Dependency = model.Dependency
+	return scorer, nil
+}
+
+// ConfigHash returns a hash of the effective scoring settings. Two reports
+// with the same model version and hash were scored the same way, apart
+// from the popularity and maintenance data looked up during the run.
+func (s *Scorer) ConfigHash() string {
+	specs := make([]RuleSpec, len(s.Rules))
+	for i, rule := range s.Rules {
+		specs[i] = rule.Spec
+	}
+
+	data, err := json.Marshal(struct {
+		Model       string            `json:"model"`
+		Weights     ScoringWeights    `json:"weights"`
+		Dependency  DependencyParams  `json:"dependency"`
+		Context     *ContextInfo      `json:"context"`
+		Modifiers   ModifierParams    `json:"modifiers"`
+		Timing      TimingParams      `json:"timing"`
+		Rules       []RuleSpec        `json:"rules"`
+		Aggregation AggregationParams `json:"aggregation"`
+	}{s.ModelVersion, s.Weights, s.Dependency, s.Context, s.Modifiers, s.Timing, specs, s.Aggregation})
+	if err != nil {
+		return ""
+	}
+
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,168 @@
+package scorer
+
+import (
+	"fmt"
+	"math"
+	"strings"
+
+	"github.com/dep-risk/dep-risk/internal/expr"
+	"github.com/dep-risk/dep-risk/internal/scanner"
+)
+
+// RuleSpec is a custom scoring rule as written in the config. Exactly one of
+// Multiplier, Offset and Override is set.
+type RuleSpec struct {
+	Name       string   `json:"name" yaml:"name"`
+	Condition  string   `json:"condition" yaml:"condition"`
+	Multiplier *float64 `json:"multiplier,omitempty" yaml:"multiplier"`
+	Offset     *float64 `json:"offset,omitempty" yaml:"offset"`
+	Override   *float64 `json:"override,omitempty" yaml:"override"`
+}
+
+// Rule is a compiled custom scoring rule
+type Rule struct {
+	Spec      RuleSpec
+	condition *expr.Program
+}
+
+// RuleFields declares the finding fields rule conditions can reference
+var RuleFields = expr.Env{
+	"id":                        expr.TypeString,
+	"package":                   expr.TypeString,
+	"version":                   expr.TypeString,
+	"ecosystem":                 expr.TypeString,
+	"severity":                  expr.TypeString,
+	"class":                     expr.TypeString,
+	"cvss":                      expr.TypeNumber,
+	"is_direct":                 expr.TypeBool,
+	"depth":                     expr.TypeNumber,
+	"scope":                     expr.TypeString,
+	"dependents":                expr.TypeNumber,
+	"cyclic":                    expr.TypeBool,
+	"reachability":              expr.TypeString,
+	"fix_available":             expr.TypeBool,
+	"disclosure_days":           expr.TypeNumber,
+	"kev":                       expr.TypeBool,
+	"context.declared":          expr.TypeBool,
+	"context.execution":         expr.TypeList,
+	"context.network_exposed":   expr.TypeBool,
+	"context.privileged_access": expr.TypeBool,
+	"context.data_sensitivity":  expr.TypeString,
+	"context.compliance":        expr.TypeList,
+	"context.environment":       expr.TypeString,
+}
+
+// CompileRules parses and type-checks custom scoring rules
+func CompileRules(specs []RuleSpec) ([]Rule, error) {
+	rules := make([]Rule, 0, len(specs))
+	for i, spec := range specs {
+		name := spec.Name
+		if name == "" {
+			name = fmt.Sprintf("#%d", i+1)
+		}
+
+		actions := 0
+		for _, value := range []*float64{spec.Multiplier, spec.Offset, spec.Override} {
+			if value != nil {
+				actions++
+			}
+		}
+		if actions != 1 {
+			return nil, fmt.Errorf("rule %s: exactly one of multiplier, offset or override is required", name)
+		}
+		if spec.Multiplier != nil && *spec.Multiplier < 0 {
+			return nil, fmt.Errorf("rule %s: multiplier cannot be negative", name)
+		}
+		if spec.Override != nil && (*spec.Override < 0 || *spec.Override > 10) {
+			return nil, fmt.Errorf("rule %s: override must be between 0 and 10", name)
+		}
+
+		condition, err := expr.Compile(spec.Condition, RuleFields)
+		if err != nil {
+			return nil, fmt.Errorf("rule %s: invalid condition %q: %w", name, spec.Condition, err)
+		}
+
+		spec.Name = name
+		rules = append(rules, Rule{Spec: spec, condition: condition})
+	}
+	return rules, nil
+}
+
+// References reports whether the condition of a rule reads a field
+func (r Rule) References(field string) bool {
+	return r.condition.References(field)
+}
+
+// ruleValues exposes a finding and the declared context to rule conditions
+func (s *Scorer) ruleValues(vuln scanner.Vulnerability, dependency scanner.DependencyInfo) expr.Values {
+	reachability := vuln.Reachability
+	if reachability == "" {
+		reachability = scanner.ReachabilityUnknown
+	}
+
+	values := expr.Values{
+		"id":               vuln.ID,
+		"package":          vuln.Package,
+		"version":          vuln.Version,
+		"ecosystem":        vuln.Ecosystem,
+		"severity":         strings.ToUpper(vuln.Severity),
+		"class":            vuln.FindingClass(),
+		"cvss":             vuln.CVSS,
+		"is_direct":        dependency.IsDirect,
+		"depth":            float64(dependency.Depth),
+		"scope":            dependency.Type,
+		"dependents":       float64(dependency.TransitiveDependents),
+		"cyclic":           dependency.Cyclic,
+		"reachability":     reachability,
+		"fix_available":    vuln.FixAvailable(),
+		"kev":              vuln.KEV,
+		"context.declared": s.Context != nil,
+	}
+	if days, ok := vuln.DaysSinceDisclosure(s.scanTime()); ok {
+		values["disclosure_days"] = float64(days)
+	}
+
+	if s.Context != nil {
+		values["context.execution"] = s.Context.Execution
+		values["context.network_exposed"] = s.Context.NetworkExposed
+		values["context.privileged_access"] = s.Context.PrivilegedAccess
+		values["context.data_sensitivity"] = s.Context.DataSensitivity
+		values["context.compliance"] = s.Context.Compliance
+		values["context.environment"] = s.Context.Environment
+	}
+
+	return values
+}
+
+// applyRules applies matching custom rules in order. An override sets the
+// score and stops further rules.
+func (s *Scorer) applyRules(score float64, values expr.Values) (float64, []Adjustment) {
+	var applied []Adjustment
+	for _, rule := range s.Rules {
+		if !rule.condition.Eval(values) {
+			continue
+		}
+
+		adjustment := Adjustment{Factor: "rule", Reason: fmt.Sprintf("rule %q", rule.Spec.Name)}
+		switch {
+		case rule.Spec.Multiplier != nil:
+			adjustment.Operation = AdjustMultiply
+			adjustment.Value = *rule.Spec.Multiplier
+			score *= adjustment.Value
+		case rule.Spec.Offset != nil:
+			adjustment.Operation = AdjustAdd
+			adjustment.Value = *rule.Spec.Offset
+			score += adjustment.Value
+		case rule.Spec.Override != nil:
+			adjustment.Operation = AdjustSet
+			adjustment.Value = *rule.Spec.Override
+			score = adjustment.Value
+		}
+		applied = append(applied, adjustment)
+
+		if adjustment.Operation == AdjustSet {
+			break
+		}
+	}
+	return math.Max(0, math.Min(10, score)), applied
+}
@@ -1,8 +1,13 @@
 package scorer
 
 import (
+	"fmt"
 	"math"
+	"runtime"
+	"sort"
 	"strings"
+	"sync"
+	"time"
 
 	"github.com/dep-risk/dep-risk/internal/scanner"
 )
@@ -13,6 +18,11 @@ type ScoringWeights struct {
 	Popularity float64 `json:"popularity" yaml:"popularity"`
 	Dependency float64 `json:"dependency" yaml:"dependency"`
 	Context    float64 `json:"context" yaml:"context"`
+	Maintenance float64 `json:"maintenance" yaml:"maintenance"`
+
+	// Hygiene scales the score of hygiene findings (retracted or deprecated
+	// versions) relative to vulnerabilities; it is not part of the component sum
+	Hygiene float64 `json:"hygiene" yaml:"hygiene"`
 }
 
 // DefaultWeights returns the default scoring weights
@@ -22,6 +32,157 @@ func DefaultWeights() ScoringWeights {
 		Popularity: 0.2,
 		Dependency: 0.15,
 		Context:    0.15,
+		// Maintenance is opt-in so existing scores are unchanged
+		Maintenance: 0.0,
+		Hygiene:    0.6,
+	}
+}
+
+// DependencyParams configures the dependency component formula:
+//
+//	direct:     DirectScore
+//	transitive: max(MinTransitiveScore, TransitiveScore - depth * DepthDecay)
+//
+// multiplied by the modifier of the dependency type, plus DependentsBonus when
+// more than DependentsThreshold packages depend on it and CyclicBonus when it
+// is part of a dependency cycle
+type DependencyParams struct {
+	DirectScore         float64            `json:"direct_score" yaml:"direct_score"`
+	TransitiveScore     float64            `json:"transitive_score" yaml:"transitive_score"`
+	DepthDecay          float64            `json:"depth_decay" yaml:"depth_decay"`
+	MinTransitiveScore  float64            `json:"min_transitive_score" yaml:"min_transitive_score"`
+	TypeModifiers       map[string]float64 `json:"type_modifiers" yaml:"type_modifiers"`
+	DependentsThreshold int                `json:"dependents_threshold" yaml:"dependents_threshold"`
+	DependentsBonus     float64            `json:"dependents_bonus" yaml:"dependents_bonus"`
+	CyclicBonus         float64            `json:"cyclic_bonus" yaml:"cyclic_bonus"`
+}
+
+// DefaultDependencyParams returns the dependency formula of the scoring design
+func DefaultDependencyParams() DependencyParams {
+	return DependencyParams{
+		DirectScore:        6.0,
+		TransitiveScore:    6.0,
+		DepthDecay:         1.5,
+		MinTransitiveScore: 1.0,
+		TypeModifiers: map[string]float64{
+			scanner.DependencyProduction:  1.0,
+			scanner.DependencyDevelopment: 0.3,
+			scanner.DependencyOptional:    0.5,
+			scanner.DependencyPeer:        0.8,
+		},
+		DependentsThreshold: 10,
+		DependentsBonus:     1.0,
+		CyclicBonus:         2.0,
+	}
+}
+
+// ContextInfo declares how a project runs. When a project declares it, the
+// context component is computed from it instead of from package names.
+type ContextInfo struct {
+	Execution        []string `json:"execution,omitempty" yaml:"execution"`
+	NetworkExposed   bool     `json:"network_exposed" yaml:"network_exposed"`
+	PrivilegedAccess bool     `json:"privileged_access" yaml:"privileged_access"`
+	DataSensitivity  string   `json:"data_sensitivity,omitempty" yaml:"data_sensitivity"`
+	Compliance       []string `json:"compliance,omitempty" yaml:"compliance"`
+	Environment      string   `json:"environment,omitempty" yaml:"environment"`
+}
+
+// ExecutionModifiers are added to the context score per execution context
+var ExecutionModifiers = map[string]float64{
+	"server":  2.0,
+	"client":  1.0,
+	"cli":     0.5,
+	"library": 1.5,
+}
+
+// SensitivityModifiers are added to the context score per data sensitivity
+var SensitivityModifiers = map[string]float64{
+	"public":       0.0,
+	"internal":     0.5,
+	"confidential": 1.5,
+	"secret":       3.0,
+}
+
+// EnvironmentModifiers multiply the context score per environment
+var EnvironmentModifiers = map[string]float64{
+	"production":  1.0,
+	"staging":     0.7,
+	"development": 0.3,
+}
+
+// ModifierParams configures the multipliers applied to the overall score of
+// every finding. Industries and Ecosystems override the bundled tables.
+type ModifierParams struct {
+	Industry          string             `json:"industry,omitempty" yaml:"industry"`
+	EcosystemsEnabled bool               `json:"ecosystems_enabled" yaml:"ecosystems_enabled"`
+	Industries        map[string]float64 `json:"industries,omitempty" yaml:"industries"`
+	Ecosystems        map[string]float64 `json:"ecosystems,omitempty" yaml:"ecosystems"`
+}
+
+// IndustryModifiers are the bundled per-industry multipliers
+var IndustryModifiers = map[string]float64{
+	"finance":    1.5,
+	"healthcare": 1.3,
+	"government": 1.4,
+	"education":  0.9,
+	"gaming":     0.8,
+	"ecommerce":  1.2,
+	"media":      0.9,
+	"enterprise": 1.1,
+	"startup":    0.7,
+}
+
+// EcosystemModifiers are the bundled per-ecosystem multipliers, keyed by the
+// lowercased OSV ecosystem name
+var EcosystemModifiers = map[string]float64{
+	"npm":       1.2,
+	"pypi":      1.0,
+	"go":        0.9,
+	"crates.io": 0.8,
+	"maven":     1.1,
+	"packagist": 1.2,
+	"rubygems":  1.0,
+}
+
+// Modifier returns the multiplier of a key, preferring configured overrides
+// over the bundled table. Keys are matched case-insensitively.
+func Modifier(overrides, bundled map[string]float64, key string) (float64, bool) {
+	for name, modifier := range overrides {
+		if strings.EqualFold(name, key) {
+			return modifier, true
+		}
+	}
+	modifier, ok := bundled[strings.ToLower(key)]
+	return modifier, ok
+}
+
+// Adjustment operations
+const (
+	AdjustBase     = "base"
+	AdjustAdd      = "add"
+	AdjustMultiply = "multiply"
+	AdjustSet      = "set"
+)
+
+// Adjustment explains one step in the calculation of a score
+type Adjustment struct {
+	Factor    string  `json:"factor"`
+	Operation string  `json:"operation"`
+	Value     float64 `json:"value"`
+	Reason    string  `json:"reason"`
+}
+
+// String formats the adjustment for reports, e.g. "+2.0 runs as a server"
+func (a Adjustment) String() string {
+	switch a.Operation {
+	case AdjustAdd:
+		return fmt.Sprintf("%+.1f %s", a.Value, a.Reason)
+	case AdjustMultiply:
+		return fmt.Sprintf("×%.1f %s", a.Value, a.Reason)
+	case AdjustSet:
+		return fmt.Sprintf("=%.1f %s", a.Value, a.Reason)
+	default:
+		return fmt.Sprintf("%.1f %s", a.Value, a.Reason)
 	}
 }
 
@@ -29,7 +190,44 @@ func DefaultWeights() ScoringWeights {
 type PackagePopularity struct {
 	GitHubStars      int `json:"github_stars"`
 	DownloadsPerMonth int `json:"downloads_per_month"`
+	Dependents       int `json:"dependents"`
 	Age              int `json:"age_months"`
+	// Licenses and PublishedAt describe the looked-up version when the
+	// provider knows them
+	Licenses         []string   `json:"licenses,omitempty"`
+	PublishedAt      *time.Time `json:"published_at,omitempty"`
+}
+
+// PackageInfo describes the package version of a finding for policies: its
+// licenses and how long it has been published. Licenses is empty and the
+// other fields are left out when no source knows them.
+type PackageInfo struct {
+	Licenses    []string   `json:"licenses"`
+	PublishedAt *time.Time `json:"published_at,omitempty"`
+	AgeMonths   int        `json:"age_months,omitempty"`
+}
+
+// PopularityProvider looks up popularity metrics for a package. Unknown
+// packages return nil without an error.
+type PopularityProvider interface {
+	Popularity(ecosystem, name, version string) (*PackagePopularity, error)
+}
+
+// MaintenanceInfo describes how actively a package is maintained. Nil
+// scores mean no Scorecard result is available.
+type MaintenanceInfo struct {
+	Repository       string     `json:"repository,omitempty"`
+	ScorecardScore   *float64   `json:"scorecard_score,omitempty"`
+	MaintainedScore  *float64   `json:"maintained_score,omitempty"`
+	Archived         bool       `json:"archived"`
+	LastRelease      *time.Time `json:"last_release,omitempty"`
+	ReleasesLastYear int        `json:"releases_last_year"`
+}
+
+// MaintenanceProvider looks up maintenance health for a package. Unknown
+// packages return nil without an error.
+type MaintenanceProvider interface {
+	Maintenance(ecosystem, name string) (*MaintenanceInfo, error)
 }
 
 // RiskScore represents the calculated risk score for a vulnerability
@@ -39,6 +237,13 @@ type RiskScore struct {
 	PopularityComponent float64 `json:"popularity_component"`
 	DependencyComponent float64 `json:"dependency_component"`
 	ContextComponent    float64 `json:"context_component"`
+	MaintenanceComponent float64 `json:"maintenance_component"`
+	ContextAdjustments []Adjustment `json:"context_adjustments,omitempty"`
+	Modifiers        []Adjustment `json:"modifiers,omitempty"`
+	Rules            []Adjustment `json:"rules,omitempty"`
+	Dependency       scanner.DependencyInfo `json:"dependency"`
+	Package          PackageInfo `json:"package"`
+	Explanation      Explanation `json:"explanation"`
 	Vulnerability    scanner.Vulnerability `json:"vulnerability"`
 }
 
@@ -46,8 +251,99 @@ type RiskScore struct {
 type ProjectRiskScore struct {
 	OverallScore     float64     `json:"overall_score"`
 	MaxScore         float64     `json:"max_score"`
+	Aggregation      Aggregation `json:"aggregation"`
+	ModelVersion     string      `json:"model_version"`
+	ConfigHash       string      `json:"config_hash"`
+	// Weights are the component weights the findings were scored with
+	Weights          ScoringWeights `json:"weights"`
+	ScannedAt        time.Time   `json:"scanned_at"`
+	DependencyCount  int         `json:"dependency_count,omitempty"`
 	VulnerabilityScores []RiskScore `json:"vulnerability_scores"`
 	Summary          ScoreSummary `json:"summary"`
+	Diagnostics      []scanner.Diagnostic `json:"diagnostics,omitempty"`
+	ExpiredIgnores   []ExpiredIgnore `json:"expired_ignores,omitempty"`
+	// IgnoredFindings are the findings excluded by ignore rules, kept so the
+	// report can be rescored under other rules
+	IgnoredFindings  []scanner.Vulnerability `json:"ignored_findings,omitempty"`
+	// UnjustifiedIgnores are the configured ignores without a reason or expiry
+	UnjustifiedIgnores []string `json:"unjustified_ignores,omitempty"`
+	Policy           *PolicyResult `json:"policy,omitempty"`
+	Gates            []GateResult `json:"gates,omitempty"`
+	Decisions        []PolicyDecision `json:"decisions,omitempty"`
+}
+
+// ExpiredIgnore is a finding reported again because the ignore rule that
+// covered it has expired
+type ExpiredIgnore struct {
+	VulnerabilityID string `json:"vulnerability_id"`
+	Package         string `json:"package"`
+	Version         string `json:"version"`
+	Rule            string `json:"rule"`
+	Reason          string `json:"reason"`
+	Owner           string `json:"owner,omitempty"`
+	Expires         string `json:"expires"`
+}
+
+// Policy decision actions
+const (
+	DecisionDeny = "deny"
+	DecisionWarn = "warn"
+)
+
+// PolicyDecision is a deny or warn decision of a rule in a policy file
+type PolicyDecision struct {
+	Policy  string `json:"policy"`
+	Rule    string `json:"rule"`
+	Action  string `json:"action"`
+	Message string `json:"message"`
+}
+
+// CountDecisions counts the policy decisions with an action
+func CountDecisions(decisions []PolicyDecision, action string) int {
+	count := 0
+	for _, decision := range decisions {
+		if decision.Action == action {
+			count++
+		}
+	}
+	return count
+}
+
+// PolicyResult records the override blocks that set the thresholds of a run
+type PolicyResult struct {
+	Branch        string        `json:"branch,omitempty"`
+	Event         string        `json:"event,omitempty"`
+	Overrides     []string      `json:"overrides,omitempty"`
+	FailThreshold float64       `json:"fail_threshold"`
+	WarnThreshold float64       `json:"warn_threshold"`
+	Scopes        []PolicyScope `json:"scopes,omitempty"`
+}
+
+// PolicyScope is a set of findings judged on its own against the thresholds
+// of an override block with paths. The findings no such block covers form
+// the scope without an override.
+type PolicyScope struct {
+	Override      string   `json:"override,omitempty"`
+	Paths         []string `json:"paths,omitempty"`
+	Findings      int      `json:"findings"`
+	Score         float64  `json:"score"`
+	FailThreshold float64  `json:"fail_threshold"`
+	WarnThreshold float64  `json:"warn_threshold"`
+}
+
+// Failed reports whether any scope reaches its fail threshold
+func (p *PolicyResult) Failed() bool {
+	for _, scope := range p.Scopes {
+		if scope.Score >= scope.FailThreshold {
+			return true
+		}
+	}
+	return false
+}
+
+// IsPartialScan reports whether scanner diagnostics indicate an incomplete scan
+func (p *ProjectRiskScore) IsPartialScan() bool {
+	return scanner.PartialScan(p.Diagnostics)
 }
 
 // ScoreSummary provides a summary of risk scores
@@ -61,79 +357,252 @@ type ScoreSummary struct {
 
 // Scorer handles risk score calculations
 type Scorer struct {
-	Weights ScoringWeights
+	ModelVersion string
+	Weights      ScoringWeights
+	Dependency   DependencyParams
+	Context      *ContextInfo
+	Modifiers    ModifierParams
+	Timing       TimingParams
+	Rules        []Rule
+	Aggregation  AggregationParams
+	Popularity   PopularityProvider
+	Maintenance  MaintenanceProvider
+
+	// Workers bounds how many findings are scored concurrently; zero uses
+	// GOMAXPROCS. Providers must be safe for concurrent use.
+	Workers int
+
+	// ScanTime is when the scan ran. Timing factors and disclosure_days are
+	// measured against it, so a report rescores the same later on; zero uses
+	// the current time.
+	ScanTime time.Time
+
+	mu               sync.Mutex
+	popularityErrors []error
+}
+
+// scanTime returns the time timing factors are measured against
+func (s *Scorer) scanTime() time.Time {
+	if s.ScanTime.IsZero() {
+		return time.Now().UTC()
+	}
+	return s.ScanTime
 }
 
 // NewScorer creates a new scorer with default weights
 func NewScorer() *Scorer {
 	return &Scorer{
-		Weights: DefaultWeights(),
+		ModelVersion: LatestModelVersion,
+		Weights:      DefaultWeights(),
+		Dependency:   DefaultDependencyParams(),
+		Timing:       DefaultTimingParams(),
+		Aggregation:  DefaultAggregationParams(),
+		Popularity:   BuiltinPopularity{},
 	}
 }
 
 // NewScorerWithWeights creates a new scorer with custom weights
 func NewScorerWithWeights(weights ScoringWeights) *Scorer {
 	return &Scorer{
-		Weights: weights,
+		ModelVersion: LatestModelVersion,
+		Weights:      weights,
+		Dependency:   DefaultDependencyParams(),
+		Timing:       DefaultTimingParams(),
+		Aggregation:  DefaultAggregationParams(),
+		Popularity:   BuiltinPopularity{},
 	}
 }
 
 // CalculateProjectScore calculates the overall risk score for a project
 func (s *Scorer) CalculateProjectScore(scanResult *scanner.ScanResult) *ProjectRiskScore {
-	var vulnerabilityScores []RiskScore
-	var totalScore float64
-	var maxScore float64
+	s.mu.Lock()
+	s.popularityErrors = nil
+	s.mu.Unlock()
 
-	for _, vuln := range scanResult.Vulnerabilities {
-		score := s.CalculateVulnerabilityScore(vuln)
-		vulnerabilityScores = append(vulnerabilityScores, score)
-		
-		// Use weighted average for overall score
-		totalScore += score.Overall
+	vulnerabilityScores := s.scoreAll(scanResult.Vulnerabilities)
+	var maxScore float64
+	for _, score := range vulnerabilityScores {
 		if score.Overall > maxScore {
 			maxScore = score.Overall
 		}
 	}
 
-	var overallScore float64
-	if len(vulnerabilityScores) > 0 {
-		// Use the maximum score as the project risk score
-		// This ensures that even one high-risk vulnerability makes the project high-risk
-		overallScore = maxScore
-	}
+	overallScore, aggregation := s.aggregate(vulnerabilityScores, scanResult.DependencyCount)
 
 	summary := s.calculateSummary(vulnerabilityScores)
 
+	diagnostics := scanResult.Diagnostics
+	if errs := s.lookupErrors(); len(errs) > 0 {
+		// Failed lookups fall back to a neutral score, so the scan itself is complete
+		diagnostics = append(diagnostics, scanner.Diagnostic{
+			Tool:    "dep-risk",
+			Kind:    scanner.DiagnosticWarning,
+			Message: fmt.Sprintf("popularity lookup failed for %d findings: %v", len(errs), errs[0]),
+		})
+	}
+
 	return &ProjectRiskScore{
 		OverallScore:        overallScore,
 		MaxScore:           maxScore,
+		Aggregation:         aggregation,
+		ModelVersion:        s.ModelVersion,
+		ConfigHash:          s.ConfigHash(),
+		Weights:             s.Weights,
+		DependencyCount:     scanResult.DependencyCount,
 		VulnerabilityScores: vulnerabilityScores,
 		Summary:            summary,
+		Diagnostics:        diagnostics,
+		ScannedAt:          s.scanTime(),
 	}
 }
 
+// scoreAll scores findings with a bounded pool of workers. Each score is
+// stored at the index of its finding, so the order does not depend on
+// scheduling.
+func (s *Scorer) scoreAll(vulns []scanner.Vulnerability) []RiskScore {
+	if len(vulns) == 0 {
+		return nil
+	}
+	scores := make([]RiskScore, len(vulns))
+
+	workers := s.Workers
+	if workers <= 0 {
+		workers = runtime.GOMAXPROCS(0)
+	}
+	if workers > len(vulns) {
+		workers = len(vulns)
+	}
+	if workers == 1 {
+		for i, vuln := range vulns {
+			scores[i] = s.CalculateVulnerabilityScore(vuln)
+		}
+		return scores
+	}
+
+	indexes := make(chan int, workers)
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range indexes {
+				scores[i] = s.CalculateVulnerabilityScore(vulns[i])
+			}
+		}()
+	}
+	for i := range vulns {
+		indexes <- i
+	}
+	close(indexes)
+	wg.Wait()
+
+	return scores
+}
+
+// recordPopularityError records a failed popularity lookup
+func (s *Scorer) recordPopularityError(err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.popularityErrors = append(s.popularityErrors, err)
+}
+
+// lookupErrors returns the recorded popularity lookup failures ordered by
+// message, so reports do not depend on the order findings were scored in
+func (s *Scorer) lookupErrors() []error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	errs := append([]error(nil), s.popularityErrors...)
+	sort.Slice(errs, func(i, j int) bool { return errs[i].Error() < errs[j].Error() })
+	return errs
+}
+
 // CalculateVulnerabilityScore calculates the risk score for a single vulnerability
 func (s *Scorer) CalculateVulnerabilityScore(vuln scanner.Vulnerability) RiskScore {
 	// Calculate CVSS component (0-10 scale)
 	cvssComponent := s.calculateCVSSComponent(vuln.CVSS)
+	cvssExplanation := Explanation{Factor: "cvss", Value: cvssComponent, Reason: "CVSS base score", Inputs: map[string]interface{}{"cvss": vuln.CVSS}}
+	if vuln.FindingClass() != scanner.ClassVulnerability {
+		// Findings without an advisory have no CVSS; use their severity instead
+		cvssComponent = s.calculateSeverityComponent(vuln.Severity)
+		cvssExplanation = Explanation{
+			Factor: "cvss",
+			Value:  cvssComponent,
+			Reason: fmt.Sprintf("%s finding scored by severity", vuln.FindingClass()),
+			Inputs: map[string]interface{}{"severity": vuln.Severity},
+		}
+	} else if vuln.CVSS == 0 && vuln.Severity != "" {
+		// Some advisories rate severity without publishing a CVSS vector
+		cvssComponent = s.calculateSeverityComponent(vuln.Severity)
+		cvssExplanation = Explanation{
+			Factor: "cvss",
+			Value:  cvssComponent,
+			Reason: "advisory without CVSS scored by severity",
+			Inputs: map[string]interface{}{"severity": vuln.Severity},
+		}
+	}
 	
 	// Calculate popularity component (0-10 scale)
-	popularityComponent := s.calculatePopularityComponent(vuln.Package)
+	popularityComponent, popularityExplanation, popularity := s.calculatePopularityComponent(vuln)
 	
 	// Calculate dependency component (0-10 scale)
-	dependencyComponent := s.calculateDependencyComponent(vuln.IsDirect)
+	dependency := vuln.DependencyInfo()
+	dependencyComponent, dependencyExplanation := s.calculateDependencyComponent(dependency)
 	
 	// Calculate context component (0-10 scale)
-	contextComponent := s.calculateContextComponent(vuln)
+	contextComponent, contextAdjustments := s.calculateContextComponent(vuln)
+	
+	// Calculate maintenance component (0-10 scale)
+	maintenanceComponent, maintenanceExplanation := s.calculateMaintenanceComponent(vuln)
 	
 	// Calculate weighted overall score
 	overall := (cvssComponent * s.Weights.CVSS) +
 		(popularityComponent * s.Weights.Popularity) +
 		(dependencyComponent * s.Weights.Dependency) +
-		(contextComponent * s.Weights.Context)
+		(contextComponent * s.Weights.Context) +
+		(maintenanceComponent * s.Weights.Maintenance)
+	
+	explanation := Explanation{Factor: "risk_score", Reason: "weighted components, then multipliers and rules, clamped to 0-10"}
+	explanation.Children = append(explanation.Children, Explanation{
+		Factor: "weighted_sum",
+		Value:  overall,
+		Children: []Explanation{
+			weighted(cvssExplanation, s.Weights.CVSS),
+			weighted(popularityExplanation, s.Weights.Popularity),
+			weighted(dependencyExplanation, s.Weights.Dependency),
+			weighted(Explanation{Factor: "context", Value: contextComponent, Children: explainAdjustments(contextAdjustments)}, s.Weights.Context),
+			weighted(maintenanceExplanation, s.Weights.Maintenance),
+		},
+	})
+	
+	if vuln.FindingClass() == scanner.ClassHygiene {
+		overall *= s.Weights.Hygiene
+		explanation.Children = append(explanation.Children, Explanation{
+			Factor:    "hygiene_weight",
+			Value:     s.Weights.Hygiene,
+			Operation: AdjustMultiply,
+			Reason:    "hygiene findings are scaled relative to vulnerabilities",
+		})
+	}
+	
+	modifiers := append(s.applicableModifiers(vuln), s.timingModifiers(vuln, s.scanTime())...)
+	for _, modifier := range modifiers {
+		overall *= modifier.Value
+	}
+	explanation.Children = append(explanation.Children, explainAdjustments(modifiers)...)
 	
 	// Ensure score is within 0-10 range
-	overall = math.Max(0, math.Min(10, overall))
+	if clamped := math.Max(0, math.Min(10, overall)); clamped != overall {
+		overall = clamped
+		explanation.Children = append(explanation.Children, Explanation{Factor: "clamp", Value: overall, Operation: AdjustSet, Reason: "scores are limited to 0-10"})
+	}
+	
+	// Custom rules see the clamped score and clamp their result again
+	var rules []Adjustment
+	if len(s.Rules) > 0 {
+		overall, rules = s.applyRules(overall, s.ruleValues(vuln, dependency))
+		explanation.Children = append(explanation.Children, explainAdjustments(rules)...)
+	}
+	explanation.Value = overall
 	
 	return RiskScore{
 		Overall:             overall,
@@ -141,6 +610,13 @@ func (s *Scorer) CalculateVulnerabilityScore(vuln scanner.Vulnerability) RiskSco
 		PopularityComponent: popularityComponent,
 		DependencyComponent: dependencyComponent,
 		ContextComponent:    contextComponent,
+		MaintenanceComponent: maintenanceComponent,
+		ContextAdjustments:  contextAdjustments,
+		Modifiers:           modifiers,
+		Rules:               rules,
+		Dependency:          dependency,
+		Package:             s.packageInfo(vuln, popularity),
+		Explanation:         explanation,
 		Vulnerability:       vuln,
 	}
 }
@@ -151,86 +627,340 @@ func (s *Scorer) calculateCVSSComponent(cvss float64) float64 {
 	return cvss
 }
 
-// calculatePopularityComponent calculates the popularity-based component
-func (s *Scorer) calculatePopularityComponent(packageName string) float64 {
-	// For MVP, we'll use a simplified popularity calculation
-	// In a full implementation, this would query package registries
+// calculateSeverityComponent maps a severity label to a 0-10 score for findings without CVSS
+func (s *Scorer) calculateSeverityComponent(severity string) float64 {
+	switch strings.ToUpper(severity) {
+	case "CRITICAL":
+		return 9.5
+	case "HIGH":
+		return 8.0
+	case "MEDIUM":
+		return 5.5
+	case "LOW":
+		return 2.5
+	default:
+		return 0.0
+	}
+}
+
+// calculatePopularityComponent calculates the popularity-based component and
+// returns the metrics it was computed from, nil when the lookup found none
+func (s *Scorer) calculatePopularityComponent(vuln scanner.Vulnerability) (float64, Explanation, *PackagePopularity) {
+	provider := s.Popularity
+	if provider == nil {
+		provider = BuiltinPopularity{}
+	}
+	explanation := Explanation{Factor: "popularity", Value: 5.0}
 	
-	popularity := s.getPackagePopularity(packageName)
+	popularity, err := provider.Popularity(vuln.Ecosystem, vuln.Package, vuln.Version)
+	if err != nil {
+		s.recordPopularityError(err)
+		explanation.Reason = fmt.Sprintf("lookup failed: %v", err)
+		return 5.0, explanation, nil
+	}
+	if popularity == nil {
+		// Default to medium risk for unknown packages
+		explanation.Reason = "unknown package"
+		return 5.0, explanation, nil
+	}
+	explanation.Inputs = map[string]interface{}{
+		"downloads_per_month": popularity.DownloadsPerMonth,
+		"dependents":          popularity.Dependents,
+		"github_stars":        popularity.GitHubStars,
+	}
 	
 	// Calculate popularity factor: less popular packages are riskier
 	// Formula: max(0, 10 - log10(downloads_per_month / 1000))
 	if popularity.DownloadsPerMonth > 0 {
 		factor := math.Log10(float64(popularity.DownloadsPerMonth) / 1000.0)
-		return math.Max(0, math.Min(10, 10-factor))
+		explanation.Value = math.Max(0, math.Min(10, 10-factor))
+		explanation.Reason = "10 - log10(downloads_per_month / 1000)"
+		return explanation.Value, explanation, popularity
+	}
+	
+	// Registries without download counts are ranked by dependents, then stars
+	if reach := popularity.Dependents; reach > 0 || popularity.GitHubStars > 0 {
+		explanation.Reason = "10 - 1.5 * log10(dependents)"
+		if reach == 0 {
+			reach = popularity.GitHubStars
+			explanation.Reason = "10 - 1.5 * log10(github_stars)"
+		}
+		explanation.Value = math.Max(0, math.Min(10, 10-1.5*math.Log10(float64(reach))))
+		return explanation.Value, explanation, popularity
 	}
 	
-	// Default to medium risk for unknown packages
-	return 5.0
+	explanation.Reason = "no popularity metrics"
+	return 5.0, explanation, popularity
+}
+
+// packageInfo collects the licenses and publication of a finding's package
+// version: licenses from the scan, else from the popularity provider, and the
+// age in months from the provider or its publish date at the scan time
+func (s *Scorer) packageInfo(vuln scanner.Vulnerability, popularity *PackagePopularity) PackageInfo {
+	info := PackageInfo{Licenses: append([]string{}, vuln.Licenses...)}
+	if popularity == nil {
+		return info
+	}
+	if len(info.Licenses) == 0 {
+		info.Licenses = append(info.Licenses, popularity.Licenses...)
+	}
+	info.PublishedAt = popularity.PublishedAt
+	info.AgeMonths = popularity.Age
+	if info.AgeMonths == 0 && popularity.PublishedAt != nil {
+		if days := s.scanTime().Sub(*popularity.PublishedAt).Hours() / 24; days > 0 {
+			info.AgeMonths = int(days / 30)
+		}
+	}
+	return info
+}
+
+// calculateMaintenanceComponent calculates the maintenance-health component
+func (s *Scorer) calculateMaintenanceComponent(vuln scanner.Vulnerability) (float64, Explanation) {
+	explanation := Explanation{Factor: "maintenance", Value: 5.0, Reason: "no maintenance data"}
+	if s.Maintenance == nil {
+		return 5.0, explanation
+	}
+	
+	info, err := s.Maintenance.Maintenance(vuln.Ecosystem, vuln.Package)
+	if err != nil || info == nil {
+		// Default to medium risk for unknown packages
+		return 5.0, explanation
+	}
+	explanation.Inputs = map[string]interface{}{"archived": info.Archived}
+	if info.Repository != "" {
+		explanation.Inputs["repository"] = info.Repository
+	}
+	
+	// An archived repository will not ship a fix
+	if info.Archived {
+		explanation.Value = 10.0
+		explanation.Reason = "archived repository"
+		return 10.0, explanation
+	}
+	
+	// Average the available signals; Scorecard scores are 0-10 with 10 the healthiest
+	var signals []float64
+	signal := func(factor string, value float64, reason string) {
+		signals = append(signals, value)
+		explanation.Children = append(explanation.Children, Explanation{Factor: factor, Value: value, Reason: reason})
+	}
+	if info.ScorecardScore != nil {
+		signal("scorecard", 10-*info.ScorecardScore, fmt.Sprintf("10 - Scorecard score %.1f", *info.ScorecardScore))
+	}
+	if info.MaintainedScore != nil {
+		signal("maintained_check", 10-*info.MaintainedScore, fmt.Sprintf("10 - Maintained check %.1f", *info.MaintainedScore))
+	}
+	if info.LastRelease != nil {
+		signal("release_cadence", releaseCadenceRisk(*info.LastRelease, info.ReleasesLastYear, s.scanTime()),
+			fmt.Sprintf("last release %s, %d releases in the last year", info.LastRelease.Format("2006-01-02"), info.ReleasesLastYear))
+	}
+	if len(signals) == 0 {
+		return 5.0, explanation
+	}
+	
+	var total float64
+	for _, signal := range signals {
+		total += signal
+	}
+	explanation.Value = math.Max(0, math.Min(10, total/float64(len(signals))))
+	explanation.Reason = "average of the available signals"
+	return explanation.Value, explanation
+}
+
+// releaseCadenceRisk scores how stale a package's releases are at the scan
+// time, so rescoring a stored scan gives the same result
+func releaseCadenceRisk(lastRelease time.Time, releasesLastYear int, now time.Time) float64 {
+	years := now.Sub(lastRelease).Hours() / (24 * 365)
+	switch {
+	case years >= 3:
+		return 9.0
+	case years >= 2:
+		return 7.0
+	case years >= 1:
+		return 5.0
+	case releasesLastYear >= 4:
+		return 1.0
+	default:
+		return 3.0
+	}
 }
 
 // calculateDependencyComponent calculates the dependency depth component
-func (s *Scorer) calculateDependencyComponent(isDirect bool) float64 {
-	// Direct dependencies are easier to update, so lower risk
-	if isDirect {
-		return 2.0
+func (s *Scorer) calculateDependencyComponent(info scanner.DependencyInfo) (float64, Explanation) {
+	params := s.Dependency
+	explanation := Explanation{
+		Factor: "dependency",
+		Inputs: map[string]interface{}{
+			"is_direct":             info.IsDirect,
+			"depth":                 info.Depth,
+			"type":                  info.Type,
+			"transitive_dependents": info.TransitiveDependents,
+			"cyclic":                info.Cyclic,
+		},
 	}
-	// Transitive dependencies are harder to control, so higher risk
-	return 6.0
+	
+	score := params.DirectScore
+	step := Explanation{Factor: "base", Value: score, Operation: AdjustBase, Reason: "direct dependency"}
+	if !info.IsDirect {
+		// Deeper dependencies are reached through fewer code paths; an
+		// unknown depth is treated as the shallowest transitive level
+		depth := info.Depth
+		if depth < 2 {
+			depth = 2
+		}
+		score = math.Max(params.MinTransitiveScore, params.TransitiveScore-float64(depth)*params.DepthDecay)
+		step = Explanation{Factor: "base", Value: score, Operation: AdjustBase, Reason: fmt.Sprintf("transitive dependency at depth %d", depth)}
+	}
+	explanation.Children = append(explanation.Children, step)
+	
+	if modifier, exists := params.TypeModifiers[info.Type]; exists {
+		score *= modifier
+		explanation.Children = append(explanation.Children, Explanation{Factor: "type", Value: modifier, Operation: AdjustMultiply, Reason: info.Type + " dependency"})
+	}
+	
+	// Widely depended-on packages affect more of the tree
+	if info.TransitiveDependents > params.DependentsThreshold {
+		score += params.DependentsBonus
+		explanation.Children = append(explanation.Children, Explanation{
+			Factor:    "dependents",
+			Value:     params.DependentsBonus,
+			Operation: AdjustAdd,
+			Reason:    fmt.Sprintf("more than %d dependents", params.DependentsThreshold),
+		})
+	}
+	
+	if info.Cyclic {
+		score += params.CyclicBonus
+		explanation.Children = append(explanation.Children, Explanation{Factor: "cyclic", Value: params.CyclicBonus, Operation: AdjustAdd, Reason: "part of a dependency cycle"})
+	}
+	
+	explanation.Value = math.Max(0, math.Min(10, score))
+	return explanation.Value, explanation
 }
 
-// calculateContextComponent calculates the context-based component
-func (s *Scorer) calculateContextComponent(vuln scanner.Vulnerability) float64 {
+// calculateContextComponent calculates the context-based component from the
+// declared execution context, or from the package name when none is declared
+func (s *Scorer) calculateContextComponent(vuln scanner.Vulnerability) (float64, []Adjustment) {
+	if s.Context != nil {
+		return s.calculateDeclaredContext(*s.Context)
+	}
+	
 	score := 5.0 // Base score
+	adjustments := []Adjustment{{Factor: "base", Operation: AdjustBase, Value: score, Reason: "no execution context declared"}}
 	
 	// Increase risk for certain package types
 	packageName := strings.ToLower(vuln.Package)
+	nameRules := []struct {
+		factor   string
+		keywords []string
+		value    float64
+		reason   string
+	}{
+		// Network/HTTP libraries are higher risk
+		{"network_package", []string{"http", "net", "curl", "request"}, 2.0, "network library"},
+		// Crypto libraries are higher risk
+		{"crypto_package", []string{"crypto", "ssl", "tls"}, 1.5, "cryptography library"},
+		// Authentication/authorization libraries are higher risk
+		{"auth_package", []string{"auth", "jwt", "oauth"}, 1.5, "authentication library"},
+		// Database libraries are medium-high risk
+		{"database_package", []string{"sql", "db", "mongo", "redis"}, 1.0, "database library"},
+		// Development/testing tools are lower risk
+		{"development_package", []string{"test", "mock", "dev"}, -2.0, "development or testing tool"},
+	}
+	
+	for _, rule := range nameRules {
+		for _, keyword := range rule.keywords {
+			if strings.Contains(packageName, keyword) {
+				score += rule.value
+				adjustments = append(adjustments, Adjustment{
+					Factor:    rule.factor,
+					Operation: AdjustAdd,
+					Value:     rule.value,
+					Reason:    fmt.Sprintf("%s (package name contains %q)", rule.reason, keyword),
+				})
+				break
+			}
+		}
+	}
 	
-	// Network/HTTP libraries are higher risk
-	if strings.Contains(packageName, "http") || 
-	   strings.Contains(packageName, "net") ||
-	   strings.Contains(packageName, "curl") ||
-	   strings.Contains(packageName, "request") {
-		score += 2.0
+	return math.Max(0, math.Min(10, score)), adjustments
+}
+
+// applicableModifiers returns the industry and ecosystem multipliers for a finding
+func (s *Scorer) applicableModifiers(vuln scanner.Vulnerability) []Adjustment {
+	var modifiers []Adjustment
+	
+	if s.Modifiers.Industry != "" {
+		if modifier, ok := Modifier(s.Modifiers.Industries, IndustryModifiers, s.Modifiers.Industry); ok && modifier != 1 {
+			modifiers = append(modifiers, Adjustment{
+				Factor:    "industry",
+				Operation: AdjustMultiply,
+				Value:     modifier,
+				Reason:    fmt.Sprintf("%s industry", s.Modifiers.Industry),
+			})
+		}
 	}
 	
-	// Crypto libraries are higher risk
-	if strings.Contains(packageName, "crypto") ||
-	   strings.Contains(packageName, "ssl") ||
-	   strings.Contains(packageName, "tls") {
-		score += 1.5
+	if s.Modifiers.EcosystemsEnabled && vuln.Ecosystem != "" {
+		if modifier, ok := Modifier(s.Modifiers.Ecosystems, EcosystemModifiers, vuln.Ecosystem); ok && modifier != 1 {
+			modifiers = append(modifiers, Adjustment{
+				Factor:    "ecosystem",
+				Operation: AdjustMultiply,
+				Value:     modifier,
+				Reason:    fmt.Sprintf("%s ecosystem", vuln.Ecosystem),
+			})
+		}
 	}
 	
-	// Authentication/authorization libraries are higher risk
-	if strings.Contains(packageName, "auth") ||
-	   strings.Contains(packageName, "jwt") ||
-	   strings.Contains(packageName, "oauth") {
-		score += 1.5
+	return modifiers
+}
+
+// calculateDeclaredContext scores a declared execution context
+func (s *Scorer) calculateDeclaredContext(context ContextInfo) (float64, []Adjustment) {
+	score := 3.0
+	adjustments := []Adjustment{{Factor: "base", Operation: AdjustBase, Value: score, Reason: "declared context base score"}}
+	add := func(factor string, value float64, reason string) {
+		score += value
+		adjustments = append(adjustments, Adjustment{Factor: factor, Operation: AdjustAdd, Value: value, Reason: reason})
 	}
 	
-	// Database libraries are medium-high risk
-	if strings.Contains(packageName, "sql") ||
-	   strings.Contains(packageName, "db") ||
-	   strings.Contains(packageName, "mongo") ||
-	   strings.Contains(packageName, "redis") {
-		score += 1.0
+	for _, execution := range context.Execution {
+		if modifier, ok := ExecutionModifiers[execution]; ok {
+			add("execution", modifier, fmt.Sprintf("runs as a %s", execution))
+		}
+	}
+	if context.NetworkExposed {
+		add("network_exposed", 3.0, "exposed to the network")
+	}
+	if context.PrivilegedAccess {
+		add("privileged_access", 2.0, "runs with privileged access")
+	}
+	if modifier, ok := SensitivityModifiers[context.DataSensitivity]; ok && modifier != 0 {
+		add("data_sensitivity", modifier, fmt.Sprintf("handles %s data", context.DataSensitivity))
+	}
+	if len(context.Compliance) > 0 {
+		add("compliance", float64(len(context.Compliance))*0.5, fmt.Sprintf("subject to %s", strings.Join(context.Compliance, ", ")))
 	}
 	
-	// Development/testing tools are lower risk
-	if strings.Contains(packageName, "test") ||
-	   strings.Contains(packageName, "mock") ||
-	   strings.Contains(packageName, "dev") {
-		score -= 2.0
+	if modifier, ok := EnvironmentModifiers[context.Environment]; ok && modifier != 1 {
+		score *= modifier
+		adjustments = append(adjustments, Adjustment{
+			Factor:    "environment",
+			Operation: AdjustMultiply,
+			Value:     modifier,
+			Reason:    fmt.Sprintf("%s environment", context.Environment),
+		})
 	}
 	
-	return math.Max(0, math.Min(10, score))
+	return math.Max(0, math.Min(10, score)), adjustments
 }
 
-// getPackagePopularity retrieves popularity metrics for a package
-func (s *Scorer) getPackagePopularity(packageName string) PackagePopularity {
-	// For MVP, return mock data based on common packages
-	// In a full implementation, this would query package registries
-	
+// BuiltinPopularity is the default popularity provider: a small table of
+// well-known npm packages, with every other package treated as moderately used
+type BuiltinPopularity struct{}
+
+// Popularity retrieves popularity metrics for a package
+func (BuiltinPopularity) Popularity(ecosystem, name, version string) (*PackagePopularity, error) {
 	commonPackages := map[string]PackagePopularity{
 		"lodash":     {GitHubStars: 50000, DownloadsPerMonth: 50000000, Age: 120},
 		"express":    {GitHubStars: 60000, DownloadsPerMonth: 20000000, Age: 144},
@@ -241,16 +971,16 @@ func (s *Scorer) getPackagePopularity(packageName string) PackagePopularity {
 		"underscore": {GitHubStars: 27000, DownloadsPerMonth: 5000000, Age: 156},
 	}
 	
-	if popularity, exists := commonPackages[packageName]; exists {
-		return popularity
+	if popularity, exists := commonPackages[name]; exists {
+		return &popularity, nil
 	}
 	
 	// Default values for unknown packages
-	return PackagePopularity{
+	return &PackagePopularity{
 		GitHubStars:      1000,
 		DownloadsPerMonth: 10000,
 		Age:              24,
-	}
+	}, nil
 }
 
 // calculateSummary calculates summary statistics for vulnerability scores
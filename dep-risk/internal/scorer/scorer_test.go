@@ -1,7 +1,13 @@
 package scorer
 
 import (
+	"errors"
+	"fmt"
+	"math"
+	"reflect"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/dep-risk/dep-risk/internal/scanner"
 )
@@ -88,13 +94,32 @@ func TestCalculateProjectScore(t *testing.T) {
 func TestDependencyComponent(t *testing.T) {
 	scorer := NewScorer()
 	
-	// Direct dependency should have lower risk
-	directScore := scorer.calculateDependencyComponent(true)
-	transitiveScore := scorer.calculateDependencyComponent(false)
+	tests := []struct {
+		name     string
+		info     scanner.DependencyInfo
+		expected float64
+	}{
+		{"direct production", scanner.DependencyInfo{IsDirect: true, Depth: 1, Type: scanner.DependencyProduction}, 6.0},
+		{"depth 2", scanner.DependencyInfo{Depth: 2, Type: scanner.DependencyProduction}, 3.0},
+		{"depth 5 floors at minimum", scanner.DependencyInfo{Depth: 5}, 1.0},
+		{"unknown depth treated as 2", scanner.DependencyInfo{}, 3.0},
+		{"direct development", scanner.DependencyInfo{IsDirect: true, Depth: 1, Type: scanner.DependencyDevelopment}, 1.8},
+		{"widely depended on", scanner.DependencyInfo{Depth: 2, TransitiveDependents: 11}, 4.0},
+		{"cyclic", scanner.DependencyInfo{Depth: 2, Cyclic: true}, 5.0},
+	}
+	
+	for _, tt := range tests {
+		if component, _ := scorer.calculateDependencyComponent(tt.info); math.Abs(component-tt.expected) > 1e-9 {
+			t.Errorf("%s: expected %.2f, got %.2f", tt.name, tt.expected, component)
+		}
+	}
 	
-	if directScore >= transitiveScore {
-		t.Errorf("Direct dependency should have lower risk than transitive. Direct: %f, Transitive: %f", 
-			directScore, transitiveScore)
+	// The previous fixed formula can be expressed with parameters
+	scorer.Dependency = DependencyParams{DirectScore: 2.0, TransitiveScore: 6.0}
+	directScore, _ := scorer.calculateDependencyComponent(scanner.DependencyInfo{IsDirect: true, Depth: 1})
+	transitiveScore, _ := scorer.calculateDependencyComponent(scanner.DependencyInfo{Depth: 4})
+	if directScore != 2.0 || transitiveScore != 6.0 {
+		t.Errorf("Expected legacy parameters to give 2.0/6.0, got %.1f/%.1f", directScore, transitiveScore)
 	}
 }
 
@@ -106,9 +131,9 @@ func TestContextComponent(t *testing.T) {
 	cryptoVuln := scanner.Vulnerability{Package: "crypto-lib"}
 	testVuln := scanner.Vulnerability{Package: "test-utils"}
 	
-	httpScore := scorer.calculateContextComponent(httpVuln)
-	cryptoScore := scorer.calculateContextComponent(cryptoVuln)
-	testScore := scorer.calculateContextComponent(testVuln)
+	httpScore, _ := scorer.calculateContextComponent(httpVuln)
+	cryptoScore, _ := scorer.calculateContextComponent(cryptoVuln)
+	testScore, _ := scorer.calculateContextComponent(testVuln)
 	
 	// HTTP and crypto should be higher risk than test utilities
 	if httpScore <= testScore {
@@ -120,4 +145,648 @@ func TestContextComponent(t *testing.T) {
 		t.Errorf("Crypto package should have higher risk than test package. Crypto: %f, Test: %f", 
 			cryptoScore, testScore)
 	}
-}
\ No newline at end of file
+}
+
+func TestDeclaredContextComponent(t *testing.T) {
+	scorer := NewScorer()
+	vuln := scanner.Vulnerability{Package: "devise"}
+	
+	scorer.Context = &ContextInfo{
+		Execution:       []string{"server"},
+		NetworkExposed:  true,
+		DataSensitivity: "confidential",
+		Compliance:      []string{"PCI-DSS", "GDPR"},
+		Environment:     "staging",
+	}
+	score, adjustments := scorer.calculateContextComponent(vuln)
+	
+	// (3 + 2 server + 3 network + 1.5 confidential + 2 * 0.5 compliance) * 0.7 staging
+	if math.Abs(score-7.35) > 0.001 {
+		t.Errorf("Expected declared context score 7.35, got %f", score)
+	}
+	
+	factors := make([]string, len(adjustments))
+	for i, adjustment := range adjustments {
+		factors[i] = adjustment.Factor
+	}
+	expected := "base,execution,network_exposed,data_sensitivity,compliance,environment"
+	if strings.Join(factors, ",") != expected {
+		t.Errorf("Expected adjustments %s, got %s", expected, strings.Join(factors, ","))
+	}
+	if adjustments[len(adjustments)-1].String() != "×0.7 staging environment" {
+		t.Errorf("Unexpected environment explanation: %s", adjustments[len(adjustments)-1])
+	}
+	
+	// Declared contexts ignore the package name and clamp to 10
+	scorer.Context = &ContextInfo{
+		Execution:        []string{"server", "library"},
+		NetworkExposed:   true,
+		PrivilegedAccess: true,
+		DataSensitivity:  "secret",
+	}
+	if score, _ := scorer.calculateContextComponent(vuln); score != 10.0 {
+		t.Errorf("Expected clamped score 10.0, got %f", score)
+	}
+	
+	score = scorer.CalculateVulnerabilityScore(vuln).ContextComponent
+	if score != 10.0 {
+		t.Errorf("Expected declared context in the risk score, got %f", score)
+	}
+}
+
+func TestHygieneScoring(t *testing.T) {
+	scorer := NewScorer()
+	
+	finding := scanner.Vulnerability{
+		ID:       "RETRACTED-example.com/lib@v1.2.0",
+		Package:  "example.com/lib",
+		Version:  "v1.2.0",
+		Severity: "MEDIUM",
+		IsDirect: true,
+		Class:    scanner.ClassHygiene,
+	}
+	
+	score := scorer.CalculateVulnerabilityScore(finding)
+	if score.CVSSComponent != 5.5 {
+		t.Errorf("Expected severity-based component 5.5 for hygiene finding, got %f", score.CVSSComponent)
+	}
+	
+	scorer.Weights.Hygiene = 0
+	if zeroed := scorer.CalculateVulnerabilityScore(finding); zeroed.Overall != 0 {
+		t.Errorf("Expected zero hygiene weight to zero the score, got %f", zeroed.Overall)
+	}
+	
+	scorer.Weights.Hygiene = 1.0
+	full := scorer.CalculateVulnerabilityScore(finding)
+	if full.Overall <= score.Overall {
+		t.Errorf("Expected a higher hygiene weight to raise the score. Default: %f, Full: %f", score.Overall, full.Overall)
+	}
+}
+
+func TestAdvisoryWithoutCVSS(t *testing.T) {
+	scorer := NewScorer()
+	
+	advisory := scanner.Vulnerability{
+		ID:       "GHSA-xxxx-yyyy-zzzz",
+		Package:  "octo/deploy",
+		Version:  "v2.1.0",
+		Severity: "MEDIUM",
+		IsDirect: true,
+	}
+	
+	// Advisories rated by severity alone are not scored as CVSS 0
+	if score := scorer.CalculateVulnerabilityScore(advisory); score.CVSSComponent != 5.5 {
+		t.Errorf("Expected severity-based component 5.5, got %f", score.CVSSComponent)
+	}
+	
+	advisory.CVSS = 7.5
+	if score := scorer.CalculateVulnerabilityScore(advisory); score.CVSSComponent != 7.5 {
+		t.Errorf("Expected the CVSS score to be used when present, got %f", score.CVSSComponent)
+	}
+}
+
+// popularityFunc adapts a function to the PopularityProvider interface
+type popularityFunc func(ecosystem, name, version string) (*PackagePopularity, error)
+
+func (f popularityFunc) Popularity(ecosystem, name, version string) (*PackagePopularity, error) {
+	return f(ecosystem, name, version)
+}
+
+func TestPopularityProvider(t *testing.T) {
+	scorer := NewScorer()
+	scorer.Popularity = popularityFunc(func(ecosystem, name, version string) (*PackagePopularity, error) {
+		switch name {
+		case "widely-used":
+			return &PackagePopularity{Dependents: 100000}, nil
+		case "broken":
+			return nil, errors.New("registry unavailable")
+		}
+		return nil, nil
+	})
+	
+	if component, _, _ := scorer.calculatePopularityComponent(scanner.Vulnerability{Package: "widely-used"}); component != 2.5 {
+		t.Errorf("Expected dependents-based component 2.5, got %f", component)
+	}
+	if component, _, _ := scorer.calculatePopularityComponent(scanner.Vulnerability{Package: "unknown"}); component != 5.0 {
+		t.Errorf("Expected neutral component 5.0 for unknown package, got %f", component)
+	}
+	
+	// The package block takes licenses from the scan first and ages the
+	// version from its publish date at the scan time
+	published := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	scorer.ScanTime = time.Date(2026, 7, 1, 0, 0, 0, 0, time.UTC)
+	info := scorer.packageInfo(scanner.Vulnerability{Licenses: []string{"MIT"}}, &PackagePopularity{Licenses: []string{"GPL-3.0-only"}, PublishedAt: &published})
+	if !reflect.DeepEqual(info.Licenses, []string{"MIT"}) || info.AgeMonths != 6 || info.PublishedAt != &published {
+		t.Errorf("Unexpected package info %+v", info)
+	}
+	if info := scorer.packageInfo(scanner.Vulnerability{}, nil); info.Licenses == nil || len(info.Licenses) != 0 {
+		t.Errorf("Expected an empty license list for an unknown package, got %+v", info)
+	}
+	
+	result := &scanner.ScanResult{}
+	result.AddFindings([]scanner.Vulnerability{{ID: "CVE-2024-0001", Package: "broken", CVSS: 5.0, Severity: "MEDIUM"}})
+	projectScore := scorer.CalculateProjectScore(result)
+	
+	if len(projectScore.Diagnostics) != 1 || projectScore.IsPartialScan() {
+		t.Errorf("Expected one non-blocking diagnostic for the failed lookup, got %+v", projectScore.Diagnostics)
+	}
+}
+
+// maintenanceFunc adapts a function to the MaintenanceProvider interface
+type maintenanceFunc func(ecosystem, name string) (*MaintenanceInfo, error)
+
+func (f maintenanceFunc) Maintenance(ecosystem, name string) (*MaintenanceInfo, error) {
+	return f(ecosystem, name)
+}
+
+func TestMaintenanceComponent(t *testing.T) {
+	healthy, stale := 9.0, 2.0
+	scanTime := time.Date(2026, 6, 1, 0, 0, 0, 0, time.UTC)
+	recent := scanTime.AddDate(0, -2, 0)
+	abandoned := scanTime.AddDate(-4, 0, 0)
+	
+	scorer := NewScorer()
+	scorer.ScanTime = scanTime
+	scorer.Maintenance = maintenanceFunc(func(ecosystem, name string) (*MaintenanceInfo, error) {
+		switch name {
+		case "archived":
+			return &MaintenanceInfo{Archived: true}, nil
+		case "healthy":
+			return &MaintenanceInfo{ScorecardScore: &healthy, LastRelease: &recent, ReleasesLastYear: 12}, nil
+		case "abandoned":
+			return &MaintenanceInfo{MaintainedScore: &stale, LastRelease: &abandoned}, nil
+		}
+		return nil, nil
+	})
+	
+	tests := map[string]float64{
+		"archived":  10.0,
+		"healthy":   1.0,
+		"abandoned": 8.5,
+		"unknown":   5.0,
+	}
+	for name, expected := range tests {
+		if component, _ := scorer.calculateMaintenanceComponent(scanner.Vulnerability{Package: name}); component != expected {
+			t.Errorf("%s: expected maintenance component %.1f, got %.1f", name, expected, component)
+		}
+	}
+	
+	// Release cadence is measured at the scan time, not the wall clock, so a
+	// stored scan rescores the same
+	scorer.ScanTime = scanTime.AddDate(2, 0, 0)
+	if component, _ := scorer.calculateMaintenanceComponent(scanner.Vulnerability{Package: "healthy"}); component != 4.0 {
+		t.Errorf("Expected releases two years before the scan time to raise the component to 4.0, got %.1f", component)
+	}
+	
+	// The default weight keeps maintenance out of the overall score
+	vuln := scanner.Vulnerability{Package: "archived", CVSS: 5.0}
+	before := scorer.CalculateVulnerabilityScore(vuln).Overall
+	scorer.Weights.Maintenance = 0.2
+	if after := scorer.CalculateVulnerabilityScore(vuln).Overall; after <= before {
+		t.Errorf("Expected maintenance weight to raise the score. Before: %f, After: %f", before, after)
+	}
+}
+
+func TestModifiers(t *testing.T) {
+	scorer := NewScorer()
+	vuln := scanner.Vulnerability{Package: "lib", Ecosystem: "npm", CVSS: 5.0, Severity: "MEDIUM"}
+	base := scorer.CalculateVulnerabilityScore(vuln)
+	if len(base.Modifiers) != 0 {
+		t.Errorf("Expected no modifiers by default, got %v", base.Modifiers)
+	}
+	
+	scorer.Modifiers = ModifierParams{
+		Industry:          "Finance",
+		EcosystemsEnabled: true,
+		Ecosystems:        map[string]float64{"NPM": 1.1},
+	}
+	score := scorer.CalculateVulnerabilityScore(vuln)
+	
+	if len(score.Modifiers) != 2 || score.Modifiers[0].Factor != "industry" || score.Modifiers[1].Value != 1.1 {
+		t.Fatalf("Expected bundled industry and overridden ecosystem modifiers, got %v", score.Modifiers)
+	}
+	if math.Abs(score.Overall-base.Overall*1.5*1.1) > 0.001 {
+		t.Errorf("Expected %f, got %f", base.Overall*1.5*1.1, score.Overall)
+	}
+	
+	// Ecosystems without a modifier are left unchanged
+	vuln.Ecosystem = "GitHub Actions"
+	if score := scorer.CalculateVulnerabilityScore(vuln); len(score.Modifiers) != 1 {
+		t.Errorf("Expected only the industry modifier, got %v", score.Modifiers)
+	}
+}
+
+func TestCustomRules(t *testing.T) {
+	multiplier, offset, override := 2.0, -1.0, 9.5
+	rules, err := CompileRules([]RuleSpec{
+		{Name: "Critical infrastructure", Condition: "context.network_exposed && context.privileged_access", Multiplier: &multiplier},
+		{Name: "Internal packages", Condition: "package matches '@acme/*' && depth > 1", Offset: &offset},
+		{Name: "Reachable criticals", Condition: "severity == 'critical' && reachability == 'reachable'", Override: &override},
+	})
+	if err != nil {
+		t.Fatalf("CompileRules failed: %v", err)
+	}
+	
+	scorer := NewScorer()
+	vuln := scanner.Vulnerability{
+		Package:    "@acme/parser",
+		CVSS:       5.0,
+		Severity:   "MEDIUM",
+		Dependency: &scanner.DependencyInfo{Depth: 2, Type: scanner.DependencyProduction},
+	}
+	base := scorer.CalculateVulnerabilityScore(vuln)
+	
+	scorer.Rules = rules
+	score := scorer.CalculateVulnerabilityScore(vuln)
+	if len(score.Rules) != 1 || score.Rules[0].String() != `-1.0 rule "Internal packages"` {
+		t.Fatalf("Expected only the offset rule, got %v", score.Rules)
+	}
+	if math.Abs(score.Overall-(base.Overall-1)) > 0.001 {
+		t.Errorf("Expected %f, got %f", base.Overall-1, score.Overall)
+	}
+	
+	// Context rules see the declared context; an override ends rule processing
+	scorer.Context = &ContextInfo{NetworkExposed: true, PrivilegedAccess: true}
+	vuln.Severity = "CRITICAL"
+	vuln.Reachability = scanner.ReachabilityReachable
+	score = scorer.CalculateVulnerabilityScore(vuln)
+	if len(score.Rules) != 3 || score.Overall != 9.5 {
+		t.Errorf("Expected all rules ending in the override, got %.2f with %v", score.Overall, score.Rules)
+	}
+}
+
+func TestCompileRulesErrors(t *testing.T) {
+	value := 2.0
+	tests := []struct {
+		spec   RuleSpec
+		errMsg string
+	}{
+		{RuleSpec{Name: "no action", Condition: "is_direct"}, "exactly one of"},
+		{RuleSpec{Name: "two actions", Condition: "is_direct", Multiplier: &value, Offset: &value}, "exactly one of"},
+		{RuleSpec{Condition: "scope = 'development'", Multiplier: &value}, "rule #1: invalid condition"},
+		{RuleSpec{Name: "typo", Condition: "context.network_exposd", Multiplier: &value}, `unknown field "context.network_exposd"`},
+		{RuleSpec{Name: "types", Condition: "depth == 'two'", Multiplier: &value}, "cannot apply =="},
+	}
+	
+	for _, tt := range tests {
+		_, err := CompileRules([]RuleSpec{tt.spec})
+		if err == nil || !strings.Contains(err.Error(), tt.errMsg) {
+			t.Errorf("Expected error containing %q, got %v", tt.errMsg, err)
+		}
+	}
+}
+
+func TestGates(t *testing.T) {
+	gates, err := CompileGates([]GateSpec{
+		{Name: "fixable-critical-direct", Condition: "severity == 'CRITICAL' && is_direct && fix_available"},
+		{Name: "too-many-highs", Condition: "severity == 'HIGH'", MaxCount: 1},
+		{Name: "known-exploited", Condition: "kev", Action: GateWarn},
+		{Name: "high-scores", Condition: "score >= 9.5", MaxCount: 2},
+	})
+	if err != nil {
+		t.Fatalf("CompileGates failed: %v", err)
+	}
+	if gates[0].Spec.Action != GateFail || !gates[2].References("kev") || gates[1].References("kev") {
+		t.Fatalf("Unexpected compiled gates: %+v", gates)
+	}
+	
+	direct := &scanner.DependencyInfo{IsDirect: true, Depth: 1}
+	result := &scanner.ScanResult{Vulnerabilities: []scanner.Vulnerability{
+		{ID: "CVE-1", Package: "a", CVSS: 9.8, Severity: "CRITICAL", Dependency: direct, FixedVersions: []string{"2.0.0"}},
+		{ID: "CVE-2", Package: "b", CVSS: 9.1, Severity: "CRITICAL", Dependency: direct},
+		{ID: "CVE-3", Package: "c", CVSS: 7.5, Severity: "HIGH", KEV: true},
+		{ID: "CVE-4", Package: "d", CVSS: 7.2, Severity: "HIGH"},
+	}}
+	scorer := NewScorer()
+	projectScore := scorer.CalculateProjectScore(result)
+	results := scorer.EvaluateGates(gates, projectScore.VulnerabilityScores)
+	
+	expected := []struct {
+		count   int
+		passed  bool
+		matches []string
+	}{
+		{1, false, []string{"CVE-1"}},
+		{2, false, []string{"CVE-3", "CVE-4"}},
+		{1, false, []string{"CVE-3"}},
+		{0, true, nil},
+	}
+	for i, want := range expected {
+		got := results[i]
+		if got.Count != want.count || got.Passed != want.passed || !reflect.DeepEqual(got.Matches, want.matches) {
+			t.Errorf("Gate %s: expected count %d passed %v matches %v, got %+v", got.Name, want.count, want.passed, want.matches, got)
+		}
+	}
+	if failed := GatesFailed(results, GateFail); failed != 2 {
+		t.Errorf("Expected 2 failed fail gates, got %d", failed)
+	}
+	if failed := GatesFailed(results, GateWarn); failed != 1 {
+		t.Errorf("Expected 1 failed warn gate, got %d", failed)
+	}
+	
+	// Without the KEV catalog, gates reading kev fail instead of passing
+	result.Vulnerabilities[2].KEV = false
+	unknown := scorer.EvaluateGates(gates, scorer.CalculateProjectScore(result).VulnerabilityScores)
+	if count := FailGatesReading(gates, unknown, "kev", "KEV catalog unavailable"); count != 1 {
+		t.Errorf("Expected 1 gate to read kev, got %d", count)
+	}
+	if unknown[2].Passed || unknown[2].Error != "KEV catalog unavailable" || unknown[1].Error != "" {
+		t.Errorf("Expected only the kev gate to fail with an error, got %+v", unknown)
+	}
+}
+
+func TestCompileGatesErrors(t *testing.T) {
+	tests := []struct {
+		specs  []GateSpec
+		errMsg string
+	}{
+		{[]GateSpec{{Condition: "kev"}}, "gate #1: name is required"},
+		{[]GateSpec{{Name: "a", Condition: "kev"}, {Name: "a", Condition: "is_direct"}}, "gate a: duplicate name"},
+		{[]GateSpec{{Name: "a", Condition: "kev", Action: "block"}}, "action must be fail or warn"},
+		{[]GateSpec{{Name: "a", Condition: "kev", MaxCount: -1}}, "max_count cannot be negative"},
+		{[]GateSpec{{Name: "a", Condition: "score > 'high'"}}, "gate a: invalid condition"},
+		{[]GateSpec{{Name: "a", Condition: "exploited"}}, `unknown field "exploited"`},
+	}
+	
+	for _, tt := range tests {
+		_, err := CompileGates(tt.specs)
+		if err == nil || !strings.Contains(err.Error(), tt.errMsg) {
+			t.Errorf("Expected error containing %q, got %v", tt.errMsg, err)
+		}
+	}
+}
+
+func TestAggregationStrategies(t *testing.T) {
+	scores := []RiskScore{
+		{Overall: 8.0, Vulnerability: scanner.Vulnerability{Severity: "CRITICAL"}},
+		{Overall: 4.0, Vulnerability: scanner.Vulnerability{Severity: "MEDIUM"}},
+		{Overall: 6.0, Vulnerability: scanner.Vulnerability{Severity: "HIGH"}},
+	}
+	
+	tests := []struct {
+		strategy        string
+		dependencyCount int
+		expected        float64
+		aggregation     string
+	}{
+		{AggregateMax, 100, 8.0, "max"},
+		// 8 + 6*0.5 + 4*0.25, capped at 10
+		{AggregateWeightedTopN, 100, 10.0, "weighted_top_n (top 3 with decay 0.50)"},
+		// 10 * (1 - 0.2*0.4*0.6)
+		{AggregateProbabilisticOR, 100, 9.52, "probabilistic_or (3 findings)"},
+		// (10 + 7 + 4) / 300 * 50
+		{AggregateDensity, 300, 3.5, "density (3 findings across 300 dependencies)"},
+		{AggregateDensity, 0, 8.0, "max (density requested but the dependency count is unknown)"},
+	}
+	
+	for _, tt := range tests {
+		scorer := NewScorer()
+		scorer.Aggregation.Strategy = tt.strategy
+		result, aggregation := scorer.aggregate(scores, tt.dependencyCount)
+		if math.Abs(result-tt.expected) > 0.001 {
+			t.Errorf("%s: expected %.2f, got %.2f", tt.strategy, tt.expected, result)
+		}
+		if aggregation.String() != tt.aggregation {
+			t.Errorf("%s: expected aggregation %q, got %q", tt.strategy, tt.aggregation, aggregation)
+		}
+	}
+	
+	// Project scores record the strategy
+	scorer := NewScorer()
+	scorer.Aggregation.Strategy = AggregateProbabilisticOR
+	project := scorer.CalculateProjectScore(&scanner.ScanResult{Vulnerabilities: []scanner.Vulnerability{{Package: "lib", CVSS: 5.0}}})
+	if project.Aggregation.Strategy != AggregateProbabilisticOR || project.OverallScore != project.MaxScore {
+		t.Errorf("Expected a single finding to score its own score, got %+v", project)
+	}
+}
+
+func TestExplanation(t *testing.T) {
+	offset := -0.5
+	rules, err := CompileRules([]RuleSpec{{Name: "Transitive", Condition: "!is_direct", Offset: &offset}})
+	if err != nil {
+		t.Fatalf("CompileRules failed: %v", err)
+	}
+	
+	scorer := NewScorer()
+	scorer.Rules = rules
+	scorer.Modifiers = ModifierParams{Industry: "finance"}
+	score := scorer.CalculateVulnerabilityScore(scanner.Vulnerability{
+		Package:    "express",
+		CVSS:       7.5,
+		Dependency: &scanner.DependencyInfo{Depth: 3, Type: scanner.DependencyDevelopment, Cyclic: true},
+	})
+	
+	explanation := score.Explanation
+	if explanation.Value != score.Overall {
+		t.Errorf("Expected the root to carry the final score %.2f, got %.2f", score.Overall, explanation.Value)
+	}
+	
+	factors := make([]string, len(explanation.Children))
+	for i, child := range explanation.Children {
+		factors[i] = child.Factor
+	}
+	if strings.Join(factors, ",") != "weighted_sum,industry,rule" {
+		t.Fatalf("Unexpected explanation steps: %v", factors)
+	}
+	
+	// The weighted components add up to the weighted sum
+	sum := explanation.Children[0]
+	var total float64
+	for _, component := range sum.Children {
+		if component.Weight == nil {
+			t.Fatalf("Expected a weight on %s", component.Factor)
+		}
+		total += component.Value * *component.Weight
+	}
+	if math.Abs(total-sum.Value) > 1e-9 {
+		t.Errorf("Expected components to add up to %.4f, got %.4f", sum.Value, total)
+	}
+	
+	dependency := sum.Children[2]
+	if dependency.Summary() != "dependency: 2.45 × 0.15 (cyclic=true, depth=3, is_direct=false, transitive_dependents=0, type=development)" {
+		t.Errorf("Unexpected dependency summary: %s", dependency.Summary())
+	}
+	if len(dependency.Children) != 3 || dependency.Children[1].Factor != "type" || dependency.Children[2].Factor != "cyclic" {
+		t.Errorf("Unexpected dependency steps: %+v", dependency.Children)
+	}
+	
+	if popularity := sum.Children[1]; popularity.Inputs["downloads_per_month"] != 20000000 {
+		t.Errorf("Expected popularity inputs, got %+v", popularity.Inputs)
+	}
+}
+
+func TestScoringModels(t *testing.T) {
+	latest, err := LookupModel(LatestModelVersion)
+	if err != nil {
+		t.Fatalf("Latest model missing: %v", err)
+	}
+	if latest.Weights != DefaultWeights() {
+		t.Errorf("Latest model weights %+v differ from the defaults", latest.Weights)
+	}
+	defaults := DefaultDependencyParams()
+	if latest.Dependency.DirectScore != defaults.DirectScore || latest.Dependency.DepthDecay != defaults.DepthDecay {
+		t.Errorf("Latest model dependency params %+v differ from the defaults", latest.Dependency)
+	}
+	
+	if _, err := LookupModel("v0"); err == nil {
+		t.Error("Expected unknown model to be rejected")
+	}
+	if _, err := NewScorerForModel("v0"); err == nil {
+		t.Error("Expected scorer for unknown model to fail")
+	}
+	
+	legacy, err := NewScorerForModel("v1")
+	if err != nil {
+		t.Fatalf("Failed to create v1 scorer: %v", err)
+	}
+	if legacy.ModelVersion != "v1" {
+		t.Errorf("Expected model v1, got %s", legacy.ModelVersion)
+	}
+	direct, _ := legacy.calculateDependencyComponent(scanner.DependencyInfo{IsDirect: true, Depth: 1})
+	transitive, _ := legacy.calculateDependencyComponent(scanner.DependencyInfo{Depth: 4})
+	if direct != 2.0 || transitive != 6.0 {
+		t.Errorf("Expected v1 dependency scores 2/6, got %.2f/%.2f", direct, transitive)
+	}
+}
+
+func TestConfigHash(t *testing.T) {
+	scorer := NewScorer()
+	hash := scorer.ConfigHash()
+	if len(hash) != 64 {
+		t.Fatalf("Expected a sha256 hex hash, got %q", hash)
+	}
+	if NewScorer().ConfigHash() != hash {
+		t.Error("Expected identical settings to hash the same")
+	}
+	
+	scorer.Weights.CVSS = 0.6
+	if scorer.ConfigHash() == hash {
+		t.Error("Expected changed weights to change the hash")
+	}
+	
+	project := NewScorer().CalculateProjectScore(&scanner.ScanResult{})
+	if project.ModelVersion != LatestModelVersion || project.ConfigHash != hash {
+		t.Errorf("Expected project score stamped with %s/%s, got %s/%s", LatestModelVersion, hash, project.ModelVersion, project.ConfigHash)
+	}
+}
+
+func TestTimingFactors(t *testing.T) {
+	published := time.Now().AddDate(0, 0, -100)
+	vuln := scanner.Vulnerability{
+		ID:            "CVE-2023-1234",
+		Package:       "lib",
+		CVSS:          7.5,
+		Severity:      "HIGH",
+		Published:     &published,
+		FixedVersions: []string{"1.2.3"},
+	}
+	
+	scorer := NewScorer()
+	base := scorer.CalculateVulnerabilityScore(vuln)
+	if len(base.Modifiers) != 0 {
+		t.Errorf("Expected timing factors to be off by default, got %v", base.Modifiers)
+	}
+	
+	scorer.Timing.Enabled = true
+	scorer.Timing.SLADays = map[string]int{"high": 30}
+	score := scorer.CalculateVulnerabilityScore(vuln)
+	factors := make(map[string]float64)
+	for _, modifier := range score.Modifiers {
+		factors[modifier.Factor] = modifier.Value
+	}
+	if factors["fix_available"] != 1.2 || factors["sla"] != 1.5 {
+		t.Errorf("Expected fix and SLA factors, got %v", score.Modifiers)
+	}
+	if age := factors["age"]; math.Abs(age-(1+0.2*100.0/365)) > 0.001 {
+		t.Errorf("Expected age factor for 100 days, got %.4f", age)
+	}
+	
+	vuln.FixedVersions = nil
+	vuln.Published = nil
+	if score := scorer.CalculateVulnerabilityScore(vuln); len(score.Modifiers) != 0 {
+		t.Errorf("Expected no timing factors without a fix or dates, got %v", score.Modifiers)
+	}
+	
+	offset := 1.0
+	rules, err := CompileRules([]RuleSpec{{Name: "Unfixed", Condition: "!fix_available && disclosure_days > 60", Offset: &offset}})
+	if err != nil {
+		t.Fatalf("Failed to compile rule: %v", err)
+	}
+	scorer.Timing.Enabled = false
+	scorer.Rules = rules
+	vuln.Published = &published
+	if score := scorer.CalculateVulnerabilityScore(vuln); len(score.Rules) != 1 {
+		t.Errorf("Expected the timing rule to match, got %v", score.Rules)
+	}
+	
+	// Timing is measured at the scan time, not when the report is rescored
+	scorer.ScanTime = published.AddDate(0, 0, 30)
+	if score := scorer.CalculateVulnerabilityScore(vuln); len(score.Rules) != 0 {
+		t.Errorf("Expected the rule not to match 30 days after disclosure, got %v", score.Rules)
+	}
+}
+
+// syntheticScanResult builds a scan result with the given number of findings
+func syntheticScanResult(findings int) *scanner.ScanResult {
+	severities := []string{"CRITICAL", "HIGH", "MEDIUM", "LOW"}
+	result := &scanner.ScanResult{DependencyCount: findings * 2}
+	for i := 0; i < findings; i++ {
+		result.Vulnerabilities = append(result.Vulnerabilities, scanner.Vulnerability{
+			ID:         fmt.Sprintf("GHSA-%05d", i),
+			Package:    fmt.Sprintf("pkg-%d", i%5000),
+			Version:    "1.0.0",
+			Ecosystem:  "npm",
+			CVSS:       float64(i%100) / 10,
+			Severity:   severities[i%len(severities)],
+			Dependency: &scanner.DependencyInfo{Depth: 1 + i%6, IsDirect: i%6 == 0, Type: scanner.DependencyProduction, TransitiveDependents: i % 20},
+		})
+	}
+	result.TotalCount = len(result.Vulnerabilities)
+	return result
+}
+
+func TestConcurrentScoringIsDeterministic(t *testing.T) {
+	result := syntheticScanResult(2000)
+	failing := popularityFunc(func(ecosystem, name, version string) (*PackagePopularity, error) {
+		if strings.HasSuffix(name, "7") {
+			return nil, fmt.Errorf("lookup of %s failed", name)
+		}
+		return &PackagePopularity{DownloadsPerMonth: len(name) * 1000}, nil
+	})
+	
+	scanTime := time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC)
+	sequential := NewScorer()
+	sequential.Workers = 1
+	sequential.Popularity = failing
+	sequential.ScanTime = scanTime
+	want := sequential.CalculateProjectScore(result)
+	
+	concurrent := NewScorer()
+	concurrent.Workers = 8
+	concurrent.Popularity = failing
+	concurrent.ScanTime = scanTime
+	for run := 0; run < 3; run++ {
+		got := concurrent.CalculateProjectScore(result)
+		if !reflect.DeepEqual(got, want) {
+			t.Fatalf("Concurrent scoring differs from sequential scoring on run %d", run)
+		}
+	}
+	if len(want.Diagnostics) != 1 || !strings.Contains(want.Diagnostics[0].Message, "failed for 200 findings") {
+		t.Errorf("Expected one diagnostic for the failed lookups, got %+v", want.Diagnostics)
+	}
+}
+
+func BenchmarkCalculateProjectScore(b *testing.B) {
+	result := syntheticScanResult(50000)
+	for _, workers := range []int{1, 2, 4, 8} {
+		b.Run(fmt.Sprintf("workers=%d", workers), func(b *testing.B) {
+			scorer := NewScorer()
+			scorer.Workers = workers
+			b.ReportAllocs()
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				scorer.CalculateProjectScore(result)
+			}
+		})
+	}
+}
@@ -0,0 +1,89 @@
+package scorer
+
+import (
+	"fmt"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/dep-risk/dep-risk/internal/scanner"
+)
+
+// TimingParams configures the factors derived from advisory timing. When
+// enabled they multiply the overall score like the other modifiers:
+//
+//	fix_available: FixAvailable when a fixed version exists but is not installed
+//	age:           1 + AgeWeight * min(days since disclosure, AgeCapDays) / AgeCapDays
+//	sla:           SLABoost when the days since disclosure exceed SLADays[severity]
+type TimingParams struct {
+	Enabled      bool           `json:"enabled" yaml:"enabled"`
+	FixAvailable float64        `json:"fix_available" yaml:"fix_available"`
+	AgeWeight    float64        `json:"age_weight" yaml:"age_weight"`
+	AgeCapDays   int            `json:"age_cap_days" yaml:"age_cap_days"`
+	SLADays      map[string]int `json:"sla_days,omitempty" yaml:"sla_days"`
+	SLABoost     float64        `json:"sla_boost" yaml:"sla_boost"`
+}
+
+// DefaultTimingParams returns the timing factors, disabled
+func DefaultTimingParams() TimingParams {
+	return TimingParams{
+		FixAvailable: 1.2,
+		AgeWeight:    0.2,
+		AgeCapDays:   365,
+		SLABoost:     1.5,
+	}
+}
+
+// SLA returns the remediation window in days for a severity
+func (p TimingParams) SLA(severity string) (int, bool) {
+	for name, days := range p.SLADays {
+		if strings.EqualFold(name, severity) {
+			return days, true
+		}
+	}
+	return 0, false
+}
+
+// timingModifiers returns the fix availability, age and SLA multipliers for a finding
+func (s *Scorer) timingModifiers(vuln scanner.Vulnerability, now time.Time) []Adjustment {
+	params := s.Timing
+	if !params.Enabled || vuln.FindingClass() != scanner.ClassVulnerability {
+		return nil
+	}
+
+	var modifiers []Adjustment
+	if vuln.FixAvailable() && params.FixAvailable != 1 {
+		modifiers = append(modifiers, Adjustment{
+			Factor:    "fix_available",
+			Operation: AdjustMultiply,
+			Value:     params.FixAvailable,
+			Reason:    fmt.Sprintf("fixed in %s, not applied", strings.Join(vuln.FixedVersions, ", ")),
+		})
+	}
+
+	days, known := vuln.DaysSinceDisclosure(now)
+	if !known {
+		return modifiers
+	}
+
+	if params.AgeWeight != 0 && params.AgeCapDays > 0 && days > 0 {
+		age := math.Min(float64(days), float64(params.AgeCapDays)) / float64(params.AgeCapDays)
+		modifiers = append(modifiers, Adjustment{
+			Factor:    "age",
+			Operation: AdjustMultiply,
+			Value:     1 + params.AgeWeight*age,
+			Reason:    fmt.Sprintf("disclosed %d days ago", days),
+		})
+	}
+
+	if sla, ok := params.SLA(vuln.Severity); ok && days > sla {
+		modifiers = append(modifiers, Adjustment{
+			Factor:    "sla",
+			Operation: AdjustMultiply,
+			Value:     params.SLABoost,
+			Reason:    fmt.Sprintf("%s SLA of %d days exceeded by %d days", strings.ToUpper(vuln.Severity), sla, days-sla),
+		})
+	}
+
+	return modifiers
+}
@@ -0,0 +1,305 @@
+package workflow
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	"golang.org/x/mod/semver"
+
+	"github.com/dep-risk/dep-risk/internal/scanner"
+)
+
+// Advisory is a GitHub Security Advisory in the shape returned by the
+// global advisories REST API
+type Advisory struct {
+	GHSAID      string    `json:"ghsa_id"`
+	CVEID       string    `json:"cve_id"`
+	HTMLURL     string    `json:"html_url"`
+	Summary     string    `json:"summary"`
+	Description string    `json:"description"`
+	Severity    string    `json:"severity"`
+	CVSS        cvssScore `json:"cvss"`
+	// CVSSSeverities replaces cvss in current API versions
+	CVSSSeverities struct {
+		CVSSV3 cvssScore `json:"cvss_v3"`
+		CVSSV4 cvssScore `json:"cvss_v4"`
+	} `json:"cvss_severities"`
+	Vulnerabilities []struct {
+		Package struct {
+			Ecosystem string `json:"ecosystem"`
+			Name      string `json:"name"`
+		} `json:"package"`
+		VulnerableVersionRange string `json:"vulnerable_version_range"`
+		FirstPatchedVersion    string `json:"first_patched_version"`
+	} `json:"vulnerabilities"`
+}
+
+// cvssScore is a CVSS score of an advisory; the score is null when the
+// advisory has no vector
+type cvssScore struct {
+	Score float64 `json:"score"`
+}
+
+// Score returns the CVSS score of the advisory, preferring CVSS 3 to CVSS 4
+// and the legacy field
+func (a Advisory) Score() float64 {
+	for _, score := range []float64{a.CVSSSeverities.CVSSV3.Score, a.CVSSSeverities.CVSSV4.Score, a.CVSS.Score} {
+		if score > 0 {
+			return score
+		}
+	}
+	return 0
+}
+
+// advisorySeverity converts a GitHub severity to the labels used by the
+// scanner; GitHub calls medium severity moderate
+func advisorySeverity(severity string) string {
+	severity = strings.ToUpper(severity)
+	if severity == "MODERATE" {
+		return "MEDIUM"
+	}
+	return severity
+}
+
+// AdvisorySource provides GitHub Actions advisories for a set of actions
+type AdvisorySource interface {
+	Advisories(ctx context.Context, actions []string) ([]Advisory, error)
+}
+
+// FileSource reads advisories from a JSON file, such as the saved output of
+// GET /advisories?ecosystem=actions
+type FileSource struct {
+	Path string
+}
+
+// Advisories returns every advisory in the file
+func (s *FileSource) Advisories(ctx context.Context, actions []string) ([]Advisory, error) {
+	data, err := os.ReadFile(s.Path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read advisories: %w", err)
+	}
+
+	var advisories []Advisory
+	if err := json.Unmarshal(data, &advisories); err != nil {
+		return nil, fmt.Errorf("failed to parse advisories: %w", err)
+	}
+	return advisories, nil
+}
+
+// APISource queries the GitHub global security advisories API
+type APISource struct {
+	BaseURL    string
+	Token      string
+	httpClient *http.Client
+}
+
+// NewAPISource creates an advisory source for a GitHub API endpoint
+func NewAPISource(baseURL, token string) *APISource {
+	if baseURL == "" {
+		baseURL = "https://api.github.com"
+	}
+	return &APISource{
+		BaseURL:    strings.TrimSuffix(baseURL, "/"),
+		Token:      token,
+		httpClient: &http.Client{Timeout: 30 * time.Second},
+	}
+}
+
+// Advisories returns the reviewed advisories affecting the given actions
+func (s *APISource) Advisories(ctx context.Context, actions []string) ([]Advisory, error) {
+	var advisories []Advisory
+
+	// Keep the affects list well below URL length limits
+	const batchSize = 50
+	for start := 0; start < len(actions); start += batchSize {
+		end := start + batchSize
+		if end > len(actions) {
+			end = len(actions)
+		}
+
+		query := url.Values{}
+		query.Set("ecosystem", "actions")
+		query.Set("affects", strings.Join(actions[start:end], ","))
+		query.Set("per_page", "100")
+
+		// Results are paginated with cursors in the Link header
+		next := s.BaseURL + "/advisories?" + query.Encode()
+		for next != "" {
+			batch, nextURL, err := s.fetch(ctx, next)
+			if err != nil {
+				return nil, err
+			}
+			advisories = append(advisories, batch...)
+			next = nextURL
+		}
+	}
+
+	return advisories, nil
+}
+
+// fetch performs a single advisories API request and returns the URL of
+// the next page, if any
+func (s *APISource) fetch(ctx context.Context, rawURL string) ([]Advisory, string, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, rawURL, nil)
+	if err != nil {
+		return nil, "", err
+	}
+	req.Header.Set("Accept", "application/vnd.github+json")
+	if s.Token != "" {
+		req.Header.Set("Authorization", "Bearer "+s.Token)
+	}
+
+	resp, err := s.httpClient.Do(req)
+	if err != nil {
+		return nil, "", err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return nil, "", fmt.Errorf("advisories API returned status %d: %s", resp.StatusCode, strings.TrimSpace(string(body)))
+	}
+
+	var advisories []Advisory
+	if err := json.NewDecoder(resp.Body).Decode(&advisories); err != nil {
+		return nil, "", fmt.Errorf("failed to decode advisories: %w", err)
+	}
+	return advisories, nextPage(resp.Header.Get("Link")), nil
+}
+
+// nextPage returns the rel="next" URL of a Link header
+func nextPage(link string) string {
+	for _, part := range strings.Split(link, ",") {
+		target, params, ok := strings.Cut(strings.TrimSpace(part), ";")
+		if !ok || !strings.Contains(params, `rel="next"`) {
+			continue
+		}
+		return strings.Trim(strings.TrimSpace(target), "<>")
+	}
+	return ""
+}
+
+// matchAdvisories reports the references whose version is in a vulnerable
+// range. References to actions with advisories whose version cannot be
+// compared, such as floating tags, are reported as diagnostics.
+func matchAdvisories(refs []Reference, advisories []Advisory) ([]scanner.Vulnerability, []scanner.Diagnostic) {
+	var findings []scanner.Vulnerability
+	var diagnostics []scanner.Diagnostic
+	for _, ref := range refs {
+		// Floating tags such as v4 resolve to a release we cannot see offline
+		version, ok := fullVersion(ref.Version)
+		if !ok {
+			if ids := advisoryIDs(ref, advisories); len(ids) > 0 {
+				diagnostics = append(diagnostics, scanner.Diagnostic{
+					Tool: "dep-risk",
+					Kind: scanner.DiagnosticWarning,
+					Message: fmt.Sprintf("%s@%s cannot be checked against %s: pin a full version, or a commit SHA with the version in a trailing comment",
+						ref.Action(), ref.Ref, strings.Join(ids, ", ")),
+					Path: ref.File,
+				})
+			}
+			continue
+		}
+
+		for _, advisory := range advisories {
+			for _, affected := range advisory.Vulnerabilities {
+				if !strings.EqualFold(affected.Package.Ecosystem, "actions") ||
+					!strings.EqualFold(affected.Package.Name, ref.Action()) ||
+					!inRange(version, affected.VulnerableVersionRange) {
+					continue
+				}
+
+				description := advisory.Description
+				if affected.FirstPatchedVersion != "" {
+					description = fmt.Sprintf("Fixed in %s. %s", affected.FirstPatchedVersion, description)
+				}
+
+				findings = append(findings, scanner.Vulnerability{
+					ID:          advisory.GHSAID,
+					Package:     ref.Action(),
+					Version:     ref.Version,
+					CVSS:        advisory.Score(),
+					Severity:    advisorySeverity(advisory.Severity),
+					Summary:     advisory.Summary,
+					Description: description,
+					References:  []string{advisory.HTMLURL},
+					IsDirect:    true,
+					Ecosystem:   Ecosystem,
+					Class:       scanner.ClassVulnerability,
+					File:        ref.File,
+					Line:        ref.Line,
+				})
+				break
+			}
+		}
+	}
+	return findings, diagnostics
+}
+
+// advisoryIDs lists the advisories published for the action of a reference
+func advisoryIDs(ref Reference, advisories []Advisory) []string {
+	var ids []string
+	for _, advisory := range advisories {
+		for _, affected := range advisory.Vulnerabilities {
+			if strings.EqualFold(affected.Package.Ecosystem, "actions") && strings.EqualFold(affected.Package.Name, ref.Action()) {
+				ids = append(ids, advisory.GHSAID)
+				break
+			}
+		}
+	}
+	return ids
+}
+
+// fullVersion canonicalizes a major.minor.patch version
+func fullVersion(version string) (string, bool) {
+	version = "v" + strings.TrimPrefix(version, "v")
+	if strings.Count(version, ".") != 2 || !semver.IsValid(version) {
+		return "", false
+	}
+	return semver.Canonical(version), true
+}
+
+// inRange checks a version against a GHSA range such as ">= 1.0.0, < 1.2.3"
+func inRange(version, versionRange string) bool {
+	if strings.TrimSpace(versionRange) == "" {
+		return false
+	}
+
+	for _, constraint := range strings.Split(versionRange, ",") {
+		fields := strings.Fields(constraint)
+		if len(fields) != 2 {
+			return false
+		}
+
+		bound := "v" + strings.TrimPrefix(fields[1], "v")
+		if !semver.IsValid(bound) {
+			return false
+		}
+		cmp := semver.Compare(version, bound)
+
+		var ok bool
+		switch fields[0] {
+		case "<":
+			ok = cmp < 0
+		case "<=":
+			ok = cmp <= 0
+		case ">":
+			ok = cmp > 0
+		case ">=":
+			ok = cmp >= 0
+		case "=":
+			ok = cmp == 0
+		}
+		if !ok {
+			return false
+		}
+	}
+	return true
+}
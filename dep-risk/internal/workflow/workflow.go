@@ -0,0 +1,288 @@
+package workflow
+
+import (
+	"context"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/dep-risk/dep-risk/internal/scanner"
+)
+
+// Ecosystem is the advisory ecosystem name of GitHub Actions
+const Ecosystem = "GitHub Actions"
+
+var (
+	shaPattern     = regexp.MustCompile(`^[0-9a-f]{40}$`)
+	versionComment = regexp.MustCompile(`v?\d+(?:\.\d+){0,2}`)
+)
+
+// Reference is a third-party action or reusable workflow referenced with uses:
+type Reference struct {
+	Owner string
+	Repo  string
+	Path  string
+	Ref   string
+	// Version is the release the ref points to: the tag itself, or the
+	// version in a trailing comment such as "# v4.1.1" for SHA pins
+	Version string
+	File    string
+	Line    int
+}
+
+// Action returns the owner/repo name advisories are published under
+func (r Reference) Action() string {
+	return r.Owner + "/" + r.Repo
+}
+
+// IsSHAPinned reports whether the reference is pinned to a full commit SHA
+func (r Reference) IsSHAPinned() bool {
+	return shaPattern.MatchString(r.Ref)
+}
+
+// Options configures the workflow check
+type Options struct {
+	// RequireSHA reports references that are not pinned to a full commit SHA
+	RequireSHA bool
+
+	// AllowedOwners lists the owners (or owner/repo names) that may be used;
+	// empty allows every owner
+	AllowedOwners []string
+
+	// ExcludePaths lists directory names that are not searched for action files
+	ExcludePaths []string
+}
+
+// Checker scans workflow and composite action files for risky action references
+type Checker struct {
+	WorkingDir string
+	Source     AdvisorySource
+	Options    Options
+}
+
+// NewChecker creates a new workflow checker. A nil source skips advisory lookups.
+func NewChecker(workingDir string, source AdvisorySource, options Options) *Checker {
+	return &Checker{
+		WorkingDir: workingDir,
+		Source:     source,
+		Options:    options,
+	}
+}
+
+// Check reports vulnerable action versions and pinning policy violations
+func (c *Checker) Check(ctx context.Context) ([]scanner.Vulnerability, []scanner.Diagnostic) {
+	refs, diagnostics := FindReferences(c.WorkingDir, c.Options.ExcludePaths)
+	if len(refs) == 0 {
+		return nil, diagnostics
+	}
+
+	findings := c.checkPolicy(refs)
+
+	if c.Source != nil {
+		advisories, err := c.Source.Advisories(ctx, actionNames(refs))
+		if err != nil {
+			diagnostics = append(diagnostics, scanner.Diagnostic{
+				Tool:    "dep-risk",
+				Kind:    scanner.DiagnosticNetworkFailure,
+				Message: fmt.Sprintf("GitHub Actions advisory lookup failed: %v", err),
+			})
+		} else {
+			matched, unchecked := matchAdvisories(refs, advisories)
+			findings = append(findings, matched...)
+			diagnostics = append(diagnostics, unchecked...)
+		}
+	}
+
+	return findings, diagnostics
+}
+
+// checkPolicy reports references that break the pinning policy
+func (c *Checker) checkPolicy(refs []Reference) []scanner.Vulnerability {
+	var findings []scanner.Vulnerability
+	for _, ref := range refs {
+		if !c.ownerAllowed(ref) {
+			findings = append(findings, policyFinding(ref, "UNTRUSTED-ACTION", "HIGH",
+				fmt.Sprintf("%s is not published by an allowed owner", ref.Action()),
+				"The pinning policy only allows actions from the owners listed in workflow_allowed_owners. Third-party actions run with the workflow's token and secrets."))
+		}
+		if c.Options.RequireSHA && !ref.IsSHAPinned() {
+			findings = append(findings, policyFinding(ref, "UNPINNED-ACTION", "MEDIUM",
+				fmt.Sprintf("%s@%s is not pinned to a full commit SHA", ref.Action(), ref.Ref),
+				"Tags and branches can be moved to different code after review. Pin the action to a full commit SHA and keep the version in a trailing comment."))
+		}
+	}
+	return findings
+}
+
+// ownerAllowed checks a reference against the allowed owners
+func (c *Checker) ownerAllowed(ref Reference) bool {
+	if len(c.Options.AllowedOwners) == 0 {
+		return true
+	}
+	for _, allowed := range c.Options.AllowedOwners {
+		if strings.EqualFold(allowed, ref.Owner) || strings.EqualFold(allowed, ref.Action()) {
+			return true
+		}
+	}
+	return false
+}
+
+// policyFinding builds a pinning policy finding for a reference
+func policyFinding(ref Reference, prefix, severity, summary, description string) scanner.Vulnerability {
+	return scanner.Vulnerability{
+		ID:          fmt.Sprintf("%s-%s@%s", prefix, ref.Action(), ref.Ref),
+		Package:     ref.Action(),
+		Version:     ref.Ref,
+		Severity:    severity,
+		Summary:     summary,
+		Description: description,
+		References:  []string{"https://docs.github.com/actions/security-guides/security-hardening-for-github-actions#using-third-party-actions"},
+		IsDirect:    true,
+		Ecosystem:   Ecosystem,
+		Class:       scanner.ClassWorkflow,
+		File:        ref.File,
+		Line:        ref.Line,
+	}
+}
+
+// actionNames lists the distinct actions referenced
+func actionNames(refs []Reference) []string {
+	seen := make(map[string]bool)
+	var names []string
+	for _, ref := range refs {
+		name := strings.ToLower(ref.Action())
+		if !seen[name] {
+			seen[name] = true
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+// isActionFile reports whether a file may contain uses: references
+func isActionFile(rel string) bool {
+	name := filepath.Base(rel)
+	if name == "action.yml" || name == "action.yaml" {
+		return true
+	}
+	dir := filepath.ToSlash(filepath.Dir(rel))
+	ext := filepath.Ext(name)
+	return strings.HasSuffix(dir, ".github/workflows") && (ext == ".yml" || ext == ".yaml")
+}
+
+// FindReferences collects the third-party uses: references of workflow and composite action files
+func FindReferences(root string, excludePaths []string) ([]Reference, []scanner.Diagnostic) {
+	excluded := make(map[string]bool)
+	for _, path := range excludePaths {
+		excluded[strings.Trim(path, "/")] = true
+	}
+
+	var refs []Reference
+	var diagnostics []scanner.Diagnostic
+
+	filepath.WalkDir(root, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return nil
+		}
+		rel, _ := filepath.Rel(root, path)
+		if entry.IsDir() {
+			if path != root && (excluded[entry.Name()] || excluded[filepath.ToSlash(rel)]) {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !isActionFile(rel) {
+			return nil
+		}
+
+		fileRefs, err := parseFile(path)
+		if err != nil {
+			diagnostics = append(diagnostics, scanner.Diagnostic{
+				Tool:    "dep-risk",
+				Kind:    scanner.DiagnosticWarning,
+				Message: fmt.Sprintf("failed to parse %s: %v", filepath.ToSlash(rel), err),
+				Path:    filepath.ToSlash(rel),
+			})
+			return nil
+		}
+		for _, ref := range fileRefs {
+			ref.File = filepath.ToSlash(rel)
+			refs = append(refs, ref)
+		}
+		return nil
+	})
+
+	return refs, diagnostics
+}
+
+// parseFile reads the uses: references of a workflow or action file
+func parseFile(path string) ([]Reference, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return nil, err
+	}
+
+	var refs []Reference
+	var walk func(node *yaml.Node)
+	walk = func(node *yaml.Node) {
+		if node.Kind == yaml.MappingNode {
+			for i := 0; i+1 < len(node.Content); i += 2 {
+				key, value := node.Content[i], node.Content[i+1]
+				if key.Value == "uses" && value.Kind == yaml.ScalarNode {
+					if ref, ok := parseReference(value.Value); ok {
+						ref.Line = value.Line
+						if ref.IsSHAPinned() {
+							ref.Version = versionComment.FindString(value.LineComment)
+						}
+						refs = append(refs, ref)
+					}
+					continue
+				}
+				walk(value)
+			}
+			return
+		}
+		for _, child := range node.Content {
+			walk(child)
+		}
+	}
+	walk(&doc)
+
+	return refs, nil
+}
+
+// parseReference parses an owner/repo[/path]@ref reference. Local actions
+// and Docker images are not third-party repository references.
+func parseReference(uses string) (Reference, bool) {
+	uses = strings.TrimSpace(uses)
+	if strings.HasPrefix(uses, "./") || strings.HasPrefix(uses, "docker://") {
+		return Reference{}, false
+	}
+
+	at := strings.LastIndex(uses, "@")
+	if at < 0 {
+		return Reference{}, false
+	}
+	name, ref := uses[:at], uses[at+1:]
+
+	parts := strings.SplitN(name, "/", 3)
+	if len(parts) < 2 || parts[0] == "" || parts[1] == "" || ref == "" {
+		return Reference{}, false
+	}
+
+	reference := Reference{Owner: parts[0], Repo: parts[1], Ref: ref, Version: ref}
+	if len(parts) == 3 {
+		reference.Path = parts[2]
+	}
+	return reference, true
+}
@@ -0,0 +1,201 @@
+package workflow
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/dep-risk/dep-risk/internal/scanner"
+)
+
+// writeFile creates a file and its parent directories for a test fixture
+func writeFile(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatalf("Failed to create directory: %v", err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatalf("Failed to write %s: %v", path, err)
+	}
+}
+
+const ciWorkflow = `name: CI
+on: [push]
+jobs:
+  build:
+    runs-on: ubuntu-latest
+    steps:
+      - uses: actions/checkout@b4ffde65f46336ab88eb53be808477a3936bae11 # v4.1.1
+      - uses: tj-actions/changed-files@v35.7.6
+      - uses: ./local-action
+      - uses: docker://alpine:3.19
+  release:
+    uses: octo-org/workflows/.github/workflows/release.yml@main
+`
+
+const compositeAction = `name: Setup
+runs:
+  using: composite
+  steps:
+    - uses: actions/setup-go@v5
+`
+
+func TestFindReferences(t *testing.T) {
+	projectDir := t.TempDir()
+	writeFile(t, filepath.Join(projectDir, ".github/workflows/ci.yml"), ciWorkflow)
+	writeFile(t, filepath.Join(projectDir, ".github/actions/setup/action.yml"), compositeAction)
+	writeFile(t, filepath.Join(projectDir, "docs/example.yml"), "steps:\n  - uses: evil/action@v1\n")
+
+	refs, diagnostics := FindReferences(projectDir, nil)
+	if len(diagnostics) != 0 {
+		t.Errorf("Expected no diagnostics, got %+v", diagnostics)
+	}
+	if len(refs) != 4 {
+		t.Fatalf("Expected 4 references, got %+v", refs)
+	}
+
+	byAction := make(map[string]Reference)
+	for _, ref := range refs {
+		byAction[ref.Action()] = ref
+	}
+
+	checkout := byAction["actions/checkout"]
+	if !checkout.IsSHAPinned() || checkout.Version != "v4.1.1" || checkout.Line != 7 || checkout.File != ".github/workflows/ci.yml" {
+		t.Errorf("Unexpected checkout reference: %+v", checkout)
+	}
+	if release := byAction["octo-org/workflows"]; release.Path != ".github/workflows/release.yml" || release.Ref != "main" || release.Line != 12 {
+		t.Errorf("Unexpected reusable workflow reference: %+v", release)
+	}
+	if setup := byAction["actions/setup-go"]; setup.File != ".github/actions/setup/action.yml" || setup.Line != 5 {
+		t.Errorf("Unexpected composite action reference: %+v", setup)
+	}
+}
+
+func TestCheck(t *testing.T) {
+	projectDir := t.TempDir()
+	writeFile(t, filepath.Join(projectDir, ".github/workflows/ci.yml"), ciWorkflow)
+
+	advisoriesPath := filepath.Join(t.TempDir(), "advisories.json")
+	writeFile(t, advisoriesPath, `[{
+		"ghsa_id": "GHSA-mrrh-fwg8-r2c3",
+		"cve_id": "CVE-2025-30066",
+		"html_url": "https://github.com/advisories/GHSA-mrrh-fwg8-r2c3",
+		"summary": "tj-actions changed-files through 45.0.7 allows remote attackers to discover secrets",
+		"severity": "high",
+		"cvss": {"score": 8.6},
+		"vulnerabilities": [{
+			"package": {"ecosystem": "actions", "name": "tj-actions/changed-files"},
+			"vulnerable_version_range": "<= 45.0.7",
+			"first_patched_version": "46.0.1"
+		}]
+	}]`)
+
+	checker := NewChecker(projectDir, &FileSource{Path: advisoriesPath}, Options{
+		RequireSHA:    true,
+		AllowedOwners: []string{"actions", "octo-org/workflows"},
+	})
+	findings, diagnostics := checker.Check(context.Background())
+	if len(diagnostics) != 0 {
+		t.Errorf("Expected no diagnostics, got %+v", diagnostics)
+	}
+
+	byID := make(map[string]scanner.Vulnerability)
+	for _, finding := range findings {
+		byID[finding.ID] = finding
+	}
+	if len(findings) != 4 {
+		t.Errorf("Expected 4 findings, got %+v", findings)
+	}
+
+	advisory, ok := byID["GHSA-mrrh-fwg8-r2c3"]
+	if !ok || advisory.Class != scanner.ClassVulnerability || advisory.CVSS != 8.6 || advisory.Line != 8 {
+		t.Errorf("Expected advisory finding at line 8, got %+v", advisory)
+	}
+	if untrusted := byID["UNTRUSTED-ACTION-tj-actions/changed-files@v35.7.6"]; untrusted.Class != scanner.ClassWorkflow || untrusted.Severity != "HIGH" {
+		t.Errorf("Expected owner policy finding, got %+v", untrusted)
+	}
+	for _, id := range []string{"UNPINNED-ACTION-tj-actions/changed-files@v35.7.6", "UNPINNED-ACTION-octo-org/workflows@main"} {
+		if _, ok := byID[id]; !ok {
+			t.Errorf("Expected pinning finding %s", id)
+		}
+	}
+}
+
+func TestMatchAdvisories(t *testing.T) {
+	refs := []Reference{
+		{Owner: "octo", Repo: "deploy", Ref: "v2.1.0", Version: "v2.1.0", File: "ci.yml", Line: 3},
+		{Owner: "octo", Repo: "deploy", Ref: "v2", Version: "v2", File: "ci.yml", Line: 4},
+		{Owner: "octo", Repo: "lint", Ref: "v1", Version: "v1", File: "ci.yml", Line: 5},
+	}
+	var advisory Advisory
+	if err := json.Unmarshal([]byte(`{
+		"ghsa_id": "GHSA-xxxx-yyyy-zzzz",
+		"severity": "moderate",
+		"cvss": {"score": null},
+		"cvss_severities": {"cvss_v3": {"score": null}, "cvss_v4": {"score": 5.3}},
+		"vulnerabilities": [{
+			"package": {"ecosystem": "actions", "name": "octo/deploy"},
+			"vulnerable_version_range": "< 2.2.0"
+		}]
+	}`), &advisory); err != nil {
+		t.Fatalf("Failed to decode advisory: %v", err)
+	}
+
+	findings, diagnostics := matchAdvisories(refs, []Advisory{advisory})
+	if len(findings) != 1 || findings[0].Severity != "MEDIUM" || findings[0].CVSS != 5.3 || findings[0].Line != 3 {
+		t.Errorf("Expected one MEDIUM finding with CVSS 5.3, got %+v", findings)
+	}
+	// Only the floating tag of an action with advisories is reported
+	if len(diagnostics) != 1 || !strings.Contains(diagnostics[0].Message, "octo/deploy@v2 cannot be checked against GHSA-xxxx-yyyy-zzzz") {
+		t.Errorf("Expected a diagnostic for octo/deploy@v2, got %+v", diagnostics)
+	}
+}
+
+func TestAPISourcePaginates(t *testing.T) {
+	var server *httptest.Server
+	server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("after") == "" {
+			w.Header().Set("Link", fmt.Sprintf(`<%s/advisories?after=cursor1&ecosystem=actions>; rel="next"`, server.URL))
+			fmt.Fprint(w, `[{"ghsa_id": "GHSA-0001"}]`)
+			return
+		}
+		fmt.Fprint(w, `[{"ghsa_id": "GHSA-0002"}]`)
+	}))
+	defer server.Close()
+
+	advisories, err := NewAPISource(server.URL, "").Advisories(context.Background(), []string{"octo/deploy"})
+	if err != nil {
+		t.Fatalf("Advisories failed: %v", err)
+	}
+	if len(advisories) != 2 || advisories[1].GHSAID != "GHSA-0002" {
+		t.Errorf("Expected both pages, got %+v", advisories)
+	}
+}
+
+func TestInRange(t *testing.T) {
+	tests := []struct {
+		version string
+		rng     string
+		want    bool
+	}{
+		{"v1.2.0", "< 1.2.3", true},
+		{"v1.2.3", "< 1.2.3", false},
+		{"v1.5.0", ">= 1.0.0, < 2.0.0", true},
+		{"v2.0.0", ">= 1.0.0, < 2.0.0", false},
+		{"v45.0.7", "<= 45.0.7", true},
+		{"v1.0.0", "= 1.0.0", true},
+		{"v1.0.0", "", false},
+	}
+
+	for _, tt := range tests {
+		if got := inRange(tt.version, tt.rng); got != tt.want {
+			t.Errorf("inRange(%s, %q) = %v, want %v", tt.version, tt.rng, got, tt.want)
+		}
+	}
+}